@@ -1,7 +1,12 @@
 // Package bitlinks contains the methods to interact with the Bitlinks in Bitly
 package bitlinks
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/retgits/bitly"
+	"github.com/retgits/bitly/client/metrics"
+)
 
 // Bitlink has information about the Bitlink
 type Bitlink struct {
@@ -13,34 +18,67 @@ type Bitlink struct {
 	LongURL   string     `json:"long_url"`
 }
 
+// BulkResult contains the outcome of a bulk operation for a single Bitlink
+type BulkResult struct {
+	// The Bitlink the operation was performed on
+	Bitlink string
+	// The details of the Bitlink after the operation, which are empty for deleted Bitlinks
+	Details BitlinkDetails
+	// The error that occurred for this Bitlink, if any
+	Err error
+}
+
+// BulkShortenOptions configures a bulk shorten run
+type BulkShortenOptions struct {
+	// The number of requests that are sent to Bitly concurrently. Defaults to DefaultBulkWorkers.
+	Workers int
+	// The checkpoint that stores the shortened links, so a run that crashed can be resumed without
+	// shortening the same links again. Optional.
+	Checkpoint Checkpoint
+}
+
+// ShortenResult contains the outcome of shortening a single link in a bulk shorten run
+type ShortenResult struct {
+	// The position of the request in the input
+	Index int
+	// The request that was shortened
+	Request ShortenRequest
+	// The Bitlink that was created
+	Details BitlinkDetails
+	// Whether the Bitlink was taken from the checkpoint, instead of being created in this run
+	FromCheckpoint bool
+	// The error that occurred for this request, if any
+	Err error
+}
+
 // BitlinkDetails has more in-depth information about the Bitlink
 type BitlinkDetails struct {
-	References     References `json:"references"`
-	Archived       bool       `json:"archived"`
-	Tags           []string   `json:"tags"`
-	CreatedAt      string     `json:"created_at"`
-	Title          string     `json:"title"`
-	Deeplinks      []Deeplink `json:"deeplinks"`
-	CreatedBy      string     `json:"created_by"`
-	LongURL        string     `json:"long_url"`
-	ClientID       string     `json:"client_id"`
-	CustomBitlinks []string   `json:"custom_bitlinks"`
-	Link           string     `json:"link"`
-	ID             string     `json:"id"`
+	References     References  `json:"references"`
+	Archived       bool        `json:"archived"`
+	Tags           []string    `json:"tags"`
+	CreatedAt      *bitly.Time `json:"created_at,omitempty"`
+	Title          string      `json:"title"`
+	Deeplinks      []Deeplink  `json:"deeplinks"`
+	CreatedBy      string      `json:"created_by"`
+	LongURL        string      `json:"long_url"`
+	ClientID       string      `json:"client_id"`
+	CustomBitlinks []string    `json:"custom_bitlinks"`
+	Link           string      `json:"link"`
+	ID             string      `json:"id"`
 }
 
 // Deeplink details
 type Deeplink struct {
-	Bitlink     string `json:"bitlink,omitempty"`
-	InstallURL  string `json:"install_url"`
-	Created     string `json:"created,omitempty"`
-	AppURIPath  string `json:"app_uri_path"`
-	Modified    string `json:"modified,omitempty"`
-	InstallType string `json:"install_type"`
-	AppGUID     string `json:"app_guid,omitempty"`
-	GUID        string `json:"guid,omitempty"`
-	OS          string `json:"os,omitempty"`
-	AppID       string `json:"app_id,omitempty"`
+	Bitlink     string      `json:"bitlink,omitempty"`
+	InstallURL  string      `json:"install_url"`
+	Created     *bitly.Time `json:"created,omitempty"`
+	AppURIPath  string      `json:"app_uri_path"`
+	Modified    *bitly.Time `json:"modified,omitempty"`
+	InstallType string      `json:"install_type"`
+	AppGUID     string      `json:"app_guid,omitempty"`
+	GUID        string      `json:"guid,omitempty"`
+	OS          string      `json:"os,omitempty"`
+	AppID       string      `json:"app_id,omitempty"`
 }
 
 // Link contains the single ID of a Bitlink
@@ -50,16 +88,16 @@ type Link struct {
 
 // LinkClick contains the number of clicks per date
 type LinkClick struct {
-	Date   string `json:"date"`
-	Clicks int64  `json:"clicks"`
+	Date   bitly.Time `json:"date"`
+	Clicks int64      `json:"clicks"`
 }
 
 // LinkInfo contains information on the Bitlink
 type LinkInfo struct {
-	LongURL   string `json:"long_url"`
-	CreatedAt string `json:"created_at"`
-	Link      string `json:"link"`
-	ID        string `json:"id"`
+	LongURL   string     `json:"long_url"`
+	CreatedAt bitly.Time `json:"created_at"`
+	Link      string     `json:"link"`
+	ID        string     `json:"id"`
 }
 
 // Metric contains information on the chosen metric
@@ -73,7 +111,7 @@ type Metrics struct {
 	Units             int64               `json:"units,omitempty"`
 	Unit              string              `json:"unit,omitempty"`
 	TotalClicks       int64               `json:"total_clicks,omitempty"`
-	UnitReference     string              `json:"unit_reference,omitempty"`
+	UnitReference     bitly.Time          `json:"unit_reference"`
 	LinkClicks        []LinkClick         `json:"link_clicks,omitempty"`
 	Facet             string              `json:"facet,omitempty"`
 	Metrics           []Metric            `json:"metrics,omitempty"`
@@ -81,16 +119,7 @@ type Metrics struct {
 }
 
 // MetricsRequest is used to generate the metrics request to Bitly
-type MetricsRequest struct {
-	// A unit of time
-	Unit string
-	// An integer representing the time units to query data for. pass -1 to return all units of time.
-	Units int
-	// An ISO-8601 timestamp, indicating the most recent time for which to pull metrics. Will default to current time.
-	UnitReference string
-	// The quantity of items to be be returned
-	Size int
-}
+type MetricsRequest = metrics.Request
 
 // References contains properties generated by Bitly
 type References struct {
@@ -104,12 +133,120 @@ type Referrer struct {
 	Key   string `json:"key"`
 }
 
+// Patch contains the fields to update on a Bitlink. Only the fields that are explicitly set are sent to
+// Bitly, so all other fields are left untouched.
+//
+//	patch := bitlinks.NewPatch().Title("Launch").AddTags("campaign", "2019")
+type Patch struct {
+	longURL    *string
+	title      *string
+	archived   *bool
+	tags       *[]string
+	addTags    []string
+	removeTags []string
+	deeplinks  *[]Deeplink
+}
+
+// NewPatch creates a new Patch that can be chained with builder methods to set the fields to update.
+func NewPatch() *Patch {
+	return &Patch{}
+}
+
+// LongURL sets the long url the Bitlink redirects to returning a Patch pointer for chaining. Changing the long
+// url is only allowed on paid accounts.
+func (p *Patch) LongURL(longURL string) *Patch {
+	p.longURL = &longURL
+	return p
+}
+
+// Title sets the title of the Bitlink returning a Patch pointer for chaining.
+func (p *Patch) Title(title string) *Patch {
+	p.title = &title
+	return p
+}
+
+// Archived sets the archived state of the Bitlink returning a Patch pointer for chaining.
+func (p *Patch) Archived(archived bool) *Patch {
+	p.archived = &archived
+	return p
+}
+
+// Tags replaces all tags of the Bitlink returning a Patch pointer for chaining. Calling Tags without
+// arguments removes all tags.
+func (p *Patch) Tags(tags ...string) *Patch {
+	t := append([]string{}, tags...)
+	p.tags = &t
+	return p
+}
+
+// AddTags adds tags to the existing tags of the Bitlink returning a Patch pointer for chaining.
+func (p *Patch) AddTags(tags ...string) *Patch {
+	p.addTags = append(p.addTags, tags...)
+	return p
+}
+
+// RemoveTags removes tags from the existing tags of the Bitlink returning a Patch pointer for chaining.
+func (p *Patch) RemoveTags(tags ...string) *Patch {
+	p.removeTags = append(p.removeTags, tags...)
+	return p
+}
+
+// Deeplinks replaces all deeplinks of the Bitlink returning a Patch pointer for chaining.
+func (p *Patch) Deeplinks(deeplinks ...Deeplink) *Patch {
+	d := append([]Deeplink{}, deeplinks...)
+	p.deeplinks = &d
+	return p
+}
+
+// needsCurrentTags returns true if the existing tags of the Bitlink are needed to compute the new tags.
+func (p *Patch) needsCurrentTags() bool {
+	return p.tags == nil && (len(p.addTags) > 0 || len(p.removeTags) > 0)
+}
+
+// mergeTags applies the tags that are added and removed to the tags.
+func (p *Patch) mergeTags(current []string) []string {
+	if p.tags != nil {
+		current = *p.tags
+	}
+
+	removed := make(map[string]bool, len(p.removeTags))
+	for _, t := range p.removeTags {
+		removed[t] = true
+	}
+
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, t := range append(append([]string{}, current...), p.addTags...) {
+		if removed[t] || seen[t] {
+			continue
+		}
+		seen[t] = true
+		tags = append(tags, t)
+	}
+
+	return tags
+}
+
+// patchRequest is the payload sent to Bitly for a Patch
+type patchRequest struct {
+	LongURL   *string     `json:"long_url,omitempty"`
+	Title     *string     `json:"title,omitempty"`
+	Archived  *bool       `json:"archived,omitempty"`
+	Tags      *[]string   `json:"tags,omitempty"`
+	Deeplinks *[]Deeplink `json:"deeplinks,omitempty"`
+}
+
 // ReferrersByDomain contains information about the domain of the referrer of a Bitlink click
 type ReferrersByDomain struct {
 	Referrers []Referrer `json:"Referrers"`
 	Network   string     `json:"network"`
 }
 
+// archiveRequest contains the archived state to set on a Bitlink
+type archiveRequest struct {
+	Archived bool `json:"archived"`
+}
+
 // ShortenRequest contains the request details to shorten a link using Bitly
 type ShortenRequest struct {
 	GroupGUID string `json:"group_guid"`
@@ -125,6 +262,14 @@ func (r *BitlinkDetails) marshal() ([]byte, error) {
 	return json.Marshal(r)
 }
 
+func (r *patchRequest) marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *archiveRequest) marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
 func (r *Link) marshal() ([]byte, error) {
 	return json.Marshal(r)
 }
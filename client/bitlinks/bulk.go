@@ -0,0 +1,314 @@
+// Package bitlinks contains the methods to interact with the Bitlinks in Bitly
+package bitlinks
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+)
+
+const (
+	// DefaultBulkWorkers is the number of concurrent requests of a bulk shorten run
+	DefaultBulkWorkers = 4
+)
+
+// Checkpoint stores the progress of a bulk shorten run.
+type Checkpoint interface {
+	// Lookup returns the Bitlink that was created for the request in an earlier run
+	Lookup(req ShortenRequest) (BitlinkDetails, bool)
+	// Save stores the Bitlink that was created for the request
+	Save(req ShortenRequest, details BitlinkDetails) error
+}
+
+// pendingShorten is a unique request in a bulk shorten run. Identical requests share a pendingShorten,
+// so the long URL is only shortened once.
+type pendingShorten struct {
+	req            ShortenRequest
+	done           chan struct{}
+	details        BitlinkDetails
+	fromCheckpoint bool
+	err            error
+}
+
+// orderedShorten links a position in the input to the unique request.
+type orderedShorten struct {
+	index   int
+	req     ShortenRequest
+	pending *pendingShorten
+}
+
+// BulkShorten will convert all long urls to Bitlinks using a pool of workers. The results are returned in
+// the same order as the requests. Identical requests are only sent to Bitly once.
+func (b *Bitlinks) BulkShorten(requests []ShortenRequest, options *BulkShortenOptions) []ShortenResult {
+	return b.BulkShortenContext(context.Background(), requests, options)
+}
+
+// BulkShortenContext is the same as BulkShorten, but uses the context to control the lifetime of the requests.
+func (b *Bitlinks) BulkShortenContext(ctx context.Context, requests []ShortenRequest, options *BulkShortenOptions) []ShortenResult {
+	in := make(chan ShortenRequest)
+	go func() {
+		defer close(in)
+		for _, req := range requests {
+			select {
+			case in <- req:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	results := make([]ShortenResult, len(requests))
+	received := make([]bool, len(requests))
+	for res := range b.BulkShortenStreamContext(ctx, in, options) {
+		results[res.Index] = res
+		received[res.Index] = true
+	}
+
+	// Requests that weren't processed because the context was done
+	for idx := range results {
+		if !received[idx] {
+			results[idx] = ShortenResult{Index: idx, Request: requests[idx], Err: ctx.Err()}
+		}
+	}
+
+	return results
+}
+
+// BulkShortenStream will convert all long urls received on the channel to Bitlinks using a pool of workers.
+// The results are sent in the same order as the requests, and the returned channel is closed after the
+// last result. Identical requests are only sent to Bitly once.
+func (b *Bitlinks) BulkShortenStream(requests <-chan ShortenRequest, options *BulkShortenOptions) <-chan ShortenResult {
+	return b.BulkShortenStreamContext(context.Background(), requests, options)
+}
+
+// BulkShortenStreamContext is the same as BulkShortenStream, but uses the context to control the lifetime of the requests.
+// When the context is done, no new requests are read from the channel.
+func (b *Bitlinks) BulkShortenStreamContext(ctx context.Context, requests <-chan ShortenRequest, options *BulkShortenOptions) <-chan ShortenResult {
+	workers := DefaultBulkWorkers
+	var checkpoint Checkpoint
+	if options != nil {
+		if options.Workers > 0 {
+			workers = options.Workers
+		}
+		checkpoint = options.Checkpoint
+	}
+
+	work := make(chan *pendingShorten)
+	order := make(chan orderedShorten, workers)
+	out := make(chan ShortenResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range work {
+				b.shortenPending(ctx, p, checkpoint)
+			}
+		}()
+	}
+
+	// Dispatch unique requests to the workers, and all requests to the emitter
+	go func() {
+		defer close(order)
+		defer close(work)
+
+		seen := make(map[string]*pendingShorten)
+		for idx := 0; ; idx++ {
+			var req ShortenRequest
+			var ok bool
+			select {
+			case req, ok = <-requests:
+			case <-ctx.Done():
+				return
+			}
+			if !ok {
+				return
+			}
+
+			key := shortenKey(req)
+			p, dup := seen[key]
+			if !dup {
+				p = &pendingShorten{req: req, done: make(chan struct{})}
+				seen[key] = p
+				select {
+				case work <- p:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			order <- orderedShorten{index: idx, req: req, pending: p}
+		}
+	}()
+
+	// Emit the results in the order of the requests
+	go func() {
+		defer close(out)
+		defer wg.Wait()
+
+		for o := range order {
+			<-o.pending.done
+			res := ShortenResult{
+				Index:          o.index,
+				Request:        o.req,
+				Details:        o.pending.details,
+				FromCheckpoint: o.pending.fromCheckpoint,
+				Err:            o.pending.err,
+			}
+			select {
+			case out <- res:
+			case <-ctx.Done():
+				// Keep draining, so the dispatcher isn't blocked, but stop sending results nobody reads
+				for o := range order {
+					<-o.pending.done
+				}
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// shortenPending shortens a unique request, unless it can be found in the checkpoint.
+func (b *Bitlinks) shortenPending(ctx context.Context, p *pendingShorten, checkpoint Checkpoint) {
+	defer close(p.done)
+
+	if checkpoint != nil {
+		if details, ok := checkpoint.Lookup(p.req); ok {
+			p.details = details
+			p.fromCheckpoint = true
+			return
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return
+	}
+
+	req := p.req
+	p.details, p.err = b.ShortenLinkContext(ctx, &req)
+	if p.err != nil || checkpoint == nil {
+		return
+	}
+
+	if err := checkpoint.Save(p.req, p.details); err != nil {
+		p.err = fmt.Errorf("bitlink %s was created, but saving the checkpoint failed: %s", p.details.ID, err.Error())
+	}
+}
+
+// shortenKey identifies identical requests.
+func shortenKey(req ShortenRequest) string {
+	return fmt.Sprintf("%s\x00%s\x00%s", req.GroupGUID, req.Domain, req.LongURL)
+}
+
+// FileCheckpoint is a Checkpoint which appends every shortened link to a file, as a JSON object per line.
+// It is safe for concurrent use.
+type FileCheckpoint struct {
+	mu      sync.Mutex
+	file    *os.File
+	entries map[string]BitlinkDetails
+}
+
+// checkpointEntry is a single line in the file of a FileCheckpoint
+type checkpointEntry struct {
+	Request ShortenRequest `json:"request"`
+	Details BitlinkDetails `json:"details"`
+}
+
+// NewFileCheckpoint opens, or creates, the checkpoint file and loads the links that were shortened in
+// earlier runs. The caller should call Close when the run is finished.
+func NewFileCheckpoint(path string) (*FileCheckpoint, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &FileCheckpoint{
+		file:    file,
+		entries: make(map[string]BitlinkDetails),
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e checkpointEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// A crash can leave a partially written last line behind, which is skipped
+			continue
+		}
+		c.entries[shortenKey(e.Request)] = e.Details
+	}
+
+	if err := scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if err := terminateLastLine(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// terminateLastLine ends a partially written last line, so the next Save starts on a line of its own
+// instead of continuing the partial one.
+func terminateLastLine(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil || info.Size() == 0 {
+		return err
+	}
+
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return err
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+
+	_, err = file.Write([]byte{'\n'})
+	return err
+}
+
+// Lookup returns the Bitlink that was created for the request in an earlier run.
+func (c *FileCheckpoint) Lookup(req ShortenRequest) (BitlinkDetails, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	details, ok := c.entries[shortenKey(req)]
+	return details, ok
+}
+
+// Save appends the Bitlink that was created for the request to the file.
+func (c *FileCheckpoint) Save(req ShortenRequest, details BitlinkDetails) error {
+	line, err := json.Marshal(checkpointEntry{Request: req, Details: details})
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+
+	c.entries[shortenKey(req)] = details
+	return nil
+}
+
+// Close closes the checkpoint file.
+func (c *FileCheckpoint) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.file.Close()
+}
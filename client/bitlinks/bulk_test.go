@@ -0,0 +1,269 @@
+package bitlinks_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bitlytest"
+)
+
+// countRequests returns the number of requests the server received for the path.
+func countRequests(srv *bitlytest.Server, method string, path string) int {
+	count := 0
+	for _, r := range srv.Requests() {
+		if r.Method == method && r.Path == path {
+			count++
+		}
+	}
+	return count
+}
+
+func TestBulkShorten(t *testing.T) {
+	url := func(n int) bitlinks.ShortenRequest {
+		return bitlinks.ShortenRequest{LongURL: fmt.Sprintf("https://example.org/%d", n)}
+	}
+
+	tests := []struct {
+		name     string
+		requests []bitlinks.ShortenRequest
+		workers  int
+		shortens int
+	}{
+		{name: "no requests", requests: nil, shortens: 0},
+		{name: "single worker", requests: []bitlinks.ShortenRequest{url(1), url(2), url(3)}, workers: 1, shortens: 3},
+		{name: "more workers than requests", requests: []bitlinks.ShortenRequest{url(1), url(2)}, workers: 8, shortens: 2},
+		{name: "duplicates", requests: []bitlinks.ShortenRequest{url(1), url(2), url(1), url(3), url(2), url(1)}, workers: 3, shortens: 3},
+		{
+			name: "same url in another domain isn't a duplicate",
+			requests: []bitlinks.ShortenRequest{
+				url(1),
+				{LongURL: "https://example.org/1", Domain: "j.mp"},
+			},
+			shortens: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			srv.AddBSD("j.mp")
+
+			results := bitlinks.New(srv.Client()).BulkShorten(tt.requests, &bitlinks.BulkShortenOptions{Workers: tt.workers})
+
+			if len(results) != len(tt.requests) {
+				t.Fatalf("got %d results, want %d", len(results), len(tt.requests))
+			}
+
+			ids := make(map[string]string)
+			for idx, res := range results {
+				if res.Err != nil {
+					t.Fatalf("result %d failed: %s", idx, res.Err.Error())
+				}
+				if res.Index != idx {
+					t.Errorf("result %d has index %d", idx, res.Index)
+				}
+				if res.Request != tt.requests[idx] {
+					t.Errorf("result %d is for %v, want %v", idx, res.Request, tt.requests[idx])
+				}
+				if res.Details.LongURL != tt.requests[idx].LongURL {
+					t.Errorf("result %d has long url %s, want %s", idx, res.Details.LongURL, tt.requests[idx].LongURL)
+				}
+
+				key := tt.requests[idx].Domain + " " + tt.requests[idx].LongURL
+				if id, ok := ids[key]; ok && id != res.Details.ID {
+					t.Errorf("duplicate request %d got Bitlink %s, want %s", idx, res.Details.ID, id)
+				}
+				ids[key] = res.Details.ID
+			}
+
+			if got := countRequests(srv, http.MethodPost, "shorten"); got != tt.shortens {
+				t.Errorf("sent %d shorten requests, want %d", got, tt.shortens)
+			}
+		})
+	}
+}
+
+func TestBulkShortenErrors(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	srv.InjectFault(bitlytest.Fault{Method: http.MethodPost, PathPrefix: "shorten", StatusCode: http.StatusBadRequest, Times: 1})
+
+	requests := []bitlinks.ShortenRequest{{LongURL: "https://example.org/a"}}
+	for i := 0; i < 5; i++ {
+		requests = append(requests, bitlinks.ShortenRequest{LongURL: "https://example.org/a"})
+	}
+
+	// With a single worker the first, and only, request fails, and all duplicates share its error
+	results := bitlinks.New(srv.Client()).BulkShorten(requests, &bitlinks.BulkShortenOptions{Workers: 1})
+	for idx, res := range results {
+		if res.Err == nil {
+			t.Errorf("result %d has no error", idx)
+		}
+	}
+}
+
+func TestBulkShortenStreamOrder(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+
+	// The first request is slow, so the later ones finish first but must still be sent after it
+	srv.InjectFault(bitlytest.Fault{Method: http.MethodPost, PathPrefix: "shorten", Delay: 50 * time.Millisecond, Times: 1})
+
+	in := make(chan bitlinks.ShortenRequest)
+	go func() {
+		defer close(in)
+		for i := 0; i < 20; i++ {
+			in <- bitlinks.ShortenRequest{LongURL: fmt.Sprintf("https://example.org/%d", i%7)}
+		}
+	}()
+
+	idx := 0
+	for res := range bitlinks.New(srv.Client()).BulkShortenStream(in, &bitlinks.BulkShortenOptions{Workers: 4}) {
+		if res.Index != idx {
+			t.Fatalf("got result %d, want %d", res.Index, idx)
+		}
+		if res.Err != nil {
+			t.Fatalf("result %d failed: %s", idx, res.Err.Error())
+		}
+		idx++
+	}
+
+	if idx != 20 {
+		t.Errorf("got %d results, want 20", idx)
+	}
+	if got := countRequests(srv, http.MethodPost, "shorten"); got != 7 {
+		t.Errorf("sent %d shorten requests, want 7", got)
+	}
+}
+
+func TestBulkShortenCancelled(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	srv.InjectFault(bitlytest.Fault{Method: http.MethodPost, PathPrefix: "shorten", Delay: 20 * time.Millisecond})
+
+	var requests []bitlinks.ShortenRequest
+	for i := 0; i < 50; i++ {
+		requests = append(requests, bitlinks.ShortenRequest{LongURL: fmt.Sprintf("https://example.org/%d", i)})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	results := bitlinks.New(srv.Client()).BulkShortenContext(ctx, requests, &bitlinks.BulkShortenOptions{Workers: 2})
+	if len(results) != len(requests) {
+		t.Fatalf("got %d results, want %d", len(results), len(requests))
+	}
+
+	failed := 0
+	for idx, res := range results {
+		if res.Index != idx || res.Request != requests[idx] {
+			t.Errorf("result %d is for request %d", idx, res.Index)
+		}
+		if res.Err != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		t.Errorf("no request failed after the context was done")
+	}
+}
+
+func TestBulkShortenCheckpoint(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "bitlinks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint.jsonl")
+
+	requests := []bitlinks.ShortenRequest{
+		{LongURL: "https://example.org/1"},
+		{LongURL: "https://example.org/2"},
+	}
+
+	run := func(requests []bitlinks.ShortenRequest) []bitlinks.ShortenResult {
+		checkpoint, err := bitlinks.NewFileCheckpoint(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer checkpoint.Close()
+		return bitlinks.New(srv.Client()).BulkShorten(requests, &bitlinks.BulkShortenOptions{Checkpoint: checkpoint})
+	}
+
+	first := run(requests[:1])
+	if first[0].Err != nil || first[0].FromCheckpoint {
+		t.Fatalf("first run = %+v", first[0])
+	}
+
+	srv.ResetRequests()
+	second := run(requests)
+
+	if !second[0].FromCheckpoint || second[0].Details.ID != first[0].Details.ID {
+		t.Errorf("the first link wasn't taken from the checkpoint: %+v", second[0])
+	}
+	if second[1].FromCheckpoint || second[1].Err != nil {
+		t.Errorf("the second link wasn't shortened: %+v", second[1])
+	}
+	if got := countRequests(srv, http.MethodPost, "shorten"); got != 1 {
+		t.Errorf("sent %d shorten requests, want 1", got)
+	}
+}
+
+func TestFileCheckpointAfterTornWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bitlinks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "checkpoint.jsonl")
+
+	a := bitlinks.ShortenRequest{LongURL: "https://example.org/a"}
+	b := bitlinks.ShortenRequest{LongURL: "https://example.org/b"}
+
+	checkpoint, err := bitlinks.NewFileCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkpoint.Save(a, bitlinks.BitlinkDetails{ID: "bit.ly/a"})
+	checkpoint.Close()
+
+	// A crash in the middle of a Save leaves a partial line without a newline behind
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"request":{"long_url":"https://exa`)
+	f.Close()
+
+	checkpoint, err = bitlinks.NewFileCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := checkpoint.Save(b, bitlinks.BitlinkDetails{ID: "bit.ly/b"}); err != nil {
+		t.Fatal(err)
+	}
+	checkpoint.Close()
+
+	checkpoint, err = bitlinks.NewFileCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checkpoint.Close()
+
+	for _, req := range []bitlinks.ShortenRequest{a, b} {
+		if _, ok := checkpoint.Lookup(req); !ok {
+			t.Errorf("the link of %s was lost after reopening", req.LongURL)
+		}
+	}
+}
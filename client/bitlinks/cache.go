@@ -0,0 +1,86 @@
+// Package bitlinks contains the methods to interact with the Bitlinks in Bitly
+package bitlinks
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/retgits/bitly/client/cache"
+)
+
+// WithShortenCache sets the cache of ShortenLink and CreateBitlink returning a Bitlinks pointer for
+// chaining. Links that were shortened before, with the same domain and group, are returned from the cache
+// for the ttl instead of being sent to Bitly again. A ttl of zero or less keeps them forever.
+//
+//	svc := bitlinks.New(c).WithShortenCache(cache.NewLRU(10000), 24*time.Hour)
+func (b *Bitlinks) WithShortenCache(c cache.Cache, ttl time.Duration) *Bitlinks {
+	b.shortenCache = c
+	b.shortenTTL = ttl
+	return b
+}
+
+// cachedShorten returns the Bitlink of the long url from the shorten cache.
+func (b *Bitlinks) cachedShorten(longURL string, domain string, groupGUID string) (BitlinkDetails, bool) {
+	if b.shortenCache == nil {
+		return BitlinkDetails{}, false
+	}
+
+	data, ok := b.shortenCache.Get(shortenCacheKey(longURL, domain, groupGUID))
+	if !ok {
+		return BitlinkDetails{}, false
+	}
+
+	var details BitlinkDetails
+	if err := json.Unmarshal(data, &details); err != nil {
+		return BitlinkDetails{}, false
+	}
+	return details, true
+}
+
+// storeShorten stores the Bitlink of the long url in the shorten cache. A cache that fails to store the
+// Bitlink only costs a request next time, so the error is ignored.
+func (b *Bitlinks) storeShorten(longURL string, domain string, groupGUID string, details BitlinkDetails) {
+	if b.shortenCache == nil {
+		return
+	}
+
+	data, err := json.Marshal(details)
+	if err != nil {
+		return
+	}
+	_ = b.shortenCache.Set(shortenCacheKey(longURL, domain, groupGUID), data, b.shortenTTL)
+}
+
+// shortenCacheKey identifies the Bitlink of a long url in the shorten cache.
+func shortenCacheKey(longURL string, domain string, groupGUID string) string {
+	return fmt.Sprintf("shorten\x00%s\x00%s\x00%s", groupGUID, strings.ToLower(domain), normalizeURL(longURL))
+}
+
+// normalizeURL rewrites a url to a canonical form, so urls that only differ in the case of the scheme and
+// host, a default port, an empty path or the order of the query parameters share a Bitlink in the cache.
+func normalizeURL(longURL string) string {
+	u, err := url.Parse(strings.TrimSpace(longURL))
+	if err != nil || len(u.Host) == 0 {
+		return longURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+
+	if (u.Scheme == "http" && strings.HasSuffix(u.Host, ":80")) || (u.Scheme == "https" && strings.HasSuffix(u.Host, ":443")) {
+		u.Host = u.Host[:strings.LastIndex(u.Host, ":")]
+	}
+
+	if len(u.Path) == 0 {
+		u.Path = "/"
+	}
+
+	if len(u.RawQuery) > 0 {
+		u.RawQuery = u.Query().Encode()
+	}
+
+	return u.String()
+}
@@ -0,0 +1,28 @@
+package bitlinks
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		same bool
+	}{
+		{a: "https://example.org", b: "https://example.org/", same: true},
+		{a: "HTTPS://Example.ORG/path", b: "https://example.org/path", same: true},
+		{a: "https://example.org:443/path", b: "https://example.org/path", same: true},
+		{a: "http://example.org:80/path", b: "http://example.org/path", same: true},
+		{a: "https://example.org/?b=2&a=1", b: "https://example.org/?a=1&b=2", same: true},
+		{a: "https://example.org/Path", b: "https://example.org/path", same: false},
+		{a: "https://example.org:8443/", b: "https://example.org/", same: false},
+		{a: "http://example.org/", b: "https://example.org/", same: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" "+tt.b, func(t *testing.T) {
+			if got := normalizeURL(tt.a) == normalizeURL(tt.b); got != tt.same {
+				t.Errorf("normalizeURL(%s) = %s, normalizeURL(%s) = %s", tt.a, normalizeURL(tt.a), tt.b, normalizeURL(tt.b))
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package bitlinks_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/cache"
+	"github.com/retgits/bitly/client/groups"
+)
+
+func TestShortenCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests []bitlinks.ShortenRequest
+		shortens int
+	}{
+		{
+			name: "same url",
+			requests: []bitlinks.ShortenRequest{
+				{LongURL: "https://example.org/a"},
+				{LongURL: "https://example.org/a"},
+			},
+			shortens: 1,
+		},
+		{
+			name: "normalized url",
+			requests: []bitlinks.ShortenRequest{
+				{LongURL: "https://example.org/a?x=1&y=2"},
+				{LongURL: "HTTPS://EXAMPLE.org:443/a?y=2&x=1"},
+			},
+			shortens: 1,
+		},
+		{
+			name: "other group",
+			requests: []bitlinks.ShortenRequest{
+				{LongURL: "https://example.org/a"},
+				{LongURL: "https://example.org/a", GroupGUID: "Bg00000001"},
+			},
+			shortens: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			srv.AddGroup(groups.Group{GUID: "Bg00000001", OrganizationGUID: bitlytest.DefaultOrganizationGUID, Name: "other"})
+
+			svc := bitlinks.New(srv.Client()).WithShortenCache(cache.NewLRU(100), 0)
+			for idx := range tt.requests {
+				if _, err := svc.ShortenLink(&tt.requests[idx]); err != nil {
+					t.Fatalf("ShortenLink() returned %s", err.Error())
+				}
+			}
+
+			if got := countRequests(srv, http.MethodPost, "shorten"); got != tt.shortens {
+				t.Errorf("sent %d shorten requests, want %d", got, tt.shortens)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package bitlinks contains the methods to interact with the Bitlinks in Bitly
+package bitlinks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// InstallTypeNoInstall sends users that don't have the app to the long url of the Bitlink
+	InstallTypeNoInstall = "no_install"
+	// InstallTypeAutoInstall sends users that don't have the app to the app store
+	InstallTypeAutoInstall = "auto_install"
+	// InstallTypePromoteInstall shows users that don't have the app a page that promotes it
+	InstallTypePromoteInstall = "promote_install"
+
+	// OSIOS is the operating system of deeplinks to iOS apps
+	OSIOS = "ios"
+	// OSAndroid is the operating system of deeplinks to Android apps
+	OSAndroid = "android"
+)
+
+// DeeplinkNotFoundError is returned when a Bitlink has no deeplink with the GUID.
+type DeeplinkNotFoundError struct {
+	Bitlink string
+	GUID    string
+}
+
+// Error implements the error interface.
+func (e *DeeplinkNotFoundError) Error() string {
+	return fmt.Sprintf("bitlinks: %s has no deeplink %s", e.Bitlink, e.GUID)
+}
+
+// Validate checks the install type, operating system and app URI path of the deeplink, so mistakes are
+// reported before the request is sent to Bitly. The operating system may be empty.
+func (d *Deeplink) Validate() error {
+	switch d.InstallType {
+	case InstallTypeNoInstall, InstallTypeAutoInstall, InstallTypePromoteInstall:
+	default:
+		return fmt.Errorf("bitlinks: invalid install type %q, must be one of %s, %s or %s", d.InstallType, InstallTypeNoInstall, InstallTypeAutoInstall, InstallTypePromoteInstall)
+	}
+
+	switch d.OS {
+	case "", OSIOS, OSAndroid:
+	default:
+		return fmt.Errorf("bitlinks: invalid operating system %q, must be %s or %s", d.OS, OSIOS, OSAndroid)
+	}
+
+	if len(d.AppURIPath) == 0 {
+		return fmt.Errorf("bitlinks: the app URI path is empty")
+	}
+	if strings.ContainsAny(d.AppURIPath, " \t\r\n") {
+		return fmt.Errorf("bitlinks: the app URI path %q contains whitespace", d.AppURIPath)
+	}
+	if _, err := url.Parse(d.AppURIPath); err != nil {
+		return fmt.Errorf("bitlinks: invalid app URI path %q: %s", d.AppURIPath, err.Error())
+	}
+
+	return nil
+}
+
+// validateDeeplinks validates all deeplinks.
+func validateDeeplinks(deeplinks []Deeplink) error {
+	for idx := range deeplinks {
+		if err := deeplinks[idx].Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListDeeplinks returns the deeplinks of a Bitlink.
+func (b *Bitlinks) ListDeeplinks(bitlink string) ([]Deeplink, error) {
+	return b.ListDeeplinksContext(context.Background(), bitlink)
+}
+
+// ListDeeplinksContext is the same as ListDeeplinks, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) ListDeeplinksContext(ctx context.Context, bitlink string) ([]Deeplink, error) {
+	details, err := b.RetrieveBitlinkContext(ctx, bitlink)
+	if err != nil {
+		return nil, err
+	}
+
+	if details.Deeplinks == nil {
+		return []Deeplink{}, nil
+	}
+	return details.Deeplinks, nil
+}
+
+// AddDeeplink adds a deeplink to the deeplinks of a Bitlink. Bitly assigns the GUID of the new deeplink.
+func (b *Bitlinks) AddDeeplink(bitlink string, deeplink Deeplink) (BitlinkDetails, error) {
+	return b.AddDeeplinkContext(context.Background(), bitlink, deeplink)
+}
+
+// AddDeeplinkContext is the same as AddDeeplink, but uses the context to control the lifetime of the requests.
+func (b *Bitlinks) AddDeeplinkContext(ctx context.Context, bitlink string, deeplink Deeplink) (BitlinkDetails, error) {
+	if err := deeplink.Validate(); err != nil {
+		return BitlinkDetails{}, err
+	}
+
+	return b.changeDeeplinks(ctx, bitlink, func(current []Deeplink) ([]Deeplink, error) {
+		return append(current, deeplink), nil
+	})
+}
+
+// UpdateDeeplink replaces the deeplink with the GUID on a Bitlink.
+func (b *Bitlinks) UpdateDeeplink(bitlink string, guid string, deeplink Deeplink) (BitlinkDetails, error) {
+	return b.UpdateDeeplinkContext(context.Background(), bitlink, guid, deeplink)
+}
+
+// UpdateDeeplinkContext is the same as UpdateDeeplink, but uses the context to control the lifetime of the requests.
+func (b *Bitlinks) UpdateDeeplinkContext(ctx context.Context, bitlink string, guid string, deeplink Deeplink) (BitlinkDetails, error) {
+	if err := deeplink.Validate(); err != nil {
+		return BitlinkDetails{}, err
+	}
+
+	return b.changeDeeplinks(ctx, bitlink, func(current []Deeplink) ([]Deeplink, error) {
+		idx := indexDeeplink(current, guid)
+		if idx < 0 {
+			return nil, &DeeplinkNotFoundError{Bitlink: bitlink, GUID: guid}
+		}
+
+		deeplink.GUID = guid
+		current[idx] = deeplink
+		return current, nil
+	})
+}
+
+// RemoveDeeplink removes the deeplink with the GUID from a Bitlink.
+func (b *Bitlinks) RemoveDeeplink(bitlink string, guid string) (BitlinkDetails, error) {
+	return b.RemoveDeeplinkContext(context.Background(), bitlink, guid)
+}
+
+// RemoveDeeplinkContext is the same as RemoveDeeplink, but uses the context to control the lifetime of the requests.
+func (b *Bitlinks) RemoveDeeplinkContext(ctx context.Context, bitlink string, guid string) (BitlinkDetails, error) {
+	return b.changeDeeplinks(ctx, bitlink, func(current []Deeplink) ([]Deeplink, error) {
+		idx := indexDeeplink(current, guid)
+		if idx < 0 {
+			return nil, &DeeplinkNotFoundError{Bitlink: bitlink, GUID: guid}
+		}
+
+		return append(current[:idx], current[idx+1:]...), nil
+	})
+}
+
+// changeDeeplinks retrieves the deeplinks of the Bitlink, bypassing the read cache so no deeplinks that were
+// changed elsewhere are lost, and replaces them with the result of change.
+func (b *Bitlinks) changeDeeplinks(ctx context.Context, bitlink string, change func(current []Deeplink) ([]Deeplink, error)) (BitlinkDetails, error) {
+	data, err := b.CallContext(ctx, fmt.Sprintf(retrieveBitlinkEndpoint, bitlink), http.MethodGet, nil)
+	if err != nil {
+		return BitlinkDetails{}, err
+	}
+
+	details, err := unmarshalBitlinkDetails(data)
+	if err != nil {
+		return BitlinkDetails{}, err
+	}
+
+	deeplinks, err := change(append([]Deeplink{}, details.Deeplinks...))
+	if err != nil {
+		return BitlinkDetails{}, err
+	}
+
+	return b.PatchBitlinkContext(ctx, bitlink, NewPatch().Deeplinks(deeplinks...))
+}
+
+// indexDeeplink returns the index of the deeplink with the GUID, or -1 if there is none.
+func indexDeeplink(deeplinks []Deeplink, guid string) int {
+	for idx, d := range deeplinks {
+		if len(guid) > 0 && d.GUID == guid {
+			return idx
+		}
+	}
+	return -1
+}
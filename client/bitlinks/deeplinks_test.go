@@ -0,0 +1,105 @@
+package bitlinks_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/cache"
+)
+
+func TestDeeplinkValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		deeplink bitlinks.Deeplink
+		wantErr  string
+	}{
+		{name: "valid", deeplink: bitlinks.Deeplink{InstallType: bitlinks.InstallTypeNoInstall, AppURIPath: "/store?id=1", OS: bitlinks.OSIOS}},
+		{name: "without operating system", deeplink: bitlinks.Deeplink{InstallType: bitlinks.InstallTypePromoteInstall, AppURIPath: "/store"}},
+		{name: "invalid install type", deeplink: bitlinks.Deeplink{InstallType: "always", AppURIPath: "/store"}, wantErr: "invalid install type"},
+		{name: "invalid operating system", deeplink: bitlinks.Deeplink{InstallType: bitlinks.InstallTypeAutoInstall, AppURIPath: "/store", OS: "windows"}, wantErr: "invalid operating system"},
+		{name: "empty app URI path", deeplink: bitlinks.Deeplink{InstallType: bitlinks.InstallTypeAutoInstall}, wantErr: "the app URI path is empty"},
+		{name: "whitespace in app URI path", deeplink: bitlinks.Deeplink{InstallType: bitlinks.InstallTypeAutoInstall, AppURIPath: "/my store"}, wantErr: "contains whitespace"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.deeplink.Validate()
+			switch {
+			case len(tt.wantErr) == 0 && err != nil:
+				t.Errorf("Validate() returned %s", err.Error())
+			case len(tt.wantErr) > 0 && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Validate() = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDeeplinks(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{ID: "bit.ly/app", LongURL: "https://example.org"})
+
+	// The read cache must not hide deeplinks that were just added
+	svc := bitlinks.New(srv.Client()).WithReadCache(cache.NewLRU(100), nil)
+
+	list := func() []bitlinks.Deeplink {
+		deeplinks, err := svc.ListDeeplinks("bit.ly/app")
+		if err != nil {
+			t.Fatalf("ListDeeplinks() returned %s", err.Error())
+		}
+		return deeplinks
+	}
+
+	if got := list(); len(got) != 0 {
+		t.Fatalf("got %d deeplinks, want 0", len(got))
+	}
+
+	ios := bitlinks.Deeplink{InstallType: bitlinks.InstallTypeAutoInstall, AppURIPath: "/ios", OS: bitlinks.OSIOS}
+	android := bitlinks.Deeplink{InstallType: bitlinks.InstallTypeNoInstall, AppURIPath: "/android", OS: bitlinks.OSAndroid}
+	for _, d := range []bitlinks.Deeplink{ios, android} {
+		if _, err := svc.AddDeeplink("bit.ly/app", d); err != nil {
+			t.Fatalf("AddDeeplink() returned %s", err.Error())
+		}
+	}
+
+	deeplinks := list()
+	if len(deeplinks) != 2 || len(deeplinks[0].GUID) == 0 || deeplinks[0].GUID == deeplinks[1].GUID {
+		t.Fatalf("deeplinks = %+v, want two deeplinks with their own GUID", deeplinks)
+	}
+
+	updated := android
+	updated.AppURIPath = "/android/v2"
+	if _, err := svc.UpdateDeeplink("bit.ly/app", deeplinks[1].GUID, updated); err != nil {
+		t.Fatalf("UpdateDeeplink() returned %s", err.Error())
+	}
+	if got := list(); got[1].AppURIPath != "/android/v2" || got[1].GUID != deeplinks[1].GUID {
+		t.Errorf("updated deeplink = %+v", got[1])
+	}
+
+	if _, err := svc.RemoveDeeplink("bit.ly/app", deeplinks[0].GUID); err != nil {
+		t.Fatalf("RemoveDeeplink() returned %s", err.Error())
+	}
+	if got := list(); len(got) != 1 || got[0].GUID != deeplinks[1].GUID {
+		t.Errorf("deeplinks after removing = %+v", got)
+	}
+
+	_, err := svc.RemoveDeeplink("bit.ly/app", deeplinks[0].GUID)
+	if _, ok := err.(*bitlinks.DeeplinkNotFoundError); !ok {
+		t.Errorf("RemoveDeeplink() of a removed deeplink = %v, want a *DeeplinkNotFoundError", err)
+	}
+
+	// Invalid deeplinks are rejected before anything is sent, also when they are set with a Patch
+	invalid := bitlinks.Deeplink{InstallType: "always", AppURIPath: "/"}
+	srv.ResetRequests()
+	if _, err := svc.AddDeeplink("bit.ly/app", invalid); err == nil {
+		t.Errorf("AddDeeplink() of an invalid deeplink returned no error")
+	}
+	if _, err := svc.PatchBitlink("bit.ly/app", bitlinks.NewPatch().Deeplinks(invalid)); err == nil {
+		t.Errorf("PatchBitlink() with an invalid deeplink returned no error")
+	}
+	if got := len(srv.Requests()); got != 0 {
+		t.Errorf("sent %d requests for invalid deeplinks", got)
+	}
+}
@@ -0,0 +1,213 @@
+// Package bitlinks contains the methods to interact with the Bitlinks in Bitly
+package bitlinks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/cache"
+)
+
+const (
+	// DefaultReadCacheTTL is the time Bitlinks are kept in the read cache when no TTL is set
+	DefaultReadCacheTTL = 5 * time.Minute
+	// DefaultNotFoundTTL is the time Bitlinks that don't exist are kept in the read cache when no NotFoundTTL is set
+	DefaultNotFoundTTL = time.Minute
+
+	readCacheRetrieve = "retrieve"
+	readCacheExpand   = "expand"
+)
+
+// ReadCacheOptions contains the options of the read cache.
+type ReadCacheOptions struct {
+	// The time a Bitlink is kept in the cache, defaults to DefaultReadCacheTTL
+	TTL time.Duration
+	// The time a Bitlink that doesn't exist is kept in the cache, defaults to DefaultNotFoundTTL. Set it to
+	// a negative duration to not cache Bitlinks that don't exist.
+	NotFoundTTL time.Duration
+}
+
+// cachedResponse is the response of Bitly as it is stored in the read cache.
+type cachedResponse struct {
+	NotFound bool   `json:"not_found,omitempty"`
+	Body     []byte `json:"body"`
+}
+
+// WithReadCache sets the cache of RetrieveBitlink and ExpandBitlink returning a Bitlinks pointer for
+// chaining. Concurrent lookups of the same Bitlink share a single request to Bitly. Bitlinks that are
+// updated, archived or deleted through this Bitlinks are removed from the cache.
+//
+//	svc := bitlinks.New(c).WithReadCache(cache.NewLRU(10000), &bitlinks.ReadCacheOptions{TTL: time.Hour})
+func (b *Bitlinks) WithReadCache(c cache.Cache, options *ReadCacheOptions) *Bitlinks {
+	b.readCache = c
+	b.readTTL = DefaultReadCacheTTL
+	b.notFoundTTL = DefaultNotFoundTTL
+	b.flights = &flightGroup{calls: make(map[string]*flight)}
+
+	if options != nil {
+		if options.TTL != 0 {
+			b.readTTL = options.TTL
+		}
+		if options.NotFoundTTL != 0 {
+			b.notFoundTTL = options.NotFoundTTL
+		}
+	}
+
+	return b
+}
+
+// InvalidateBitlink removes the Bitlink from the read cache, so the next lookup is sent to Bitly. Changes
+// made through this Bitlinks invalidate the cache automatically, so this is only needed when the Bitlink
+// was changed in another way.
+func (b *Bitlinks) InvalidateBitlink(bitlink string) {
+	if b.readCache == nil {
+		return
+	}
+
+	for _, kind := range []string{readCacheRetrieve, readCacheExpand} {
+		key := readCacheKey(kind, bitlink)
+		b.flights.invalidate(key, func() {
+			// A cache that fails to delete a Bitlink serves it until it expires, which is all that can be done
+			_ = b.readCache.Delete(key)
+		})
+	}
+}
+
+// cachedCall returns the response from the read cache, or calls Bitly and stores the response. Responses
+// with a 404 status code are stored as well, and returned as an APIError.
+func (b *Bitlinks) cachedCall(ctx context.Context, bitlink string, kind string, urlSuffix string, method string, payload []byte) ([]byte, error) {
+	if b.readCache == nil {
+		return b.CallContext(ctx, urlSuffix, method, payload)
+	}
+
+	key := readCacheKey(kind, bitlink)
+	if data, ok := b.readCache.Get(key); ok {
+		var res cachedResponse
+		if err := json.Unmarshal(data, &res); err == nil {
+			if res.NotFound {
+				return nil, notFoundError(res.Body)
+			}
+			return res.Body, nil
+		}
+	}
+
+	call := func() ([]byte, error) {
+		return b.CallContext(ctx, urlSuffix, method, payload)
+	}
+
+	store := func(data []byte, err error) {
+		switch {
+		case err == nil:
+			b.storeResponse(key, cachedResponse{Body: data}, b.readTTL)
+		case client.IsNotFound(err) && b.notFoundTTL > 0:
+			body := []byte{}
+			if apiErr, ok := err.(*client.APIError); ok {
+				body = apiErr.Body
+			}
+			b.storeResponse(key, cachedResponse{NotFound: true, Body: body}, b.notFoundTTL)
+		}
+	}
+
+	return b.flights.do(ctx, key, call, store)
+}
+
+// storeResponse stores the response in the read cache. A cache that fails to store the response only costs
+// a request next time, so the error is ignored.
+func (b *Bitlinks) storeResponse(key string, res cachedResponse, ttl time.Duration) {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+	_ = b.readCache.Set(key, data, ttl)
+}
+
+// notFoundError recreates the error of a cached 404 response.
+func notFoundError(body []byte) error {
+	apiErr := &client.APIError{StatusCode: http.StatusNotFound, Body: body}
+	if err := json.Unmarshal(body, apiErr); err != nil || len(apiErr.Message) == 0 {
+		apiErr.Message = "NOT_FOUND"
+	}
+	return apiErr
+}
+
+// readCacheKey identifies the response of an endpoint for a Bitlink in the read cache.
+func readCacheKey(kind string, bitlink string) string {
+	return "read\x00" + kind + "\x00" + client.TrimScheme(bitlink)
+}
+
+// flight is a request to Bitly that is in progress.
+type flight struct {
+	done        chan struct{}
+	data        []byte
+	err         error
+	invalidated bool
+	// cancelled is true when the request failed because the context of the caller that sent it was done
+	cancelled bool
+}
+
+// flightGroup makes sure that only one request for a key is in progress at a time. Callers that ask for a
+// key while a request for it is in progress wait for that request and share its result.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+// do calls fn, which must use ctx, unless a call for the key is in progress, and returns the result of the
+// call. The result is passed to store, unless the key was invalidated while the call was in progress, because
+// the result may be older than the change that caused the invalidation.
+//
+// Callers that wait for the call of another caller stop waiting when their own context is done. When that
+// call failed because the context of the other caller was done, they make the call again.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() ([]byte, error), store func(data []byte, err error)) ([]byte, error) {
+	for {
+		g.mu.Lock()
+		if f, ok := g.calls[key]; ok {
+			g.mu.Unlock()
+
+			select {
+			case <-f.done:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+
+			if f.cancelled {
+				continue
+			}
+			return f.data, f.err
+		}
+
+		f := &flight{done: make(chan struct{})}
+		g.calls[key] = f
+		g.mu.Unlock()
+
+		f.data, f.err = fn()
+		f.cancelled = f.err != nil && ctx.Err() != nil
+
+		g.mu.Lock()
+		if !f.invalidated {
+			store(f.data, f.err)
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		close(f.done)
+
+		return f.data, f.err
+	}
+}
+
+// invalidate marks the call for the key that is in progress as invalidated, so its result isn't stored,
+// and calls remove while no result for the key can be stored.
+func (g *flightGroup) invalidate(key string, remove func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if f, ok := g.calls[key]; ok {
+		f.invalidated = true
+		delete(g.calls, key)
+	}
+	remove()
+}
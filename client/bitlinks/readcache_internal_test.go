@@ -0,0 +1,195 @@
+package bitlinks
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newFlightGroup() *flightGroup {
+	return &flightGroup{calls: make(map[string]*flight)}
+}
+
+func TestFlightGroupSharesResult(t *testing.T) {
+	g := newFlightGroup()
+	release := make(chan struct{})
+	var calls, stores int32
+
+	fn := func() ([]byte, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return []byte("data"), nil
+	}
+	store := func(data []byte, err error) {
+		atomic.AddInt32(&stores, 1)
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			data, err := g.do(context.Background(), "key", fn, store)
+			if err != nil {
+				t.Errorf("do() returned %s", err.Error())
+			}
+			results[i] = string(data)
+		}(i)
+	}
+
+	waitForFlight(t, g, "key")
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("fn was called %d times, want 1", calls)
+	}
+	if stores != 1 {
+		t.Errorf("store was called %d times, want 1", stores)
+	}
+	for i, r := range results {
+		if r != "data" {
+			t.Errorf("result %d = %q, want data", i, r)
+		}
+	}
+}
+
+func TestFlightGroupFollowerContext(t *testing.T) {
+	g := newFlightGroup()
+	release := make(chan struct{})
+	defer close(release)
+
+	go g.do(context.Background(), "key", func() ([]byte, error) {
+		<-release
+		return []byte("data"), nil
+	}, func([]byte, error) {})
+	waitForFlight(t, g, "key")
+
+	// A follower stops waiting when its own context is done, even though the leader is still busy
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := g.do(ctx, "key", func() ([]byte, error) {
+		t.Error("the follower called fn")
+		return nil, nil
+	}, func([]byte, error) {})
+	if err != context.DeadlineExceeded {
+		t.Errorf("do() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestFlightGroupRetriesCancelledLeader(t *testing.T) {
+	g := newFlightGroup()
+	leaderCtx, cancelLeader := context.WithCancel(context.Background())
+	started := make(chan struct{})
+
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := g.do(leaderCtx, "key", func() ([]byte, error) {
+			close(started)
+			<-leaderCtx.Done()
+			return nil, leaderCtx.Err()
+		}, func([]byte, error) {})
+		leaderDone <- err
+	}()
+	<-started
+
+	followerDone := make(chan string, 1)
+	go func() {
+		data, err := g.do(context.Background(), "key", func() ([]byte, error) {
+			return []byte("follower"), nil
+		}, func([]byte, error) {})
+		if err != nil {
+			t.Errorf("the follower got %s", err.Error())
+		}
+		followerDone <- string(data)
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancelLeader()
+
+	if err := <-leaderDone; err != context.Canceled {
+		t.Errorf("the leader got %v, want %v", err, context.Canceled)
+	}
+	if data := <-followerDone; data != "follower" {
+		t.Errorf("the follower got %q, want the result of its own call", data)
+	}
+}
+
+func TestFlightGroupSharesErrors(t *testing.T) {
+	g := newFlightGroup()
+	release := make(chan struct{})
+	want := errors.New("bitly is down")
+
+	go g.do(context.Background(), "key", func() ([]byte, error) {
+		<-release
+		return nil, want
+	}, func([]byte, error) {})
+	waitForFlight(t, g, "key")
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := g.do(context.Background(), "key", func() ([]byte, error) {
+			return []byte("retried"), nil
+		}, func([]byte, error) {})
+		done <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	// Errors that aren't caused by the context of the leader are shared, not retried
+	if err := <-done; err != want {
+		t.Errorf("do() = %v, want %v", err, want)
+	}
+}
+
+func TestFlightGroupInvalidate(t *testing.T) {
+	g := newFlightGroup()
+	release := make(chan struct{})
+	var stored int32
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		g.do(context.Background(), "key", func() ([]byte, error) {
+			<-release
+			return []byte("stale"), nil
+		}, func([]byte, error) {
+			atomic.AddInt32(&stored, 1)
+		})
+	}()
+	waitForFlight(t, g, "key")
+
+	removed := false
+	g.invalidate("key", func() { removed = true })
+	close(release)
+	<-done
+
+	if !removed {
+		t.Errorf("remove wasn't called")
+	}
+	if stored != 0 {
+		t.Errorf("the result of an invalidated call was stored")
+	}
+}
+
+// waitForFlight waits until a call for the key is in progress.
+func waitForFlight(t *testing.T, g *flightGroup, key string) {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		_, ok := g.calls[key]
+		g.mu.Unlock()
+		if ok {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("no call for %s is in progress", key)
+}
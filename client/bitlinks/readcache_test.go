@@ -0,0 +1,192 @@
+package bitlinks_test
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/cache"
+)
+
+func TestReadCache(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *bitlinks.ReadCacheOptions
+		lookups  []string
+		notFound bool
+		requests int
+	}{
+		{name: "cached", lookups: []string{"bit.ly/cached", "bit.ly/cached", "bit.ly/cached"}, requests: 1},
+		{name: "scheme is ignored", lookups: []string{"bit.ly/cached", "https://bit.ly/cached", "http://bit.ly/cached"}, requests: 1},
+		{name: "not found is cached", lookups: []string{"bit.ly/missing", "bit.ly/missing"}, notFound: true, requests: 1},
+		{
+			name:     "not found isn't cached with a negative ttl",
+			options:  &bitlinks.ReadCacheOptions{NotFoundTTL: -1},
+			lookups:  []string{"bit.ly/missing", "bit.ly/missing"},
+			notFound: true,
+			requests: 2,
+		},
+		{
+			name:     "expired",
+			options:  &bitlinks.ReadCacheOptions{TTL: time.Nanosecond},
+			lookups:  []string{"bit.ly/cached", "bit.ly/cached"},
+			requests: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{ID: "bit.ly/cached", LongURL: "https://example.org"})
+
+			svc := bitlinks.New(srv.Client()).WithReadCache(cache.NewLRU(100), tt.options)
+			for _, bitlink := range tt.lookups {
+				details, err := svc.RetrieveBitlink(bitlink)
+				if tt.notFound {
+					if !client.IsNotFound(err) {
+						t.Fatalf("RetrieveBitlink(%s) = %v, want a 404 error", bitlink, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("RetrieveBitlink(%s) returned %s", bitlink, err.Error())
+				}
+				if details.LongURL != "https://example.org" {
+					t.Errorf("LongURL = %s, want https://example.org", details.LongURL)
+				}
+				time.Sleep(time.Millisecond)
+			}
+
+			if got := len(srv.Requests()); got != tt.requests {
+				t.Errorf("sent %d requests, want %d", got, tt.requests)
+			}
+		})
+	}
+}
+
+func TestReadCacheInvalidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(svc *bitlinks.Bitlinks, srv *bitlytest.Server) error
+		title  string
+	}{
+		{
+			name: "patch",
+			change: func(svc *bitlinks.Bitlinks, srv *bitlytest.Server) error {
+				_, err := svc.PatchBitlink("bit.ly/cached", bitlinks.NewPatch().Title("Patched"))
+				return err
+			},
+			title: "Patched",
+		},
+		{
+			name: "update",
+			change: func(svc *bitlinks.Bitlinks, srv *bitlytest.Server) error {
+				_, err := svc.UpdateBitlink("bit.ly/cached", &bitlinks.BitlinkDetails{LongURL: "https://example.org", Title: "Updated"})
+				return err
+			},
+			title: "Updated",
+		},
+		{
+			name: "invalidate",
+			change: func(svc *bitlinks.Bitlinks, srv *bitlytest.Server) error {
+				// A change that isn't made through the service is only seen after invalidating the Bitlink
+				other := bitlinks.New(srv.Client())
+				if _, err := other.PatchBitlink("bit.ly/cached", bitlinks.NewPatch().Title("Elsewhere")); err != nil {
+					return err
+				}
+				svc.InvalidateBitlink("https://bit.ly/cached")
+				return nil
+			},
+			title: "Elsewhere",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{ID: "bit.ly/cached", LongURL: "https://example.org", Title: "Original"})
+
+			svc := bitlinks.New(srv.Client()).WithReadCache(cache.NewLRU(100), nil)
+			if _, err := svc.RetrieveBitlink("bit.ly/cached"); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := tt.change(svc, srv); err != nil {
+				t.Fatal(err)
+			}
+
+			details, err := svc.RetrieveBitlink("bit.ly/cached")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if details.Title != tt.title {
+				t.Errorf("Title = %q, want %q", details.Title, tt.title)
+			}
+		})
+	}
+}
+
+func TestPatchTagsIgnoresReadCache(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{ID: "bit.ly/tagged", LongURL: "https://example.org", Tags: []string{"a"}})
+
+	svc := bitlinks.New(srv.Client()).WithReadCache(cache.NewLRU(100), nil)
+	if _, err := svc.RetrieveBitlink("bit.ly/tagged"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another writer adds a tag after the Bitlink was cached
+	if _, err := bitlinks.New(srv.Client()).PatchBitlink("bit.ly/tagged", bitlinks.NewPatch().AddTags("b")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := svc.PatchBitlink("bit.ly/tagged", bitlinks.NewPatch().AddTags("c")); err != nil {
+		t.Fatalf("PatchBitlink() returned %s", err.Error())
+	}
+
+	details, err := svc.RetrieveBitlink("bit.ly/tagged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(details.Tags, ","); got != "a,b,c" {
+		t.Errorf("Tags = %s, want a,b,c", got)
+	}
+}
+
+func TestReadCacheCoalescesLookups(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{ID: "bit.ly/slow", LongURL: "https://example.org"})
+	srv.InjectFault(bitlytest.Fault{Method: http.MethodGet, PathPrefix: "bitlinks/bit.ly/slow", Delay: 50 * time.Millisecond})
+
+	svc := bitlinks.New(srv.Client()).WithReadCache(cache.NewLRU(100), nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			details, err := svc.RetrieveBitlink("bit.ly/slow")
+			if err != nil {
+				t.Errorf("RetrieveBitlink() returned %s", err.Error())
+				return
+			}
+			if details.ID != "bit.ly/slow" {
+				t.Errorf("ID = %s, want bit.ly/slow", details.ID)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := len(srv.Requests()); got != 1 {
+		t.Errorf("sent %d requests, want 1", got)
+	}
+}
@@ -2,11 +2,14 @@
 package bitlinks
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"net/url"
+	"time"
 
 	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/cache"
+	"github.com/retgits/bitly/client/metrics"
 )
 
 const (
@@ -14,6 +17,7 @@ const (
 	bitlinksEndpoint                = "bitlinks"
 	updateBitlinkEndpoint           = "bitlinks/%s"
 	retrieveBitlinkEndpoint         = "bitlinks/%s"
+	deleteBitlinkEndpoint           = "bitlinks/%s"
 	shortenEndpoint                 = "shorten"
 	bitlinksClickSummaryEndpoint    = "bitlinks/%s/clicks/summary"
 	bitlinksClickEndpoint           = "bitlinks/%s/clicks"
@@ -23,27 +27,118 @@ const (
 	bitlinksReferringDomainEndpoint = "bitlinks/%s/referring_domains"
 )
 
+// Service is the interface implemented by Bitlinks. Code that depends on Service, instead of *Bitlinks, can use a
+// mock in its unit tests.
+type Service interface {
+	ExpandBitlink(link Link) (LinkInfo, error)
+	ExpandBitlinkContext(ctx context.Context, link Link) (LinkInfo, error)
+
+	GetMetricsByCountries(bitlink string, input *MetricsRequest) (Metrics, error)
+	GetMetricsByCountriesContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error)
+
+	GetMetricsByReferrers(bitlink string, input *MetricsRequest) (Metrics, error)
+	GetMetricsByReferrersContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error)
+
+	GetMetricsByReferrersAndDomain(bitlink string, input *MetricsRequest) (Metrics, error)
+	GetMetricsByReferrersAndDomainContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error)
+
+	GetMetricsByReferringDomains(bitlink string, input *MetricsRequest) (Metrics, error)
+	GetMetricsByReferringDomainsContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error)
+
+	CreateBitlink(bitlink *Bitlink) (BitlinkDetails, error)
+	CreateBitlinkContext(ctx context.Context, bitlink *Bitlink) (BitlinkDetails, error)
+
+	GetClicksSummary(bitlink string, input *MetricsRequest) (Metrics, error)
+	GetClicksSummaryContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error)
+
+	GetClicks(bitlink string, input *MetricsRequest) (Metrics, error)
+	GetClicksContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error)
+
+	UpdateBitlink(bitlink string, bitlinkDetails *BitlinkDetails) (BitlinkDetails, error)
+	UpdateBitlinkContext(ctx context.Context, bitlink string, bitlinkDetails *BitlinkDetails) (BitlinkDetails, error)
+
+	RetrieveBitlink(bitlink string) (BitlinkDetails, error)
+	RetrieveBitlinkContext(ctx context.Context, bitlink string) (BitlinkDetails, error)
+
+	ShortenLink(bitlink *ShortenRequest) (BitlinkDetails, error)
+	ShortenLinkContext(ctx context.Context, bitlink *ShortenRequest) (BitlinkDetails, error)
+
+	PatchBitlink(bitlink string, patch *Patch) (BitlinkDetails, error)
+	PatchBitlinkContext(ctx context.Context, bitlink string, patch *Patch) (BitlinkDetails, error)
+
+	DeleteBitlink(bitlink string) error
+	DeleteBitlinkContext(ctx context.Context, bitlink string) error
+
+	ArchiveBitlink(bitlink string) (BitlinkDetails, error)
+	ArchiveBitlinkContext(ctx context.Context, bitlink string) (BitlinkDetails, error)
+
+	UnarchiveBitlink(bitlink string) (BitlinkDetails, error)
+	UnarchiveBitlinkContext(ctx context.Context, bitlink string) (BitlinkDetails, error)
+
+	DeleteBitlinks(bitlinks []string) []BulkResult
+	DeleteBitlinksContext(ctx context.Context, bitlinks []string) []BulkResult
+
+	ArchiveBitlinks(bitlinks []string) []BulkResult
+	ArchiveBitlinksContext(ctx context.Context, bitlinks []string) []BulkResult
+
+	UnarchiveBitlinks(bitlinks []string) []BulkResult
+	UnarchiveBitlinksContext(ctx context.Context, bitlinks []string) []BulkResult
+
+	ListDeeplinks(bitlink string) ([]Deeplink, error)
+	ListDeeplinksContext(ctx context.Context, bitlink string) ([]Deeplink, error)
+
+	AddDeeplink(bitlink string, deeplink Deeplink) (BitlinkDetails, error)
+	AddDeeplinkContext(ctx context.Context, bitlink string, deeplink Deeplink) (BitlinkDetails, error)
+
+	UpdateDeeplink(bitlink string, guid string, deeplink Deeplink) (BitlinkDetails, error)
+	UpdateDeeplinkContext(ctx context.Context, bitlink string, guid string, deeplink Deeplink) (BitlinkDetails, error)
+
+	RemoveDeeplink(bitlink string, guid string) (BitlinkDetails, error)
+	RemoveDeeplinkContext(ctx context.Context, bitlink string, guid string) (BitlinkDetails, error)
+
+	BulkShorten(requests []ShortenRequest, options *BulkShortenOptions) []ShortenResult
+	BulkShortenContext(ctx context.Context, requests []ShortenRequest, options *BulkShortenOptions) []ShortenResult
+
+	BulkShortenStream(requests <-chan ShortenRequest, options *BulkShortenOptions) <-chan ShortenResult
+	BulkShortenStreamContext(ctx context.Context, requests <-chan ShortenRequest, options *BulkShortenOptions) <-chan ShortenResult
+}
+
 // Bitlinks is how we refer to shortened links. You can see these with the bit.ly domain or your
 // custom branded short domain. (Example: bit.ly/ABCDE)
 type Bitlinks struct {
 	*client.Client
+
+	shortenCache cache.Cache
+	shortenTTL   time.Duration
+
+	readCache   cache.Cache
+	readTTL     time.Duration
+	notFoundTTL time.Duration
+	flights     *flightGroup
 }
 
+var _ Service = (*Bitlinks)(nil)
+
 // New creates a new instance of the Bitlinks client.
 func New(c *client.Client) *Bitlinks {
 	return &Bitlinks{
-		c,
+		Client: c,
 	}
 }
 
 // ExpandBitlink returns public information for a Bitlink.
 func (b *Bitlinks) ExpandBitlink(link Link) (LinkInfo, error) {
+	return b.ExpandBitlinkContext(context.Background(), link)
+}
+
+// ExpandBitlinkContext is the same as ExpandBitlink, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) ExpandBitlinkContext(ctx context.Context, link Link) (LinkInfo, error) {
 	payload, err := link.marshal()
 	if err != nil {
 		return LinkInfo{}, err
 	}
 
-	data, err := b.Call(expandEndpoint, http.MethodPost, payload)
+	data, err := b.cachedCall(ctx, link.BitlinkID, readCacheExpand, expandEndpoint, http.MethodPost, payload)
 	if err != nil {
 		return LinkInfo{}, err
 	}
@@ -53,137 +148,148 @@ func (b *Bitlinks) ExpandBitlink(link Link) (LinkInfo, error) {
 
 // GetMetricsByCountries will return metrics about the countries referring click traffic to a single Bitlink.
 func (b *Bitlinks) GetMetricsByCountries(bitlink string, input *MetricsRequest) (Metrics, error) {
-	v := url.Values{}
-
-	if len(input.Unit) > 0 {
-		v.Add("unit", input.Unit)
-	}
-
-	if input.Units != 0 {
-		v.Add("units", fmt.Sprintf("%d", input.Units))
-	}
+	return b.GetMetricsByCountriesContext(context.Background(), bitlink, input)
+}
 
-	if len(input.UnitReference) > 0 {
-		v.Add("unit_reference", input.UnitReference)
-	}
+// GetMetricsByCountriesContext is the same as GetMetricsByCountries, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) GetMetricsByCountriesContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.getMetrics(ctx, fmt.Sprintf(bitlinksCountryEndpoint, bitlink), input)
+}
 
-	if input.Size != 0 {
-		v.Add("size", fmt.Sprintf("%d", input.Size))
-	}
+// GetMetricsByReferrers will return metrics about the referrers referring click traffic to a single Bitlink.
+func (b *Bitlinks) GetMetricsByReferrers(bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.GetMetricsByReferrersContext(context.Background(), bitlink, input)
+}
 
-	queryParams := v.Encode()
+// GetMetricsByReferrersContext is the same as GetMetricsByReferrers, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) GetMetricsByReferrersContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.getMetrics(ctx, fmt.Sprintf(bitlinksReferrersEndpoint, bitlink), input)
+}
 
-	url := fmt.Sprintf(bitlinksCountryEndpoint, bitlink)
-	if len(queryParams) > 1 {
-		url = fmt.Sprintf("%s?%s", url, queryParams)
-	}
+// GetMetricsByReferrersAndDomain will group referrers metrics about a single Bitlink.
+func (b *Bitlinks) GetMetricsByReferrersAndDomain(bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.GetMetricsByReferrersAndDomainContext(context.Background(), bitlink, input)
+}
 
-	data, err := b.Call(url, http.MethodGet, nil)
-	if err != nil {
-		return Metrics{}, err
-	}
+// GetMetricsByReferrersAndDomainContext is the same as GetMetricsByReferrersAndDomain, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) GetMetricsByReferrersAndDomainContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.getMetrics(ctx, fmt.Sprintf(bitlinksReferrersDomainEndpoint, bitlink), input)
+}
 
-	return unmarshalMetrics(data)
+// GetMetricsByReferringDomains will rollup the click counts to a referrer about a single Bitlink.
+func (b *Bitlinks) GetMetricsByReferringDomains(bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.GetMetricsByReferringDomainsContext(context.Background(), bitlink, input)
 }
 
-// GetMetricsByReferrers will return metrics about the referrers referring click traffic to a single Bitlink.
-func (b *Bitlinks) GetMetricsByReferrers(bitlink string, input *MetricsRequest) (Metrics, error) {
-	v := url.Values{}
+// GetMetricsByReferringDomainsContext is the same as GetMetricsByReferringDomains, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) GetMetricsByReferringDomainsContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.getMetrics(ctx, fmt.Sprintf(bitlinksReferringDomainEndpoint, bitlink), input)
+}
 
-	if len(input.Unit) > 0 {
-		v.Add("unit", input.Unit)
-	}
+// CreateBitlink will convert a long url to a Bitlink and set additional parameters.
+func (b *Bitlinks) CreateBitlink(bitlink *Bitlink) (BitlinkDetails, error) {
+	return b.CreateBitlinkContext(context.Background(), bitlink)
+}
 
-	if input.Units != 0 {
-		v.Add("units", fmt.Sprintf("%d", input.Units))
+// CreateBitlinkContext is the same as CreateBitlink, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) CreateBitlinkContext(ctx context.Context, bitlink *Bitlink) (BitlinkDetails, error) {
+	if err := validateDeeplinks(bitlink.Deeplinks); err != nil {
+		return BitlinkDetails{}, err
 	}
 
-	if len(input.UnitReference) > 0 {
-		v.Add("unit_reference", input.UnitReference)
+	// A cached Bitlink doesn't have the title, tags and deeplinks of the request, so it is only used when
+	// the request has none of them
+	if len(bitlink.Title) == 0 && len(bitlink.Tags) == 0 && len(bitlink.Deeplinks) == 0 {
+		if details, ok := b.cachedShorten(bitlink.LongURL, bitlink.Domain, bitlink.GroupGUID); ok {
+			return details, nil
+		}
 	}
 
-	if input.Size != 0 {
-		v.Add("size", fmt.Sprintf("%d", input.Size))
+	payload, err := bitlink.marshal()
+	if err != nil {
+		return BitlinkDetails{}, err
 	}
 
-	queryParams := v.Encode()
-
-	url := fmt.Sprintf(bitlinksReferrersEndpoint, bitlink)
-	if len(queryParams) > 1 {
-		url = fmt.Sprintf("%s?%s", url, queryParams)
+	data, err := b.CallContext(ctx, bitlinksEndpoint, http.MethodPost, payload)
+	if err != nil {
+		return BitlinkDetails{}, err
 	}
 
-	data, err := b.Call(url, http.MethodGet, nil)
+	details, err := unmarshalBitlinkDetails(data)
 	if err != nil {
-		return Metrics{}, err
+		return BitlinkDetails{}, err
 	}
 
-	return unmarshalMetrics(data)
+	b.storeShorten(bitlink.LongURL, bitlink.Domain, bitlink.GroupGUID, details)
+	return details, nil
 }
 
-// GetMetricsByReferrersAndDomain will group referrers metrics about a single Bitlink.
-func (b *Bitlinks) GetMetricsByReferrersAndDomain(bitlink string, input *MetricsRequest) (Metrics, error) {
-	v := url.Values{}
+// GetClicksSummary will return the click counts for a specified Bitlink. This rolls up all the data into a single field of clicks.
+func (b *Bitlinks) GetClicksSummary(bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.GetClicksSummaryContext(context.Background(), bitlink, input)
+}
 
-	if len(input.Unit) > 0 {
-		v.Add("unit", input.Unit)
-	}
+// GetClicksSummaryContext is the same as GetClicksSummary, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) GetClicksSummaryContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.getMetrics(ctx, fmt.Sprintf(bitlinksClickSummaryEndpoint, bitlink), input)
+}
 
-	if input.Units != 0 {
-		v.Add("units", fmt.Sprintf("%d", input.Units))
-	}
+// GetClicks will return the click counts for a specified Bitlink. This returns an array with clicks based on a date.
+func (b *Bitlinks) GetClicks(bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.GetClicksContext(context.Background(), bitlink, input)
+}
 
-	if len(input.UnitReference) > 0 {
-		v.Add("unit_reference", input.UnitReference)
-	}
+// GetClicksContext is the same as GetClicks, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) GetClicksContext(ctx context.Context, bitlink string, input *MetricsRequest) (Metrics, error) {
+	return b.getMetrics(ctx, fmt.Sprintf(bitlinksClickEndpoint, bitlink), input)
+}
 
-	if input.Size != 0 {
-		v.Add("size", fmt.Sprintf("%d", input.Size))
-	}
+// UpdateBitlink will update fields in the Bitlink. All fields of the BitlinkDetails are sent to Bitly, use
+// PatchBitlink to only update specific fields.
+func (b *Bitlinks) UpdateBitlink(bitlink string, bitlinkDetails *BitlinkDetails) (BitlinkDetails, error) {
+	return b.UpdateBitlinkContext(context.Background(), bitlink, bitlinkDetails)
+}
 
-	queryParams := v.Encode()
+// UpdateBitlinkContext is the same as UpdateBitlink, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) UpdateBitlinkContext(ctx context.Context, bitlink string, bitlinkDetails *BitlinkDetails) (BitlinkDetails, error) {
+	defer b.InvalidateBitlink(bitlink)
 
-	url := fmt.Sprintf(bitlinksReferrersDomainEndpoint, bitlink)
-	if len(queryParams) > 1 {
-		url = fmt.Sprintf("%s?%s", url, queryParams)
+	payload, err := bitlinkDetails.marshal()
+	if err != nil {
+		return BitlinkDetails{}, err
 	}
 
-	data, err := b.Call(url, http.MethodGet, nil)
+	data, err := b.CallContext(ctx, fmt.Sprintf(updateBitlinkEndpoint, bitlink), http.MethodPatch, payload)
 	if err != nil {
-		return Metrics{}, err
+		return BitlinkDetails{}, err
 	}
 
-	return unmarshalMetrics(data)
+	return unmarshalBitlinkDetails(data)
 }
 
-// GetMetricsByReferringDomains will rollup the click counts to a referrer about a single Bitlink.
-func (b *Bitlinks) GetMetricsByReferringDomains(bitlink string, input *MetricsRequest) (Metrics, error) {
-	v := url.Values{}
-
-	if len(input.Unit) > 0 {
-		v.Add("unit", input.Unit)
-	}
-
-	if input.Units != 0 {
-		v.Add("units", fmt.Sprintf("%d", input.Units))
-	}
-
-	if len(input.UnitReference) > 0 {
-		v.Add("unit_reference", input.UnitReference)
-	}
+// RetrieveBitlink returns information for a Bitlink.
+func (b *Bitlinks) RetrieveBitlink(bitlink string) (BitlinkDetails, error) {
+	return b.RetrieveBitlinkContext(context.Background(), bitlink)
+}
 
-	if input.Size != 0 {
-		v.Add("size", fmt.Sprintf("%d", input.Size))
+// RetrieveBitlinkContext is the same as RetrieveBitlink, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) RetrieveBitlinkContext(ctx context.Context, bitlink string) (BitlinkDetails, error) {
+	data, err := b.cachedCall(ctx, bitlink, readCacheRetrieve, fmt.Sprintf(retrieveBitlinkEndpoint, bitlink), http.MethodGet, nil)
+	if err != nil {
+		return BitlinkDetails{}, err
 	}
 
-	queryParams := v.Encode()
+	return unmarshalBitlinkDetails(data)
+}
 
-	url := fmt.Sprintf(bitlinksReferringDomainEndpoint, bitlink)
-	if len(queryParams) > 1 {
-		url = fmt.Sprintf("%s?%s", url, queryParams)
+// getMetrics retrieves the metrics from the endpoint, after validating the request.
+func (b *Bitlinks) getMetrics(ctx context.Context, endpoint string, input *MetricsRequest) (Metrics, error) {
+	url, err := metrics.Encode(endpoint, input)
+	if err != nil {
+		return Metrics{}, err
 	}
 
-	data, err := b.Call(url, http.MethodGet, nil)
+	data, err := b.CallContext(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return Metrics{}, err
 	}
@@ -191,127 +297,188 @@ func (b *Bitlinks) GetMetricsByReferringDomains(bitlink string, input *MetricsRe
 	return unmarshalMetrics(data)
 }
 
-// CreateBitlink will convert a long url to a Bitlink and set additional parameters.
-func (b *Bitlinks) CreateBitlink(bitlink *Bitlink) (BitlinkDetails, error) {
+// ShortenLink will convert a long url to a Bitlink. When the Bitlinks has a shorten cache, a long url that was
+// shortened before is returned from the cache.
+func (b *Bitlinks) ShortenLink(bitlink *ShortenRequest) (BitlinkDetails, error) {
+	return b.ShortenLinkContext(context.Background(), bitlink)
+}
+
+// ShortenLinkContext is the same as ShortenLink, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) ShortenLinkContext(ctx context.Context, bitlink *ShortenRequest) (BitlinkDetails, error) {
+	if details, ok := b.cachedShorten(bitlink.LongURL, bitlink.Domain, bitlink.GroupGUID); ok {
+		return details, nil
+	}
+
 	payload, err := bitlink.marshal()
 	if err != nil {
 		return BitlinkDetails{}, err
 	}
 
-	data, err := b.Call(bitlinksEndpoint, http.MethodPost, payload)
+	data, err := b.CallContext(ctx, shortenEndpoint, http.MethodPost, payload)
 	if err != nil {
 		return BitlinkDetails{}, err
 	}
 
-	return unmarshalBitlinkDetails(data)
-}
-
-// GetClicksSummary will return the click counts for a specified Bitlink. This rolls up all the data into a single field of clicks.
-func (b *Bitlinks) GetClicksSummary(bitlink string, input *MetricsRequest) (Metrics, error) {
-	v := url.Values{}
-
-	if len(input.Unit) > 0 {
-		v.Add("unit", input.Unit)
-	}
-
-	if input.Units != 0 {
-		v.Add("units", fmt.Sprintf("%d", input.Units))
-	}
-
-	if len(input.UnitReference) > 0 {
-		v.Add("unit_reference", input.UnitReference)
+	details, err := unmarshalBitlinkDetails(data)
+	if err != nil {
+		return BitlinkDetails{}, err
 	}
 
-	if input.Size != 0 {
-		v.Add("size", fmt.Sprintf("%d", input.Size))
-	}
+	b.storeShorten(bitlink.LongURL, bitlink.Domain, bitlink.GroupGUID, details)
+	return details, nil
+}
 
-	queryParams := v.Encode()
+// PatchBitlink will update only the fields of the Bitlink that are set in the Patch. When tags are added or
+// removed, the Bitlink is retrieved first to determine the new set of tags.
+func (b *Bitlinks) PatchBitlink(bitlink string, patch *Patch) (BitlinkDetails, error) {
+	return b.PatchBitlinkContext(context.Background(), bitlink, patch)
+}
 
-	url := fmt.Sprintf(bitlinksClickSummaryEndpoint, bitlink)
-	if len(queryParams) > 1 {
-		url = fmt.Sprintf("%s?%s", url, queryParams)
+// PatchBitlinkContext is the same as PatchBitlink, but uses the context to control the lifetime of the requests.
+func (b *Bitlinks) PatchBitlinkContext(ctx context.Context, bitlink string, patch *Patch) (BitlinkDetails, error) {
+	defer b.InvalidateBitlink(bitlink)
+
+	req := patchRequest{
+		LongURL:   patch.longURL,
+		Title:     patch.title,
+		Archived:  patch.archived,
+		Tags:      patch.tags,
+		Deeplinks: patch.deeplinks,
+	}
+
+	if patch.deeplinks != nil {
+		if err := validateDeeplinks(*patch.deeplinks); err != nil {
+			return BitlinkDetails{}, err
+		}
+	}
+
+	if patch.needsCurrentTags() {
+		// The tags are read from Bitly instead of the read cache, so tags added by others aren't lost
+		data, err := b.CallContext(ctx, fmt.Sprintf(retrieveBitlinkEndpoint, bitlink), http.MethodGet, nil)
+		if err != nil {
+			return BitlinkDetails{}, err
+		}
+		current, err := unmarshalBitlinkDetails(data)
+		if err != nil {
+			return BitlinkDetails{}, err
+		}
+		tags := patch.mergeTags(current.Tags)
+		req.Tags = &tags
+	} else if patch.tags != nil {
+		tags := patch.mergeTags(nil)
+		req.Tags = &tags
+	}
+
+	payload, err := req.marshal()
+	if err != nil {
+		return BitlinkDetails{}, err
 	}
 
-	data, err := b.Call(url, http.MethodGet, nil)
+	data, err := b.CallContext(ctx, fmt.Sprintf(updateBitlinkEndpoint, bitlink), http.MethodPatch, payload)
 	if err != nil {
-		return Metrics{}, err
+		return BitlinkDetails{}, err
 	}
 
-	return unmarshalMetrics(data)
+	return unmarshalBitlinkDetails(data)
 }
 
-// GetClicks will return the click counts for a specified Bitlink. This returns an array with clicks based on a date.
-func (b *Bitlinks) GetClicks(bitlink string, input *MetricsRequest) (Metrics, error) {
-	v := url.Values{}
-
-	if len(input.Unit) > 0 {
-		v.Add("unit", input.Unit)
-	}
+// DeleteBitlink will delete a Bitlink.
+func (b *Bitlinks) DeleteBitlink(bitlink string) error {
+	return b.DeleteBitlinkContext(context.Background(), bitlink)
+}
 
-	if input.Units != 0 {
-		v.Add("units", fmt.Sprintf("%d", input.Units))
-	}
+// DeleteBitlinkContext is the same as DeleteBitlink, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) DeleteBitlinkContext(ctx context.Context, bitlink string) error {
+	defer b.InvalidateBitlink(bitlink)
 
-	if len(input.UnitReference) > 0 {
-		v.Add("unit_reference", input.UnitReference)
-	}
+	_, err := b.CallContext(ctx, fmt.Sprintf(deleteBitlinkEndpoint, bitlink), http.MethodDelete, nil)
+	return err
+}
 
-	if input.Size != 0 {
-		v.Add("size", fmt.Sprintf("%d", input.Size))
-	}
+// ArchiveBitlink will archive a Bitlink, without changing any of its other fields.
+func (b *Bitlinks) ArchiveBitlink(bitlink string) (BitlinkDetails, error) {
+	return b.ArchiveBitlinkContext(context.Background(), bitlink)
+}
 
-	queryParams := v.Encode()
+// ArchiveBitlinkContext is the same as ArchiveBitlink, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) ArchiveBitlinkContext(ctx context.Context, bitlink string) (BitlinkDetails, error) {
+	return b.setArchived(ctx, bitlink, true)
+}
 
-	url := fmt.Sprintf(bitlinksClickEndpoint, bitlink)
-	if len(queryParams) > 1 {
-		url = fmt.Sprintf("%s?%s", url, queryParams)
-	}
+// UnarchiveBitlink will unarchive a Bitlink, without changing any of its other fields.
+func (b *Bitlinks) UnarchiveBitlink(bitlink string) (BitlinkDetails, error) {
+	return b.UnarchiveBitlinkContext(context.Background(), bitlink)
+}
 
-	data, err := b.Call(url, http.MethodGet, nil)
-	if err != nil {
-		return Metrics{}, err
-	}
+// UnarchiveBitlinkContext is the same as UnarchiveBitlink, but uses the context to control the lifetime of the request.
+func (b *Bitlinks) UnarchiveBitlinkContext(ctx context.Context, bitlink string) (BitlinkDetails, error) {
+	return b.setArchived(ctx, bitlink, false)
+}
 
-	return unmarshalMetrics(data)
+// DeleteBitlinks will delete all Bitlinks and report the result for each of them.
+func (b *Bitlinks) DeleteBitlinks(bitlinks []string) []BulkResult {
+	return b.DeleteBitlinksContext(context.Background(), bitlinks)
 }
 
-// UpdateBitlink will update fields in the Bitlink.
-func (b *Bitlinks) UpdateBitlink(bitlink string, bitlinkDetails *BitlinkDetails) (BitlinkDetails, error) {
-	payload, err := bitlinkDetails.marshal()
-	if err != nil {
-		return BitlinkDetails{}, err
-	}
+// DeleteBitlinksContext is the same as DeleteBitlinks, but uses the context to control the lifetime of the requests.
+func (b *Bitlinks) DeleteBitlinksContext(ctx context.Context, bitlinks []string) []BulkResult {
+	return bulk(bitlinks, func(bitlink string) (BitlinkDetails, error) {
+		return BitlinkDetails{}, b.DeleteBitlinkContext(ctx, bitlink)
+	})
+}
 
-	data, err := b.Call(fmt.Sprintf(updateBitlinkEndpoint, bitlink), http.MethodPatch, payload)
-	if err != nil {
-		return BitlinkDetails{}, err
-	}
+// ArchiveBitlinks will archive all Bitlinks and report the result for each of them.
+func (b *Bitlinks) ArchiveBitlinks(bitlinks []string) []BulkResult {
+	return b.ArchiveBitlinksContext(context.Background(), bitlinks)
+}
 
-	return unmarshalBitlinkDetails(data)
+// ArchiveBitlinksContext is the same as ArchiveBitlinks, but uses the context to control the lifetime of the requests.
+func (b *Bitlinks) ArchiveBitlinksContext(ctx context.Context, bitlinks []string) []BulkResult {
+	return bulk(bitlinks, func(bitlink string) (BitlinkDetails, error) {
+		return b.setArchived(ctx, bitlink, true)
+	})
 }
 
-// RetrieveBitlink returns information for a Bitlink.
-func (b *Bitlinks) RetrieveBitlink(bitlink string) (BitlinkDetails, error) {
-	data, err := b.Call(fmt.Sprintf(retrieveBitlinkEndpoint, bitlink), http.MethodGet, nil)
-	if err != nil {
-		return BitlinkDetails{}, err
-	}
+// UnarchiveBitlinks will unarchive all Bitlinks and report the result for each of them.
+func (b *Bitlinks) UnarchiveBitlinks(bitlinks []string) []BulkResult {
+	return b.UnarchiveBitlinksContext(context.Background(), bitlinks)
+}
 
-	return unmarshalBitlinkDetails(data)
+// UnarchiveBitlinksContext is the same as UnarchiveBitlinks, but uses the context to control the lifetime of the requests.
+func (b *Bitlinks) UnarchiveBitlinksContext(ctx context.Context, bitlinks []string) []BulkResult {
+	return bulk(bitlinks, func(bitlink string) (BitlinkDetails, error) {
+		return b.setArchived(ctx, bitlink, false)
+	})
 }
 
-// ShortenLink will convert a long url to a Bitlink.
-func (b *Bitlinks) ShortenLink(bitlink *ShortenRequest) (BitlinkDetails, error) {
-	payload, err := bitlink.marshal()
+// setArchived only sends the archived field to Bitly, so the other fields of the Bitlink are left untouched.
+func (b *Bitlinks) setArchived(ctx context.Context, bitlink string, archived bool) (BitlinkDetails, error) {
+	defer b.InvalidateBitlink(bitlink)
+
+	req := archiveRequest{Archived: archived}
+	payload, err := req.marshal()
 	if err != nil {
 		return BitlinkDetails{}, err
 	}
 
-	data, err := b.Call(shortenEndpoint, http.MethodPost, payload)
+	data, err := b.CallContext(ctx, fmt.Sprintf(updateBitlinkEndpoint, bitlink), http.MethodPatch, payload)
 	if err != nil {
 		return BitlinkDetails{}, err
 	}
 
 	return unmarshalBitlinkDetails(data)
 }
+
+// bulk runs the operation for each Bitlink, in order, and collects the results.
+func bulk(bitlinks []string, op func(bitlink string) (BitlinkDetails, error)) []BulkResult {
+	results := make([]BulkResult, len(bitlinks))
+	for idx, bitlink := range bitlinks {
+		details, err := op(bitlink)
+		results[idx] = BulkResult{
+			Bitlink: bitlink,
+			Details: details,
+			Err:     err,
+		}
+	}
+	return results
+}
@@ -0,0 +1,39 @@
+package bitlinks_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bitlytest"
+)
+
+func TestUpdateBitlinkOmitsEmptyTimestamps(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	created := srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{ID: "bit.ly/update", LongURL: "https://example.org"})
+
+	svc := bitlinks.New(srv.Client())
+	update := &bitlinks.BitlinkDetails{
+		LongURL:   "https://example.org/v2",
+		Deeplinks: []bitlinks.Deeplink{{InstallType: "no_install", AppURIPath: "/app"}},
+	}
+	if _, err := svc.UpdateBitlink("bit.ly/update", update); err != nil {
+		t.Fatalf("UpdateBitlink() returned %s", err.Error())
+	}
+
+	body := string(srv.Requests()[0].Body)
+	for _, field := range []string{"created_at", "created", "modified"} {
+		if strings.Contains(body, `"`+field+`"`) {
+			t.Errorf("UpdateBitlink() sent the empty timestamp %s: %s", field, body)
+		}
+	}
+
+	details, err := svc.RetrieveBitlink("bit.ly/update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.CreatedAt == nil || !details.CreatedAt.Equal(created.CreatedAt.Time) {
+		t.Errorf("CreatedAt = %v, want %s", details.CreatedAt, created.CreatedAt)
+	}
+}
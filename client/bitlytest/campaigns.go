@@ -0,0 +1,255 @@
+package bitlytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/retgits/bitly/client/campaigns"
+)
+
+func (s *Server) handleCampaigns(w http.ResponseWriter, r *http.Request, body []byte) {
+	switch r.Method {
+	case http.MethodGet:
+		groupGUID := r.URL.Query().Get("group_guid")
+
+		res := campaigns.BitlyCampaigns{Campaigns: []campaigns.Campaign{}}
+		guids := make([]string, 0, len(s.campaigns))
+		for guid := range s.campaigns {
+			guids = append(guids, guid)
+		}
+		sort.Strings(guids)
+
+		for _, guid := range guids {
+			if c := s.campaigns[guid]; len(groupGUID) == 0 || c.GroupGUID == groupGUID {
+				res.Campaigns = append(res.Campaigns, c)
+			}
+		}
+		writeJSON(w, http.StatusOK, res)
+	case http.MethodPost:
+		var req campaigns.CampaignRequest
+		if err := json.Unmarshal(body, &req); err != nil || len(req.Name) == 0 {
+			writeInvalidArgument(w, "campaigns", "name")
+			return
+		}
+
+		groupGUID, ok := s.groupOrDefault(w, "campaigns", req.GroupGUID)
+		if !ok {
+			return
+		}
+
+		s.sequence++
+		c := campaigns.Campaign{
+			Created:      now(),
+			Modified:     now(),
+			GroupGUID:    groupGUID,
+			GUID:         fmt.Sprintf("Cp%08d", s.sequence),
+			ChannelGUIDs: []string{},
+			Description:  req.Description,
+			Name:         req.Name,
+			CreatedBy:    s.user.Login,
+		}
+		if req.ChannelGUIDs != nil {
+			c.ChannelGUIDs = req.ChannelGUIDs
+		}
+
+		s.campaigns[c.GUID] = c
+		writeJSON(w, http.StatusCreated, c)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "campaigns", "The method isn't allowed")
+	}
+}
+
+func (s *Server) handleCampaign(w http.ResponseWriter, r *http.Request, guid string, body []byte) {
+	c, ok := s.campaigns[guid]
+	if !ok {
+		writeNotFound(w, "campaigns")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, c)
+	case http.MethodPatch:
+		var req campaigns.CampaignRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeInvalidArgument(w, "campaigns", "body")
+			return
+		}
+
+		if len(req.Name) > 0 {
+			c.Name = req.Name
+		}
+		if len(req.Description) > 0 {
+			c.Description = req.Description
+		}
+		if req.ChannelGUIDs != nil {
+			c.ChannelGUIDs = req.ChannelGUIDs
+		}
+		c.Modified = now()
+
+		s.campaigns[guid] = c
+		writeJSON(w, http.StatusOK, c)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "campaigns", "The method isn't allowed")
+	}
+}
+
+func (s *Server) handleChannels(w http.ResponseWriter, r *http.Request, body []byte) {
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+
+		res := campaigns.BitlyChannels{Channels: []campaigns.Channel{}}
+		guids := make([]string, 0, len(s.channels))
+		for guid := range s.channels {
+			guids = append(guids, guid)
+		}
+		sort.Strings(guids)
+
+		for _, guid := range guids {
+			c := s.channels[guid]
+			if len(q.Get("group_guid")) > 0 && c.GroupGUID != q.Get("group_guid") {
+				continue
+			}
+			if len(q.Get("campaign_guid")) > 0 && !s.inCampaign(c, q.Get("campaign_guid")) {
+				continue
+			}
+			res.Channels = append(res.Channels, c)
+		}
+		writeJSON(w, http.StatusOK, res)
+	case http.MethodPost:
+		var req campaigns.ChannelRequest
+		if err := json.Unmarshal(body, &req); err != nil || len(req.Name) == 0 {
+			writeInvalidArgument(w, "channels", "name")
+			return
+		}
+
+		groupGUID, ok := s.groupOrDefault(w, "channels", req.GroupGUID)
+		if !ok {
+			return
+		}
+
+		if !s.validChannelBitlinks(w, req.Bitlinks) {
+			return
+		}
+
+		s.sequence++
+		c := campaigns.Channel{
+			Created:   now(),
+			Modified:  now(),
+			GroupGUID: groupGUID,
+			GUID:      fmt.Sprintf("Ch%08d", s.sequence),
+			Name:      req.Name,
+			Bitlinks:  append([]campaigns.ChannelBitlink{}, req.Bitlinks...),
+		}
+
+		s.channels[c.GUID] = c
+		writeJSON(w, http.StatusCreated, c)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "channels", "The method isn't allowed")
+	}
+}
+
+func (s *Server) handleChannel(w http.ResponseWriter, r *http.Request, guid string, body []byte) {
+	c, ok := s.channels[guid]
+	if !ok {
+		writeNotFound(w, "channels")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, c)
+	case http.MethodPatch:
+		var req struct {
+			Name     string                      `json:"name"`
+			Bitlinks *[]campaigns.ChannelBitlink `json:"bitlinks"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeInvalidArgument(w, "channels", "body")
+			return
+		}
+
+		if len(req.Name) > 0 {
+			c.Name = req.Name
+		}
+		if req.Bitlinks != nil {
+			if !s.validChannelBitlinks(w, *req.Bitlinks) {
+				return
+			}
+			c.Bitlinks = append([]campaigns.ChannelBitlink{}, *req.Bitlinks...)
+		}
+		c.Modified = now()
+
+		s.channels[guid] = c
+		writeJSON(w, http.StatusOK, c)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "channels", "The method isn't allowed")
+	}
+}
+
+// groupOrDefault returns the group, or the default group of the user when it is empty. It writes an error and
+// returns false when the group doesn't exist.
+func (s *Server) groupOrDefault(w http.ResponseWriter, resource string, groupGUID string) (string, bool) {
+	if len(groupGUID) == 0 {
+		groupGUID = s.user.DefaultGroupGUID
+	}
+
+	if _, ok := s.groups[groupGUID]; !ok {
+		writeInvalidArgument(w, resource, "group_guid")
+		return "", false
+	}
+	return groupGUID, true
+}
+
+// validChannelBitlinks writes an error and returns false when a Bitlink or campaign doesn't exist.
+func (s *Server) validChannelBitlinks(w http.ResponseWriter, bitlinks []campaigns.ChannelBitlink) bool {
+	for _, b := range bitlinks {
+		if _, ok := s.links[b.BitlinkID]; !ok {
+			writeInvalidArgument(w, "channels", "bitlink_id")
+			return false
+		}
+		if _, ok := s.campaigns[b.CampaignGUID]; len(b.CampaignGUID) > 0 && !ok {
+			writeInvalidArgument(w, "channels", "campaign_guid")
+			return false
+		}
+	}
+	return true
+}
+
+// inCampaign returns true if the channel is one of the channels of the campaign, or has Bitlinks for it.
+func (s *Server) inCampaign(c campaigns.Channel, campaignGUID string) bool {
+	for _, guid := range s.campaigns[campaignGUID].ChannelGUIDs {
+		if guid == c.GUID {
+			return true
+		}
+	}
+	for _, b := range c.Bitlinks {
+		if b.CampaignGUID == campaignGUID {
+			return true
+		}
+	}
+	return false
+}
+
+// inChannel returns true if the Bitlink matches the campaign and channel filters of a request for the
+// Bitlinks of a group. Empty filters match all Bitlinks.
+func (s *Server) inChannel(bitlink string, campaignGUID string, channelGUID string) bool {
+	if len(campaignGUID) == 0 && len(channelGUID) == 0 {
+		return true
+	}
+
+	for guid, c := range s.channels {
+		if len(channelGUID) > 0 && guid != channelGUID {
+			continue
+		}
+		for _, b := range c.Bitlinks {
+			if b.BitlinkID == bitlink && (len(campaignGUID) == 0 || b.CampaignGUID == campaignGUID) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,759 @@
+// Package bitlytest provides an in-process fake of the Bitly v4 API, so code that uses this module can be
+// tested without network access or an access token.
+package bitlytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/retgits/bitly"
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bsds"
+	"github.com/retgits/bitly/client/custombitlinks"
+	"github.com/retgits/bitly/client/groups"
+	"github.com/retgits/bitly/client/organizations"
+)
+
+const (
+	alphabet        = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	defaultPageSize = 50
+)
+
+// bitlinkMetricsSuffixes are the metrics endpoints of a single Bitlink.
+var bitlinkMetricsSuffixes = []string{"/clicks/summary", "/clicks", "/countries", "/country", "/referrers_by_domains", "/referrers", "/referring_domains"}
+
+// groupMetricsSuffixes are the metrics endpoints of a group.
+var groupMetricsSuffixes = []string{"countries", "referring_networks", "shorten_counts"}
+
+// route dispatches the request to the handler of the endpoint. The caller must hold the lock.
+func (s *Server) route(w http.ResponseWriter, r *http.Request, path string, body []byte) {
+	segments := strings.Split(path, "/")
+
+	switch {
+	case path == "shorten" && r.Method == http.MethodPost:
+		s.handleShorten(w, body)
+	case path == "expand" && r.Method == http.MethodPost:
+		s.handleExpand(w, body)
+	case path == "bitlinks" && r.Method == http.MethodPost:
+		s.handleCreateBitlink(w, body)
+	case strings.HasPrefix(path, "bitlinks/"):
+		s.handleBitlink(w, r, strings.TrimPrefix(path, "bitlinks/"), body)
+	case path == "custom_bitlinks" && r.Method == http.MethodPost:
+		s.handleAddCustomBitlink(w, body)
+	case strings.HasPrefix(path, "custom_bitlinks/"):
+		s.handleCustomBitlink(w, r, strings.TrimPrefix(path, "custom_bitlinks/"), body)
+	case path == "campaigns":
+		s.handleCampaigns(w, r, body)
+	case segments[0] == "campaigns" && len(segments) == 2:
+		s.handleCampaign(w, r, segments[1], body)
+	case path == "channels":
+		s.handleChannels(w, r, body)
+	case segments[0] == "channels" && len(segments) == 2:
+		s.handleChannel(w, r, segments[1], body)
+	case path == "groups" && r.Method == http.MethodGet:
+		s.handleGroups(w, r)
+	case segments[0] == "groups" && len(segments) >= 2:
+		s.handleGroup(w, r, segments[1], segments[2:], body)
+	case path == "organizations" && r.Method == http.MethodGet:
+		s.handleOrganizations(w)
+	case segments[0] == "organizations" && len(segments) >= 2:
+		s.handleOrganization(w, r, segments[1], segments[2:])
+	case path == "user":
+		s.handleUser(w, r, body)
+	case path == "bsds" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, bsds.BSD{AllBSDs: s.bsds})
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "", "The endpoint doesn't exist")
+	}
+}
+
+func (s *Server) handleShorten(w http.ResponseWriter, body []byte) {
+	var req bitlinks.ShortenRequest
+	if err := json.Unmarshal(body, &req); err != nil || len(req.LongURL) == 0 {
+		writeInvalidArgument(w, "bitlinks", "long_url")
+		return
+	}
+
+	if existing := s.findByLongURL(req.GroupGUID, req.Domain, req.LongURL); existing != nil {
+		writeJSON(w, http.StatusOK, existing.details)
+		return
+	}
+
+	l := s.addBitlink(req.GroupGUID, bitlinks.BitlinkDetails{
+		LongURL: req.LongURL,
+		ID:      s.newID(req.Domain),
+	})
+	writeJSON(w, http.StatusCreated, l.details)
+}
+
+func (s *Server) handleCreateBitlink(w http.ResponseWriter, body []byte) {
+	var req bitlinks.Bitlink
+	if err := json.Unmarshal(body, &req); err != nil || len(req.LongURL) == 0 {
+		writeInvalidArgument(w, "bitlinks", "long_url")
+		return
+	}
+
+	l := s.addBitlink(req.GroupGUID, bitlinks.BitlinkDetails{
+		LongURL:   req.LongURL,
+		Title:     req.Title,
+		Tags:      req.Tags,
+		Deeplinks: req.Deeplinks,
+		ID:        s.newID(req.Domain),
+	})
+	writeJSON(w, http.StatusOK, l.details)
+}
+
+func (s *Server) handleAddCustomBitlink(w http.ResponseWriter, body []byte) {
+	var req custombitlinks.AddRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		writeInvalidArgument(w, "custom_bitlinks", "body")
+		return
+	}
+
+	domain, _, err := custombitlinks.Split(req.CustomBitlink)
+	if err != nil || !s.isDomain(domain) {
+		writeInvalidArgument(w, "custom_bitlinks", "custom_bitlink")
+		return
+	}
+
+	l, ok := s.links[req.BitlinkID]
+	if !ok {
+		writeNotFound(w, "bitlinks")
+		return
+	}
+
+	_, taken := s.links[req.CustomBitlink]
+	if _, ok := s.customs[req.CustomBitlink]; ok || taken {
+		writeError(w, http.StatusConflict, "ALREADY_A_BITLY_LINK", "custom_bitlinks", "The custom Bitlink is already in use")
+		return
+	}
+
+	s.customs[req.CustomBitlink] = &custom{history: []target{{bitlink: req.BitlinkID}}}
+	l.details.CustomBitlinks = append(l.details.CustomBitlinks, req.CustomBitlink)
+	writeJSON(w, http.StatusOK, s.customBitlink(req.CustomBitlink))
+}
+
+func (s *Server) handleCustomBitlink(w http.ResponseWriter, r *http.Request, customBitlink string, body []byte) {
+	c, ok := s.customs[customBitlink]
+	if !ok {
+		writeNotFound(w, "custom_bitlinks")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.customBitlink(customBitlink))
+	case http.MethodPatch:
+		var req struct {
+			BitlinkID string `json:"bitlink_id"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil || len(req.BitlinkID) == 0 {
+			writeInvalidArgument(w, "custom_bitlinks", "bitlink_id")
+			return
+		}
+
+		l, ok := s.links[req.BitlinkID]
+		if !ok {
+			writeNotFound(w, "bitlinks")
+			return
+		}
+
+		current := &c.history[len(c.history)-1]
+		if current.bitlink != req.BitlinkID {
+			if old, ok := s.links[current.bitlink]; ok {
+				old.details.CustomBitlinks = removeString(old.details.CustomBitlinks, customBitlink)
+			}
+			current.deactivatedAt = nowPtr()
+			c.history = append(c.history, target{bitlink: req.BitlinkID})
+			l.details.CustomBitlinks = append(l.details.CustomBitlinks, customBitlink)
+		}
+		writeJSON(w, http.StatusOK, s.customBitlink(customBitlink))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "custom_bitlinks", "The method isn't allowed")
+	}
+}
+
+// customBitlink returns the custom Bitlink as Bitly sends it, with the history in order.
+func (s *Server) customBitlink(customBitlink string) custombitlinks.CustomBitlink {
+	c := s.customs[customBitlink]
+	res := custombitlinks.CustomBitlink{CustomBitlink: customBitlink, BitlinkHistory: []custombitlinks.HistoryEntry{}}
+
+	for idx, t := range c.history {
+		entry := custombitlinks.HistoryEntry{
+			IsActive:      idx == len(c.history)-1,
+			DeactivatedAt: t.deactivatedAt,
+		}
+		if l, ok := s.links[t.bitlink]; ok {
+			entry.BitlinkDetails = l.details
+		} else {
+			entry.ID = t.bitlink
+		}
+
+		res.BitlinkHistory = append(res.BitlinkHistory, entry)
+		if entry.IsActive {
+			res.Bitlink = entry.BitlinkDetails
+		}
+	}
+
+	return res
+}
+
+// isDomain returns true if Bitlinks can be created on the domain.
+func (s *Server) isDomain(domain string) bool {
+	if domain == DefaultDomain {
+		return true
+	}
+	for _, bsd := range s.bsds {
+		if bsd == domain {
+			return true
+		}
+	}
+	return false
+}
+
+func removeString(values []string, value string) []string {
+	result := []string{}
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func (s *Server) handleExpand(w http.ResponseWriter, body []byte) {
+	var req bitlinks.Link
+	if err := json.Unmarshal(body, &req); err != nil || len(req.BitlinkID) == 0 {
+		writeInvalidArgument(w, "bitlinks", "bitlink_id")
+		return
+	}
+
+	l, ok := s.links[req.BitlinkID]
+	if !ok {
+		writeNotFound(w, "bitlinks")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, bitlinks.LinkInfo{
+		LongURL:   l.details.LongURL,
+		CreatedAt: timeValue(l.details.CreatedAt),
+		Link:      l.details.Link,
+		ID:        l.details.ID,
+	})
+}
+
+// handleBitlink handles the endpoints of a single Bitlink. Bitlink IDs contain a slash, so the metrics
+// endpoints are recognized by their suffix.
+func (s *Server) handleBitlink(w http.ResponseWriter, r *http.Request, rest string, body []byte) {
+	for _, suffix := range bitlinkMetricsSuffixes {
+		if strings.HasSuffix(rest, suffix) && r.Method == http.MethodGet {
+			s.handleBitlinkMetrics(w, r, strings.TrimSuffix(rest, suffix), suffix)
+			return
+		}
+	}
+
+	l, ok := s.links[rest]
+	if !ok {
+		writeNotFound(w, "bitlinks")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, l.details)
+	case http.MethodPatch:
+		if err := json.Unmarshal(body, &l.details); err != nil {
+			writeInvalidArgument(w, "bitlinks", "body")
+			return
+		}
+		// Fields that can't be changed through Bitly's API
+		l.details.ID = rest
+		l.details.Deeplinks = s.deeplinks(rest, l.details.Deeplinks)
+		writeJSON(w, http.StatusOK, l.details)
+	case http.MethodDelete:
+		delete(s.links, rest)
+		for idx, id := range s.order {
+			if id == rest {
+				s.order = append(s.order[:idx], s.order[idx+1:]...)
+				break
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"links_deleted": []map[string]string{{"id": rest}}})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "bitlinks", "The method isn't allowed")
+	}
+}
+
+func (s *Server) handleBitlinkMetrics(w http.ResponseWriter, r *http.Request, bitlink string, suffix string) {
+	l, ok := s.links[bitlink]
+	if !ok {
+		writeNotFound(w, "bitlinks")
+		return
+	}
+
+	q := r.URL.Query()
+	metrics := bitlinks.Metrics{
+		Unit:          q.Get("unit"),
+		Units:         queryInt64(r, "units"),
+		UnitReference: queryTime(r, "unit_reference"),
+	}
+
+	switch suffix {
+	case "/clicks":
+		metrics.LinkClicks = l.clicks
+	case "/clicks/summary":
+		for _, c := range l.clicks {
+			metrics.TotalClicks += c.Clicks
+		}
+	default:
+		metrics.Facet = strings.TrimPrefix(suffix, "/")
+		metrics.Metrics = []bitlinks.Metric{}
+	}
+
+	writeJSON(w, http.StatusOK, metrics)
+}
+
+func (s *Server) handleGroups(w http.ResponseWriter, r *http.Request) {
+	organizationGUID := r.URL.Query().Get("organization_guid")
+
+	res := groups.BitlyGroups{Groups: []groups.Group{}}
+	for _, g := range s.groups {
+		if len(organizationGUID) > 0 && g.OrganizationGUID != organizationGUID {
+			continue
+		}
+		res.Groups = append(res.Groups, g)
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleGroup(w http.ResponseWriter, r *http.Request, groupGUID string, rest []string, body []byte) {
+	group, ok := s.groups[groupGUID]
+	if !ok {
+		writeNotFound(w, "groups")
+		return
+	}
+
+	switch {
+	case len(rest) == 0 && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, group)
+	case len(rest) == 0 && r.Method == http.MethodPatch:
+		if err := json.Unmarshal(body, &group); err != nil {
+			writeInvalidArgument(w, "groups", "body")
+			return
+		}
+		group.GUID = groupGUID
+		group.Modified = nowPtr()
+		s.groups[groupGUID] = group
+		writeJSON(w, http.StatusOK, group)
+	case len(rest) == 1 && rest[0] == "preferences" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.preferences[groupGUID])
+	case len(rest) == 1 && rest[0] == "preferences" && r.Method == http.MethodPatch:
+		prefs := s.preferences[groupGUID]
+		if err := json.Unmarshal(body, &prefs); err != nil {
+			writeInvalidArgument(w, "groups", "body")
+			return
+		}
+		prefs.GroupGUID = groupGUID
+		s.preferences[groupGUID] = prefs
+		writeJSON(w, http.StatusOK, prefs)
+	case len(rest) == 1 && rest[0] == "bitlinks" && r.Method == http.MethodGet:
+		s.handleBitlinksByGroup(w, r, groupGUID)
+	case len(rest) == 2 && rest[0] == "bitlinks" && r.Method == http.MethodGet:
+		s.handleSortedBitlinks(w, r, groupGUID)
+	case len(rest) == 1 && rest[0] == "tags" && r.Method == http.MethodGet:
+		s.handleTags(w, groupGUID)
+	case len(rest) == 1 && isGroupMetrics(rest[0]) && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, groups.Metrics{
+			Unit:          r.URL.Query().Get("unit"),
+			Units:         queryInt64(r, "units"),
+			UnitReference: queryTime(r, "unit_reference"),
+			Facet:         rest[0],
+			Metrics:       []groups.Metric{},
+		})
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "groups", "The endpoint doesn't exist")
+	}
+}
+
+// handleBitlinksByGroup returns a page of the Bitlinks of the group, filtered by the tags, archived state
+// and query parameters.
+func (s *Server) handleBitlinksByGroup(w http.ResponseWriter, r *http.Request, groupGUID string) {
+	q := r.URL.Query()
+
+	size, err := strconv.Atoi(q.Get("size"))
+	if err != nil || size <= 0 {
+		size = defaultPageSize
+	}
+
+	page, err := strconv.Atoi(q.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	var matches []groups.Link
+	for _, id := range s.order {
+		l := s.links[id]
+		if l.groupGUID != groupGUID || !matchesFilters(l.details, q.Get("archived"), q.Get("query"), q["tags"]) ||
+			!s.inChannel(id, q.Get("campaign_guid"), q.Get("channel_guid")) {
+			continue
+		}
+		matches = append(matches, toGroupLink(groupGUID, l.details))
+	}
+
+	start := (page - 1) * size
+	if start > len(matches) {
+		start = len(matches)
+	}
+	end := start + size
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	res := groups.Bitlinks{
+		Links: append([]groups.Link{}, matches[start:end]...),
+		Pagination: groups.Pagination{
+			Size:  int64(size),
+			Page:  int64(page),
+			Total: int64(len(matches)),
+		},
+	}
+
+	if end < len(matches) {
+		next := q
+		next.Set("page", strconv.Itoa(page+1))
+		res.Pagination.Next = fmt.Sprintf("%sgroups/%s/bitlinks?%s", s.URL, groupGUID, next.Encode())
+	}
+
+	if page > 1 {
+		prev := r.URL.Query()
+		prev.Set("page", strconv.Itoa(page-1))
+		res.Pagination.Prev = fmt.Sprintf("%sgroups/%s/bitlinks?%s", s.URL, groupGUID, prev.Encode())
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleSortedBitlinks(w http.ResponseWriter, r *http.Request, groupGUID string) {
+	res := groups.Bitlinks{
+		Links:       []groups.Link{},
+		SortedLinks: []groups.SortedLink{},
+	}
+
+	for _, id := range s.order {
+		l := s.links[id]
+		if l.groupGUID != groupGUID {
+			continue
+		}
+
+		var clicks int64
+		for _, c := range l.clicks {
+			clicks += c.Clicks
+		}
+
+		res.Links = append(res.Links, toGroupLink(groupGUID, l.details))
+		res.SortedLinks = append(res.SortedLinks, groups.SortedLink{ID: id, Clicks: clicks})
+	}
+
+	// Sort by clicks, descending, keeping the Links in the same order as the SortedLinks
+	for i := 1; i < len(res.SortedLinks); i++ {
+		for j := i; j > 0 && res.SortedLinks[j].Clicks > res.SortedLinks[j-1].Clicks; j-- {
+			res.SortedLinks[j], res.SortedLinks[j-1] = res.SortedLinks[j-1], res.SortedLinks[j]
+			res.Links[j], res.Links[j-1] = res.Links[j-1], res.Links[j]
+		}
+	}
+
+	if size, err := strconv.Atoi(r.URL.Query().Get("size")); err == nil && size > 0 && size < len(res.Links) {
+		res.Links = res.Links[:size]
+		res.SortedLinks = res.SortedLinks[:size]
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleTags(w http.ResponseWriter, groupGUID string) {
+	seen := make(map[string]bool)
+	res := groups.Tags{Tags: []string{}}
+
+	for _, id := range s.order {
+		l := s.links[id]
+		if l.groupGUID != groupGUID {
+			continue
+		}
+		for _, t := range l.details.Tags {
+			if !seen[t] {
+				seen[t] = true
+				res.Tags = append(res.Tags, t)
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleOrganizations(w http.ResponseWriter) {
+	res := organizations.BitlyOrganizations{Organizations: []organizations.OrganizationDetails{}}
+	for _, o := range s.organizations {
+		res.Organizations = append(res.Organizations, o)
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) handleOrganization(w http.ResponseWriter, r *http.Request, organizationGUID string, rest []string) {
+	organization, ok := s.organizations[organizationGUID]
+	if !ok || r.Method != http.MethodGet {
+		writeNotFound(w, "organizations")
+		return
+	}
+
+	switch {
+	case len(rest) == 0:
+		writeJSON(w, http.StatusOK, organization)
+	case len(rest) == 1 && rest[0] == "shorten_counts":
+		writeJSON(w, http.StatusOK, organizations.Metrics{
+			Unit:          r.URL.Query().Get("unit"),
+			Units:         queryInt64(r, "units"),
+			UnitReference: queryTime(r, "unit_reference"),
+			Facet:         "shorten_counts",
+			Metrics:       []organizations.Metric{},
+		})
+	default:
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "organizations", "The endpoint doesn't exist")
+	}
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, r *http.Request, body []byte) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.user)
+	case http.MethodPatch:
+		if err := json.Unmarshal(body, &s.user); err != nil {
+			writeInvalidArgument(w, "user", "body")
+			return
+		}
+		s.user.Modified = nowPtr()
+		writeJSON(w, http.StatusOK, s.user)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "user", "The method isn't allowed")
+	}
+}
+
+// addBitlink stores the Bitlink, filling in the fields Bitly generates. The caller must hold the lock.
+func (s *Server) addBitlink(groupGUID string, details bitlinks.BitlinkDetails) *link {
+	if len(groupGUID) == 0 {
+		groupGUID = s.user.DefaultGroupGUID
+	}
+
+	if len(details.ID) == 0 {
+		details.ID = s.newID("")
+	}
+
+	if len(details.Link) == 0 {
+		details.Link = fmt.Sprintf("https://%s", details.ID)
+	}
+
+	if details.CreatedAt == nil {
+		details.CreatedAt = nowPtr()
+	}
+
+	if len(details.CreatedBy) == 0 {
+		details.CreatedBy = s.user.Login
+	}
+
+	if details.Tags == nil {
+		details.Tags = []string{}
+	}
+
+	details.Deeplinks = s.deeplinks(details.ID, details.Deeplinks)
+
+	if details.CustomBitlinks == nil {
+		details.CustomBitlinks = []string{}
+	}
+
+	if _, ok := s.links[details.ID]; !ok {
+		s.order = append(s.order, details.ID)
+	}
+
+	l := &link{details: details, groupGUID: groupGUID}
+	s.links[details.ID] = l
+	return l
+}
+
+// findByLongURL returns the Bitlink for the long URL, like Bitly does when a long URL is shortened twice.
+// The caller must hold the lock.
+func (s *Server) findByLongURL(groupGUID string, domain string, longURL string) *link {
+	if len(groupGUID) == 0 {
+		groupGUID = s.user.DefaultGroupGUID
+	}
+	if len(domain) == 0 {
+		domain = DefaultDomain
+	}
+
+	for _, id := range s.order {
+		l := s.links[id]
+		if l.groupGUID == groupGUID && l.details.LongURL == longURL && strings.HasPrefix(id, fmt.Sprintf("%s/", domain)) {
+			return l
+		}
+	}
+	return nil
+}
+
+// deeplinks fills in the fields of the deeplinks that Bitly sets, like the GUID of new deeplinks. The caller
+// must hold the lock.
+func (s *Server) deeplinks(bitlink string, deeplinks []bitlinks.Deeplink) []bitlinks.Deeplink {
+	result := []bitlinks.Deeplink{}
+	for _, d := range deeplinks {
+		if len(d.GUID) == 0 {
+			s.sequence++
+			d.GUID = fmt.Sprintf("Dp%08d", s.sequence)
+		}
+		if d.Created == nil {
+			d.Created = nowPtr()
+		}
+		d.Modified = nowPtr()
+		d.Bitlink = bitlink
+		result = append(result, d)
+	}
+	return result
+}
+
+// newID generates the ID of a new Bitlink. The caller must hold the lock.
+func (s *Server) newID(domain string) string {
+	if len(domain) == 0 {
+		domain = DefaultDomain
+	}
+
+	for {
+		s.sequence++
+		id := fmt.Sprintf("%s/%s", domain, encodeHash(s.sequence))
+		if _, ok := s.links[id]; !ok {
+			return id
+		}
+	}
+}
+
+// encodeHash turns the sequence number into a Bitly style hash of at least seven characters.
+func encodeHash(n int) string {
+	b := make([]byte, 0, 7)
+	for n > 0 {
+		b = append(b, alphabet[n%len(alphabet)])
+		n /= len(alphabet)
+	}
+	for len(b) < 7 {
+		b = append(b, alphabet[0])
+	}
+	return string(b)
+}
+
+func matchesFilters(details bitlinks.BitlinkDetails, archived string, query string, tags []string) bool {
+	switch archived {
+	case "", "both":
+	case "on":
+		if !details.Archived {
+			return false
+		}
+	default:
+		if details.Archived {
+			return false
+		}
+	}
+
+	if len(query) > 0 && !strings.Contains(details.LongURL, query) && !strings.Contains(details.Title, query) && !strings.Contains(details.ID, query) {
+		return false
+	}
+
+	for _, t := range tags {
+		found := false
+		for _, dt := range details.Tags {
+			if dt == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func toGroupLink(groupGUID string, details bitlinks.BitlinkDetails) groups.Link {
+	return groups.Link{
+		CreatedAt:      timeValue(details.CreatedAt),
+		ID:             details.ID,
+		Link:           details.Link,
+		CustomBitlinks: details.CustomBitlinks,
+		LongURL:        details.LongURL,
+		Title:          details.Title,
+		Archived:       details.Archived,
+		CreatedBy:      details.CreatedBy,
+		ClientID:       details.ClientID,
+		Tags:           details.Tags,
+		Deeplinks:      details.Deeplinks,
+		References:     groups.References{Group: groupGUID},
+	}
+}
+
+func queryInt64(r *http.Request, key string) int64 {
+	v, _ := strconv.ParseInt(r.URL.Query().Get(key), 10, 64)
+	return v
+}
+
+func queryTime(r *http.Request, key string) bitly.Time {
+	t, _ := bitly.ParseTime(r.URL.Query().Get(key))
+	return t
+}
+
+func isGroupMetrics(segment string) bool {
+	for _, m := range groupMetricsSuffixes {
+		if segment == m {
+			return true
+		}
+	}
+	return false
+}
+
+func writeNotFound(w http.ResponseWriter, resource string) {
+	writeError(w, http.StatusNotFound, "NOT_FOUND", resource, "What you are looking for cannot be found.")
+}
+
+func writeInvalidArgument(w http.ResponseWriter, resource string, field string) {
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"message":     "INVALID_ARG_" + strings.ToUpper(field),
+		"resource":    resource,
+		"description": "The value provided is invalid.",
+		"errors": []map[string]string{
+			{"field": field, "error_code": "invalid"},
+		},
+	})
+}
+
+// handleOAuthToken exchanges a code that was registered with AddAuthorizationCode for its access token.
+func (s *Server) handleOAuthToken(w http.ResponseWriter, r *http.Request, body []byte) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "oauth", "The method isn't allowed")
+		return
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		writeInvalidArgument(w, "oauth", "body")
+		return
+	}
+
+	token, ok := s.codes[form.Get("code")]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "INVALID_CODE", "oauth", "The code is invalid or was already used")
+		return
+	}
+
+	delete(s.codes, form.Get("code"))
+	s.tokens[token] = true
+	writeJSON(w, http.StatusOK, map[string]string{"access_token": token, "login": s.user.Login})
+}
@@ -0,0 +1,437 @@
+// Package bitlytest provides an in-process fake of the Bitly v4 API, so code that uses this module can be
+// tested without network access or an access token.
+package bitlytest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/retgits/bitly"
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/campaigns"
+	"github.com/retgits/bitly/client/groups"
+	"github.com/retgits/bitly/client/organizations"
+	"github.com/retgits/bitly/client/users"
+)
+
+const (
+	// DefaultAccessToken is the access token the Server accepts unless another one is set
+	DefaultAccessToken = "bitlytest-token"
+	// DefaultDomain is the domain of Bitlinks that are created without a domain
+	DefaultDomain = "bit.ly"
+	// DefaultGroupGUID is the GUID of the group that exists when the Server starts
+	DefaultGroupGUID = "Bg00000000"
+	// DefaultOrganizationGUID is the GUID of the organization that exists when the Server starts
+	DefaultOrganizationGUID = "Og00000000"
+
+	apiPrefix = "/v4/"
+	// oauthTokenPath is the path of the OAuth token endpoint
+	oauthTokenPath = "/oauth/access_token"
+)
+
+// Server is a fake Bitly API with in-memory state. The zero value is not usable, create a Server with
+// NewServer.
+type Server struct {
+	// URL is the base URL of the fake Bitly API, including the /v4/ prefix
+	URL string
+	// AccessToken is the token that must be sent as bearer token. When empty, all tokens are accepted.
+	AccessToken string
+	// TokenURL is the URL of the fake OAuth token endpoint, to use as oauth.Config.TokenURL
+	TokenURL string
+
+	server *httptest.Server
+
+	mu            sync.Mutex
+	user          users.User
+	organizations map[string]organizations.OrganizationDetails
+	groups        map[string]groups.Group
+	preferences   map[string]groups.BitlyGroupPreferences
+	bsds          []string
+	links         map[string]*link
+	order         []string
+	sequence      int
+	faults        []*Fault
+	requests      []Request
+	codes         map[string]string
+	tokens        map[string]bool
+	customs       map[string]*custom
+	campaigns     map[string]campaigns.Campaign
+	channels      map[string]campaigns.Channel
+}
+
+// link is a Bitlink with the data the fake keeps alongside it.
+type link struct {
+	details   bitlinks.BitlinkDetails
+	groupGUID string
+	clicks    []bitlinks.LinkClick
+}
+
+// custom is a custom Bitlink with the Bitlinks it redirected to, the current one last.
+type custom struct {
+	history []target
+}
+
+// target is a Bitlink a custom Bitlink redirected to.
+type target struct {
+	bitlink       string
+	deactivatedAt *bitly.Time
+}
+
+// Request is a request received by the Server.
+type Request struct {
+	Method string
+	// The path without the /v4/ prefix
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// Fault makes the Server answer matching requests with an error.
+type Fault struct {
+	// The HTTP method to match. Matches all methods when empty.
+	Method string
+	// The prefix of the path, without the /v4/ prefix, to match. Matches all paths when empty.
+	PathPrefix string
+	// The status code to respond with. When zero, the request is handled normally after the Delay.
+	StatusCode int
+	// The body to respond with. When empty, a Bitly style error body is generated.
+	Body string
+	// The headers to respond with, like Retry-After
+	Header http.Header
+	// The number of requests to fail. When zero, all matching requests fail until the faults are cleared.
+	Times int
+	// The time to wait before responding, to simulate a slow server
+	Delay time.Duration
+}
+
+// NewServer starts a new fake Bitly API with a user, an organization and a group. The caller should call
+// Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{
+		AccessToken:   DefaultAccessToken,
+		organizations: make(map[string]organizations.OrganizationDetails),
+		groups:        make(map[string]groups.Group),
+		preferences:   make(map[string]groups.BitlyGroupPreferences),
+		links:         make(map[string]*link),
+		bsds:          []string{},
+		codes:         make(map[string]string),
+		tokens:        make(map[string]bool),
+		customs:       make(map[string]*custom),
+		campaigns:     make(map[string]campaigns.Campaign),
+		channels:      make(map[string]campaigns.Channel),
+	}
+
+	created := now()
+
+	s.user = users.User{
+		Created:          &created,
+		Modified:         &created,
+		Login:            "bitlytest",
+		IsActive:         true,
+		Name:             "Bitly Test",
+		Emails:           []users.Email{{Email: "bitlytest@example.org", IsPrimary: true, IsVerified: true}},
+		DefaultGroupGUID: DefaultGroupGUID,
+	}
+
+	s.organizations[DefaultOrganizationGUID] = organizations.OrganizationDetails{
+		Created:  created,
+		Modified: created,
+		Bsds:     []interface{}{},
+		GUID:     DefaultOrganizationGUID,
+		Name:     "bitlytest",
+		IsActive: true,
+		Tier:     "free",
+		Role:     "org-admin",
+	}
+
+	s.groups[DefaultGroupGUID] = groups.Group{
+		Created:          &created,
+		Modified:         &created,
+		Bsds:             []interface{}{},
+		GUID:             DefaultGroupGUID,
+		OrganizationGUID: DefaultOrganizationGUID,
+		Name:             "bitlytest",
+		IsActive:         true,
+		Role:             "org-admin",
+		References:       groups.References{Organization: DefaultOrganizationGUID},
+	}
+
+	s.preferences[DefaultGroupGUID] = groups.BitlyGroupPreferences{
+		GroupGUID:        DefaultGroupGUID,
+		DomainPreference: DefaultDomain,
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = fmt.Sprintf("%s%s", s.server.URL, apiPrefix)
+	s.TokenURL = fmt.Sprintf("%s%s", s.server.URL, oauthTokenPath)
+	return s
+}
+
+// Close shuts down the Server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Client returns a new client.Client that sends its requests to the Server.
+func (s *Server) Client() *client.Client {
+	return client.NewClient().WithAccessToken(s.AccessToken).WithBaseURL(s.URL)
+}
+
+// AddBitlink stores a Bitlink in the group. When the ID of the Bitlink is empty, a new one is generated.
+// It returns the stored Bitlink.
+func (s *Server) AddBitlink(groupGUID string, details bitlinks.BitlinkDetails) bitlinks.BitlinkDetails {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addBitlink(groupGUID, details).details
+}
+
+// SetClicks sets the clicks of a Bitlink, which are returned by the click metrics endpoints.
+func (s *Server) SetClicks(bitlink string, clicks []bitlinks.LinkClick) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[bitlink]
+	if !ok {
+		return fmt.Errorf("bitlink %s doesn't exist", bitlink)
+	}
+
+	l.clicks = append([]bitlinks.LinkClick(nil), clicks...)
+	return nil
+}
+
+// Bitlink returns the stored Bitlink.
+func (s *Server) Bitlink(bitlink string) (bitlinks.BitlinkDetails, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	l, ok := s.links[bitlink]
+	if !ok {
+		return bitlinks.BitlinkDetails{}, false
+	}
+	return l.details, true
+}
+
+// AddGroup stores a group.
+func (s *Server) AddGroup(group groups.Group) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.groups[group.GUID] = group
+	if _, ok := s.preferences[group.GUID]; !ok {
+		s.preferences[group.GUID] = groups.BitlyGroupPreferences{GroupGUID: group.GUID, DomainPreference: DefaultDomain}
+	}
+}
+
+// AddOrganization stores an organization.
+func (s *Server) AddOrganization(organization organizations.OrganizationDetails) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.organizations[organization.GUID] = organization
+}
+
+// SetUser replaces the authenticated user.
+func (s *Server) SetUser(user users.User) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.user = user
+}
+
+// AddBSD adds a branded short domain.
+func (s *Server) AddBSD(bsd string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bsds = append(s.bsds, bsd)
+}
+
+// InjectFault adds a fault. Faults are matched in the order in which they were added.
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f := fault
+	s.faults = append(s.faults, &f)
+}
+
+// ClearFaults removes all faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// Requests returns all requests received by the Server, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// AddAuthorizationCode registers a code which the OAuth token endpoint exchanges for the access token, once.
+// The access token is accepted by the Server from then on.
+func (s *Server) AddAuthorizationCode(code string, accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.codes[code] = accessToken
+}
+
+// authorized returns true if the request has a valid access token. The caller must hold the lock.
+func (s *Server) authorized(r *http.Request) bool {
+	if len(s.AccessToken) == 0 {
+		return true
+	}
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token == s.AccessToken || s.tokens[token]
+}
+
+// ResetRequests removes all recorded requests.
+func (s *Server) ResetRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = nil
+}
+
+// serveHTTP records the request, applies faults and dispatches the request to the handler of the endpoint.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method: r.Method,
+		Path:   path,
+		Query:  r.URL.Query(),
+		Header: cloneHeader(r.Header),
+		Body:   body,
+	})
+	fault := s.matchFault(r.Method, path)
+	s.mu.Unlock()
+
+	if fault != nil {
+		if fault.Delay > 0 {
+			select {
+			case <-time.After(fault.Delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if fault.StatusCode != 0 {
+			writeFault(w, fault)
+			return
+		}
+	}
+
+	if !strings.HasPrefix(r.URL.Path, apiPrefix) && r.URL.Path != oauthTokenPath {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "", "The endpoint doesn't exist")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == oauthTokenPath {
+		s.handleOAuthToken(w, r, body)
+		return
+	}
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusForbidden, "FORBIDDEN", "", "The access token is invalid")
+		return
+	}
+
+	s.route(w, r, path, body)
+}
+
+// matchFault returns the first fault that matches the request. The caller must hold the lock.
+func (s *Server) matchFault(method string, path string) *Fault {
+	for idx, f := range s.faults {
+		if len(f.Method) > 0 && f.Method != method {
+			continue
+		}
+		if !strings.HasPrefix(path, f.PathPrefix) {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:idx], s.faults[idx+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func writeFault(w http.ResponseWriter, f *Fault) {
+	for k, v := range f.Header {
+		w.Header()[k] = v
+	}
+
+	if len(f.Body) > 0 {
+		w.WriteHeader(f.StatusCode)
+		fmt.Fprint(w, f.Body)
+		return
+	}
+
+	message := strings.ToUpper(strings.Replace(http.StatusText(f.StatusCode), " ", "_", -1))
+	writeError(w, f.StatusCode, message, "", "Injected by bitlytest")
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string, resource string, description string) {
+	writeJSON(w, statusCode, client.APIError{
+		Message:     message,
+		Resource:    resource,
+		Description: description,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// now returns the current time with the precision Bitly uses in its timestamps.
+func now() bitly.Time {
+	return bitly.NewTime(time.Now().UTC().Truncate(time.Second))
+}
+
+// nowPtr returns the current time for the fields that are a *bitly.Time.
+func nowPtr() *bitly.Time {
+	t := now()
+	return &t
+}
+
+// timeValue returns the time for the fields of responses that are a bitly.Time, or the zero Time when t is nil.
+func timeValue(t *bitly.Time) bitly.Time {
+	if t == nil {
+		return bitly.Time{}
+	}
+	return *t
+}
+
+func cloneHeader(h http.Header) http.Header {
+	c := make(http.Header, len(h))
+	for k, v := range h {
+		c[k] = append([]string(nil), v...)
+	}
+	return c
+}
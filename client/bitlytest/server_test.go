@@ -0,0 +1,127 @@
+package bitlytest_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/bitlytest"
+)
+
+func TestFaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault bitlytest.Fault
+		// The status codes of three GET requests for the user
+		want []int
+	}{
+		{
+			name:  "all requests",
+			fault: bitlytest.Fault{StatusCode: http.StatusInternalServerError},
+			want:  []int{500, 500, 500},
+		},
+		{
+			name:  "times",
+			fault: bitlytest.Fault{StatusCode: http.StatusServiceUnavailable, Times: 2},
+			want:  []int{503, 503, 200},
+		},
+		{
+			name:  "other method",
+			fault: bitlytest.Fault{Method: http.MethodPatch, StatusCode: http.StatusInternalServerError},
+			want:  []int{200, 200, 200},
+		},
+		{
+			name:  "path prefix",
+			fault: bitlytest.Fault{PathPrefix: "us", StatusCode: http.StatusTooManyRequests, Times: 1},
+			want:  []int{429, 200, 200},
+		},
+		{
+			name:  "other path",
+			fault: bitlytest.Fault{PathPrefix: "groups", StatusCode: http.StatusInternalServerError},
+			want:  []int{200, 200, 200},
+		},
+		{
+			name:  "delay only",
+			fault: bitlytest.Fault{Delay: time.Millisecond},
+			want:  []int{200, 200, 200},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			srv.InjectFault(tt.fault)
+			c := srv.Client()
+
+			for idx, want := range tt.want {
+				_, err := c.Call("user", http.MethodGet, nil)
+				got := http.StatusOK
+				if apiErr, ok := err.(*client.APIError); ok {
+					got = apiErr.StatusCode
+				} else if err != nil {
+					t.Fatalf("request %d returned %s", idx, err.Error())
+				}
+				if got != want {
+					t.Errorf("request %d returned %d, want %d", idx, got, want)
+				}
+			}
+
+			// Failed requests are recorded as well
+			if got := len(srv.Requests()); got != len(tt.want) {
+				t.Errorf("recorded %d requests, want %d", got, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestFaultBody(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	srv.InjectFault(bitlytest.Fault{
+		StatusCode: http.StatusTooManyRequests,
+		Body:       `{"message":"RATE_LIMIT_EXCEEDED"}`,
+		Header:     http.Header{"Retry-After": {"3"}},
+	})
+
+	_, err := srv.Client().Call("user", http.MethodGet, nil)
+	apiErr, ok := err.(*client.APIError)
+	if !ok {
+		t.Fatalf("Call() = %v, want an *APIError", err)
+	}
+	if apiErr.Message != "RATE_LIMIT_EXCEEDED" || apiErr.Header.Get("Retry-After") != "3" {
+		t.Errorf("Call() = %s with Retry-After %q", apiErr.Error(), apiErr.Header.Get("Retry-After"))
+	}
+
+	srv.ClearFaults()
+	if _, err := srv.Client().Call("user", http.MethodGet, nil); err != nil {
+		t.Errorf("Call() after ClearFaults() returned %s", err.Error())
+	}
+}
+
+func TestAuthorization(t *testing.T) {
+	tests := []struct {
+		name        string
+		accessToken string
+		token       string
+		forbidden   bool
+	}{
+		{name: "default token", accessToken: bitlytest.DefaultAccessToken, token: bitlytest.DefaultAccessToken},
+		{name: "wrong token", accessToken: bitlytest.DefaultAccessToken, token: "other", forbidden: true},
+		{name: "all tokens accepted", accessToken: "", token: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			srv.AccessToken = tt.accessToken
+
+			_, err := client.NewClient().WithAccessToken(tt.token).WithBaseURL(srv.URL).Call("user", http.MethodGet, nil)
+			if client.IsForbidden(err) != tt.forbidden {
+				t.Errorf("Call() = %v, want forbidden: %t", err, tt.forbidden)
+			}
+		})
+	}
+}
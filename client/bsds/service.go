@@ -2,6 +2,7 @@
 package bsds
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/retgits/bitly/client"
@@ -11,12 +12,21 @@ const (
 	bsdEndpoint = "bsds"
 )
 
+// Service is the interface implemented by BSDs. Code that depends on Service, instead of *BSDs, can use a
+// mock in its unit tests.
+type Service interface {
+	GetBSDs() (BSD, error)
+	GetBSDsContext(ctx context.Context) (BSD, error)
+}
+
 // BSDs is an acronym for branded short domains. This is a custom 15 character or less domain for bitlinks.
 // This allows you to customize the domain to your brand.
 type BSDs struct {
 	*client.Client
 }
 
+var _ Service = (*BSDs)(nil)
+
 // New creates a new instance of the BSDs client.
 func New(c *client.Client) *BSDs {
 	return &BSDs{
@@ -26,7 +36,12 @@ func New(c *client.Client) *BSDs {
 
 // GetBSDs is to Fetch all Branded Short Domains
 func (b *BSDs) GetBSDs() (BSD, error) {
-	data, err := b.Call(bsdEndpoint, http.MethodGet, nil)
+	return b.GetBSDsContext(context.Background())
+}
+
+// GetBSDsContext is the same as GetBSDs, but uses the context to control the lifetime of the request.
+func (b *BSDs) GetBSDsContext(ctx context.Context) (BSD, error) {
+	data, err := b.CallContext(ctx, bsdEndpoint, http.MethodGet, nil)
 	if err != nil {
 		return BSD{}, err
 	}
@@ -0,0 +1,42 @@
+// Package cache contains the storage used by the services to cache responses from Bitly.
+//
+// A Cache stores opaque values with a time to live, so any key/value store can be used as a backend. This
+// package contains an in-memory LRU cache and a cache that is persisted to a single file.
+package cache
+
+import (
+	"time"
+)
+
+// Cache stores values for a limited time. Implementations must be safe for concurrent use. A cache that
+// fails is treated as empty by the services, so Get should report a miss when the value can't be read.
+type Cache interface {
+	// Get returns the value of the key, and false when the key isn't in the cache or has expired
+	Get(key string) ([]byte, bool)
+	// Set stores the value of the key. The value expires after the ttl, or never if the ttl is zero or less
+	Set(key string, value []byte, ttl time.Duration) error
+	// Delete removes the key from the cache
+	Delete(key string) error
+}
+
+// entry is a value in the cache
+type entry struct {
+	Key     string    `json:"key"`
+	Value   []byte    `json:"value"`
+	Expires time.Time `json:"expires"`
+	// Deleted marks the removal of the key in the file of a File cache
+	Deleted bool `json:"deleted,omitempty"`
+}
+
+// expired returns true if the entry has expired at the time.
+func (e *entry) expired(now time.Time) bool {
+	return !e.Expires.IsZero() && !now.Before(e.Expires)
+}
+
+// expiry returns the time at which a value with the ttl expires, or the zero time if it never expires.
+func expiry(now time.Time, ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
+}
@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clock is a time that only moves when it is advanced.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestCaches(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name string
+		new  func(now func() time.Time) Cache
+	}{
+		{
+			name: "lru",
+			new: func(now func() time.Time) Cache {
+				c := NewLRU(10)
+				c.now = now
+				return c
+			},
+		},
+		{
+			name: "file",
+			new: func(now func() time.Time) Cache {
+				c, err := NewFile(filepath.Join(dir, "cache.jsonl"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				c.now = now
+				return c
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := &clock{now: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)}
+			c := tt.new(clk.Now)
+			if closer, ok := c.(io.Closer); ok {
+				defer closer.Close()
+			}
+
+			if _, ok := c.Get("missing"); ok {
+				t.Errorf("Get() of a missing key returned a value")
+			}
+
+			if err := c.Set("short", []byte("1"), time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Set("forever", []byte("2"), 0); err != nil {
+				t.Fatal(err)
+			}
+			if v, ok := c.Get("short"); !ok || string(v) != "1" {
+				t.Errorf("Get(short) = %q, %t", v, ok)
+			}
+
+			clk.Advance(time.Minute)
+			if _, ok := c.Get("short"); ok {
+				t.Errorf("Get() of an expired key returned a value")
+			}
+			if v, ok := c.Get("forever"); !ok || string(v) != "2" {
+				t.Errorf("Get(forever) = %q, %t", v, ok)
+			}
+
+			if err := c.Delete("forever"); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := c.Get("forever"); ok {
+				t.Errorf("Get() of a deleted key returned a value")
+			}
+			if err := c.Delete("missing"); err != nil {
+				t.Errorf("Delete() of a missing key returned %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	c := NewLRU(3)
+	for i := 0; i < 3; i++ {
+		c.Set(fmt.Sprintf("k%d", i), []byte{byte(i)}, 0)
+	}
+
+	// Using k0 makes k1 the least recently used value
+	c.Get("k0")
+	c.Set("k3", []byte{3}, 0)
+
+	if c.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", c.Len())
+	}
+	for key, want := range map[string]bool{"k0": true, "k1": false, "k2": true, "k3": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Get(%s) found the key: %t, want %t", key, ok, want)
+		}
+	}
+}
+
+func TestFilePersistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cache.jsonl")
+
+	c, err := NewFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("kept", []byte("v1"), 0)
+	c.Set("kept", []byte("v2"), 0)
+	c.Set("deleted", []byte("v"), 0)
+	c.Delete("deleted")
+	c.Set("expired", []byte("v"), time.Nanosecond)
+	c.Close()
+
+	// A partially written last line, as left behind by a crash, is skipped
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"key":"partial","val`)
+	f.Close()
+
+	c, err = NewFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	tests := []struct {
+		key   string
+		value string
+		found bool
+	}{
+		{key: "kept", value: "v2", found: true},
+		{key: "deleted", found: false},
+		{key: "expired", found: false},
+		{key: "partial", found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			v, ok := c.Get(tt.key)
+			if ok != tt.found || string(v) != tt.value {
+				t.Errorf("Get(%s) = %q, %t, want %q, %t", tt.key, v, ok, tt.value, tt.found)
+			}
+		})
+	}
+
+	// The file is compacted when it is opened, so only the kept value is left
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Errorf("the compacted file has %d lines, want 1", lines)
+	}
+}
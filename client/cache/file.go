@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// File is a Cache which is kept in memory and persisted to a single file, so it survives restarts. Every
+// change is appended to the file as a JSON object per line, and the file is compacted when it is opened.
+// A File can only be used by one process at a time.
+type File struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	entries map[string]*entry
+	now     func() time.Time
+}
+
+var _ Cache = (*File)(nil)
+
+// NewFile opens, or creates, the cache file and loads the values that haven't expired. The caller should
+// call Close when the cache is no longer used.
+func NewFile(path string) (*File, error) {
+	c := &File{
+		path:    path,
+		entries: make(map[string]*entry),
+		now:     time.Now,
+	}
+
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	if err := c.compact(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// load reads the changes in the file.
+func (c *File) load() error {
+	file, err := os.Open(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	now := c.now()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// A crash can leave a partially written last line behind, which is skipped
+			continue
+		}
+
+		if e.Deleted || e.expired(now) {
+			delete(c.entries, e.Key)
+			continue
+		}
+		c.entries[e.Key] = &e
+	}
+
+	return scanner.Err()
+}
+
+// compact rewrites the file with only the values in the cache, and opens it for appending.
+func (c *File) compact() error {
+	tmp := c.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(file)
+	enc := json.NewEncoder(w)
+	for _, e := range c.entries {
+		if err := enc.Encode(e); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+
+	c.file, err = os.OpenFile(c.path, os.O_WRONLY|os.O_APPEND, 0644)
+	return err
+}
+
+// Get returns the value of the key, and false when the key isn't in the cache or has expired.
+func (c *File) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || e.expired(c.now()) {
+		return nil, false
+	}
+	return e.Value, true
+}
+
+// Set stores the value of the key and appends it to the file.
+func (c *File) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &entry{Key: key, Value: value, Expires: expiry(c.now(), ttl)}
+	if err := c.append(e); err != nil {
+		return err
+	}
+
+	c.entries[key] = e
+	return nil
+}
+
+// Delete removes the key from the cache and the file.
+func (c *File) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok {
+		return nil
+	}
+
+	if err := c.append(&entry{Key: key, Deleted: true}); err != nil {
+		return err
+	}
+
+	delete(c.entries, key)
+	return nil
+}
+
+// append writes the entry to the file. The caller must hold the lock.
+func (c *File) append(e *entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close closes the cache file.
+func (c *File) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.file.Close()
+}
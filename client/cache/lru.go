@@ -0,0 +1,104 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultLRUSize is the number of values an LRU cache holds when no size is set
+	DefaultLRUSize = 1000
+)
+
+// LRU is an in-memory Cache which holds a limited number of values. When it is full, the value that was
+// used least recently is removed.
+type LRU struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	now     func() time.Time
+}
+
+var _ Cache = (*LRU)(nil)
+
+// NewLRU creates an LRU cache which holds up to size values, or DefaultLRUSize values if size is zero or less.
+func NewLRU(size int) *LRU {
+	if size <= 0 {
+		size = DefaultLRUSize
+	}
+
+	return &LRU{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value of the key, and false when the key isn't in the cache or has expired.
+func (c *LRU) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if e.expired(c.now()) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return e.Value, true
+}
+
+// Set stores the value of the key, and removes the least recently used value when the cache is full.
+func (c *LRU) Set(key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &entry{Key: key, Value: value, Expires: expiry(c.now(), ttl)}
+
+	if el, ok := c.entries[key]; ok {
+		el.Value = e
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(e)
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+
+	return nil
+}
+
+// Delete removes the key from the cache.
+func (c *LRU) Delete(key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	return nil
+}
+
+// Len returns the number of values in the cache, including values that have expired but weren't removed yet.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+// remove removes the element from the cache. The caller must hold the lock.
+func (c *LRU) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*entry).Key)
+}
@@ -0,0 +1,104 @@
+// Package campaigns contains the methods to interact with the campaigns and channels in Bitly
+package campaigns
+
+import (
+	"encoding/json"
+
+	"github.com/retgits/bitly"
+)
+
+// Campaign groups the channels through which the Bitlinks of a marketing effort are shared
+type Campaign struct {
+	Created      bitly.Time `json:"created"`
+	Modified     bitly.Time `json:"modified"`
+	GroupGUID    string     `json:"group_guid"`
+	GUID         string     `json:"guid"`
+	ChannelGUIDs []string   `json:"channel_guids"`
+	Description  string     `json:"description"`
+	Name         string     `json:"name"`
+	CreatedBy    string     `json:"created_by"`
+}
+
+// BitlyCampaigns contains a list of campaigns
+type BitlyCampaigns struct {
+	Campaigns []Campaign `json:"campaigns"`
+}
+
+// CampaignRequest contains the fields of a campaign to create or update. Fields that are empty aren't sent
+// to Bitly, so they aren't changed by an update.
+type CampaignRequest struct {
+	GroupGUID    string   `json:"group_guid,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	ChannelGUIDs []string `json:"channel_guids,omitempty"`
+}
+
+// Channel is a medium, like a newsletter or a social network, through which Bitlinks are shared
+type Channel struct {
+	Created   bitly.Time       `json:"created"`
+	Modified  bitly.Time       `json:"modified"`
+	GroupGUID string           `json:"group_guid"`
+	GUID      string           `json:"guid"`
+	Name      string           `json:"name"`
+	Bitlinks  []ChannelBitlink `json:"bitlinks"`
+}
+
+// ChannelBitlink is a Bitlink that is shared through a channel, as part of a campaign
+type ChannelBitlink struct {
+	BitlinkID    string `json:"bitlink_id"`
+	CampaignGUID string `json:"campaign_guid"`
+}
+
+// BitlyChannels contains a list of channels
+type BitlyChannels struct {
+	Channels []Channel `json:"channels"`
+}
+
+// ChannelRequest contains the fields of a channel to create or update. Fields that are empty aren't sent
+// to Bitly, so they aren't changed by an update.
+type ChannelRequest struct {
+	GroupGUID string           `json:"group_guid,omitempty"`
+	Name      string           `json:"name,omitempty"`
+	Bitlinks  []ChannelBitlink `json:"bitlinks,omitempty"`
+}
+
+// channelBitlinksRequest replaces the Bitlinks of a channel, including with an empty list
+type channelBitlinksRequest struct {
+	Bitlinks []ChannelBitlink `json:"bitlinks"`
+}
+
+func (r *CampaignRequest) marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *ChannelRequest) marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *channelBitlinksRequest) marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func unmarshalCampaign(data []byte) (Campaign, error) {
+	var r Campaign
+	err := json.Unmarshal(data, &r)
+	return r, err
+}
+
+func unmarshalCampaigns(data []byte) (BitlyCampaigns, error) {
+	var r BitlyCampaigns
+	err := json.Unmarshal(data, &r)
+	return r, err
+}
+
+func unmarshalChannel(data []byte) (Channel, error) {
+	var r Channel
+	err := json.Unmarshal(data, &r)
+	return r, err
+}
+
+func unmarshalChannels(data []byte) (BitlyChannels, error) {
+	var r BitlyChannels
+	err := json.Unmarshal(data, &r)
+	return r, err
+}
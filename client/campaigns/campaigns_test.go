@@ -0,0 +1,223 @@
+package campaigns_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/campaigns"
+	"github.com/retgits/bitly/client/groups"
+)
+
+func TestCampaigns(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	c := campaigns.New(srv.Client())
+
+	created, err := c.CreateCampaign(&campaigns.CampaignRequest{Name: "Launch", Description: "The launch of 2019"})
+	if err != nil {
+		t.Fatalf("CreateCampaign() returned %s", err.Error())
+	}
+	if created.GroupGUID != bitlytest.DefaultGroupGUID {
+		t.Errorf("the campaign was created in group %s, want the default group", created.GroupGUID)
+	}
+
+	// Fields that aren't set in an update are kept
+	updated, err := c.UpdateCampaign(created.GUID, &campaigns.CampaignRequest{Name: "Launch 2019"})
+	if err != nil {
+		t.Fatalf("UpdateCampaign() returned %s", err.Error())
+	}
+	if updated.Name != "Launch 2019" || updated.Description != "The launch of 2019" {
+		t.Errorf("UpdateCampaign() = %s, %s", updated.Name, updated.Description)
+	}
+
+	retrieved, err := c.RetrieveCampaign(created.GUID)
+	if err != nil {
+		t.Fatalf("RetrieveCampaign() returned %s", err.Error())
+	}
+	if retrieved.Name != updated.Name {
+		t.Errorf("RetrieveCampaign() returned the name %s, want %s", retrieved.Name, updated.Name)
+	}
+
+	tests := []struct {
+		groupGUID string
+		want      int
+	}{
+		{groupGUID: "", want: 1},
+		{groupGUID: bitlytest.DefaultGroupGUID, want: 1},
+		{groupGUID: "Bg99999999", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run("list "+tt.groupGUID, func(t *testing.T) {
+			list, err := c.ListCampaigns(tt.groupGUID)
+			if err != nil {
+				t.Fatalf("ListCampaigns() returned %s", err.Error())
+			}
+			if len(list.Campaigns) != tt.want {
+				t.Errorf("ListCampaigns() returned %d campaigns, want %d", len(list.Campaigns), tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateValidation(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	c := campaigns.New(srv.Client())
+
+	tests := []struct {
+		name    string
+		create  func() error
+		wantErr string
+	}{
+		{
+			name: "campaign",
+			create: func() error {
+				_, err := c.CreateCampaign(&campaigns.CampaignRequest{Description: "No name"})
+				return err
+			},
+			wantErr: "campaigns: the name of the campaign is empty",
+		},
+		{
+			name: "channel",
+			create: func() error {
+				_, err := c.CreateChannel(&campaigns.ChannelRequest{})
+				return err
+			},
+			wantErr: "campaigns: the name of the channel is empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.create()
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("the call returned %v, want %s", err, tt.wantErr)
+			}
+			if got := len(srv.Requests()); got != 0 {
+				t.Errorf("sent %d requests, want 0", got)
+			}
+		})
+	}
+}
+
+func TestChannelBitlinks(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	c := campaigns.New(srv.Client())
+	g := groups.New(srv.Client())
+
+	var ids []string
+	for _, u := range []string{"https://example.org/a", "https://example.org/b", "https://example.org/c"} {
+		ids = append(ids, srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{LongURL: u}).ID)
+	}
+
+	launch, err := c.CreateCampaign(&campaigns.CampaignRequest{Name: "Launch"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sale, err := c.CreateCampaign(&campaigns.CampaignRequest{Name: "Sale"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newsletter, err := c.CreateChannel(&campaigns.ChannelRequest{Name: "Newsletter"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	social, err := c.CreateChannel(&campaigns.ChannelRequest{Name: "Social"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Adding a Bitlink twice for the same campaign keeps one entry
+	if _, err := c.AddBitlinksToChannel(newsletter.GUID, launch.GUID, ids[0], "https://"+ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	channel, err := c.AddBitlinksToChannel(newsletter.GUID, launch.GUID, ids[0], ids[2])
+	if err != nil {
+		t.Fatalf("AddBitlinksToChannel() returned %s", err.Error())
+	}
+	if len(channel.Bitlinks) != 3 {
+		t.Errorf("the channel has %d Bitlinks, want 3", len(channel.Bitlinks))
+	}
+	if _, err := c.AddBitlinksToChannel(social.GUID, sale.GUID, ids[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing keeps the other Bitlinks
+	channel, err = c.RemoveBitlinksFromChannel(newsletter.GUID, ids[2])
+	if err != nil {
+		t.Fatalf("RemoveBitlinksFromChannel() returned %s", err.Error())
+	}
+	if len(channel.Bitlinks) != 2 {
+		t.Errorf("the channel has %d Bitlinks, want 2", len(channel.Bitlinks))
+	}
+
+	tests := []struct {
+		name     string
+		request  *groups.BitlinksGroupRequest
+		channels string
+		bitlinks []string
+	}{
+		{
+			name:     "campaign",
+			request:  &groups.BitlinksGroupRequest{CampaignGUID: launch.GUID},
+			channels: newsletter.GUID,
+			bitlinks: ids[:2],
+		},
+		{
+			name:     "other campaign",
+			request:  &groups.BitlinksGroupRequest{CampaignGUID: sale.GUID},
+			channels: social.GUID,
+			bitlinks: ids[:1],
+		},
+		{
+			name:     "channel",
+			request:  &groups.BitlinksGroupRequest{ChannelGUID: social.GUID},
+			bitlinks: ids[:1],
+		},
+		{
+			name:     "campaign and channel",
+			request:  &groups.BitlinksGroupRequest{CampaignGUID: sale.GUID, ChannelGUID: newsletter.GUID},
+			bitlinks: nil,
+		},
+		{
+			name:     "no filter",
+			request:  &groups.BitlinksGroupRequest{},
+			bitlinks: ids,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := g.RetrieveBitlinksByGroup(bitlytest.DefaultGroupGUID, tt.request)
+			if err != nil {
+				t.Fatalf("RetrieveBitlinksByGroup() returned %s", err.Error())
+			}
+
+			var got []string
+			for _, l := range res.Links {
+				got = append(got, l.ID)
+			}
+			sort.Strings(got)
+			want := append([]string{}, tt.bitlinks...)
+			sort.Strings(want)
+			if strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("RetrieveBitlinksByGroup() = %v, want %v", got, want)
+			}
+
+			if len(tt.channels) == 0 {
+				return
+			}
+			list, err := c.ListChannels("", tt.request.CampaignGUID)
+			if err != nil {
+				t.Fatalf("ListChannels() returned %s", err.Error())
+			}
+			if len(list.Channels) != 1 || list.Channels[0].GUID != tt.channels {
+				t.Errorf("ListChannels() = %+v, want only %s", list.Channels, tt.channels)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+// Package campaigns contains the methods to interact with the campaigns and channels in Bitly
+package campaigns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/retgits/bitly/client"
+)
+
+const (
+	campaignsEndpoint = "campaigns"
+	campaignEndpoint  = "campaigns/%s"
+	channelsEndpoint  = "channels"
+	channelEndpoint   = "channels/%s"
+)
+
+// Service is the interface implemented by Campaigns. Code that depends on Service, instead of *Campaigns, can use a
+// mock in its unit tests.
+type Service interface {
+	ListCampaigns(groupGUID string) (BitlyCampaigns, error)
+	ListCampaignsContext(ctx context.Context, groupGUID string) (BitlyCampaigns, error)
+
+	CreateCampaign(campaign *CampaignRequest) (Campaign, error)
+	CreateCampaignContext(ctx context.Context, campaign *CampaignRequest) (Campaign, error)
+
+	RetrieveCampaign(campaignGUID string) (Campaign, error)
+	RetrieveCampaignContext(ctx context.Context, campaignGUID string) (Campaign, error)
+
+	UpdateCampaign(campaignGUID string, campaign *CampaignRequest) (Campaign, error)
+	UpdateCampaignContext(ctx context.Context, campaignGUID string, campaign *CampaignRequest) (Campaign, error)
+
+	ListChannels(groupGUID string, campaignGUID string) (BitlyChannels, error)
+	ListChannelsContext(ctx context.Context, groupGUID string, campaignGUID string) (BitlyChannels, error)
+
+	CreateChannel(channel *ChannelRequest) (Channel, error)
+	CreateChannelContext(ctx context.Context, channel *ChannelRequest) (Channel, error)
+
+	RetrieveChannel(channelGUID string) (Channel, error)
+	RetrieveChannelContext(ctx context.Context, channelGUID string) (Channel, error)
+
+	UpdateChannel(channelGUID string, channel *ChannelRequest) (Channel, error)
+	UpdateChannelContext(ctx context.Context, channelGUID string, channel *ChannelRequest) (Channel, error)
+
+	AddBitlinksToChannel(channelGUID string, campaignGUID string, bitlinkIDs ...string) (Channel, error)
+	AddBitlinksToChannelContext(ctx context.Context, channelGUID string, campaignGUID string, bitlinkIDs ...string) (Channel, error)
+
+	RemoveBitlinksFromChannel(channelGUID string, bitlinkIDs ...string) (Channel, error)
+	RemoveBitlinksFromChannelContext(ctx context.Context, channelGUID string, bitlinkIDs ...string) (Channel, error)
+}
+
+// Campaigns organize Bitlinks by the marketing effort they belong to, and channels by the medium they are
+// shared through. Bitly's API has no endpoints to delete campaigns or channels.
+type Campaigns struct {
+	*client.Client
+}
+
+var _ Service = (*Campaigns)(nil)
+
+// New creates a new instance of the Campaigns client.
+func New(c *client.Client) *Campaigns {
+	return &Campaigns{
+		c,
+	}
+}
+
+// ListCampaigns returns the campaigns of a group, or of all groups when the group GUID is empty.
+func (c *Campaigns) ListCampaigns(groupGUID string) (BitlyCampaigns, error) {
+	return c.ListCampaignsContext(context.Background(), groupGUID)
+}
+
+// ListCampaignsContext is the same as ListCampaigns, but uses the context to control the lifetime of the request.
+func (c *Campaigns) ListCampaignsContext(ctx context.Context, groupGUID string) (BitlyCampaigns, error) {
+	v := url.Values{}
+
+	if len(groupGUID) > 0 {
+		v.Add("group_guid", groupGUID)
+	}
+
+	data, err := c.CallContext(ctx, client.AppendQuery(campaignsEndpoint, v), http.MethodGet, nil)
+	if err != nil {
+		return BitlyCampaigns{}, err
+	}
+
+	return unmarshalCampaigns(data)
+}
+
+// CreateCampaign creates a campaign in a group.
+func (c *Campaigns) CreateCampaign(campaign *CampaignRequest) (Campaign, error) {
+	return c.CreateCampaignContext(context.Background(), campaign)
+}
+
+// CreateCampaignContext is the same as CreateCampaign, but uses the context to control the lifetime of the request.
+func (c *Campaigns) CreateCampaignContext(ctx context.Context, campaign *CampaignRequest) (Campaign, error) {
+	if len(campaign.Name) == 0 {
+		return Campaign{}, fmt.Errorf("campaigns: the name of the campaign is empty")
+	}
+
+	payload, err := campaign.marshal()
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	data, err := c.CallContext(ctx, campaignsEndpoint, http.MethodPost, payload)
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	return unmarshalCampaign(data)
+}
+
+// RetrieveCampaign returns the details of a campaign.
+func (c *Campaigns) RetrieveCampaign(campaignGUID string) (Campaign, error) {
+	return c.RetrieveCampaignContext(context.Background(), campaignGUID)
+}
+
+// RetrieveCampaignContext is the same as RetrieveCampaign, but uses the context to control the lifetime of the request.
+func (c *Campaigns) RetrieveCampaignContext(ctx context.Context, campaignGUID string) (Campaign, error) {
+	data, err := c.CallContext(ctx, fmt.Sprintf(campaignEndpoint, campaignGUID), http.MethodGet, nil)
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	return unmarshalCampaign(data)
+}
+
+// UpdateCampaign updates the fields of a campaign that are set in the request. Setting ChannelGUIDs replaces
+// the channels of the campaign.
+func (c *Campaigns) UpdateCampaign(campaignGUID string, campaign *CampaignRequest) (Campaign, error) {
+	return c.UpdateCampaignContext(context.Background(), campaignGUID, campaign)
+}
+
+// UpdateCampaignContext is the same as UpdateCampaign, but uses the context to control the lifetime of the request.
+func (c *Campaigns) UpdateCampaignContext(ctx context.Context, campaignGUID string, campaign *CampaignRequest) (Campaign, error) {
+	payload, err := campaign.marshal()
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	data, err := c.CallContext(ctx, fmt.Sprintf(campaignEndpoint, campaignGUID), http.MethodPatch, payload)
+	if err != nil {
+		return Campaign{}, err
+	}
+
+	return unmarshalCampaign(data)
+}
+
+// ListChannels returns the channels of a group and campaign. Empty GUIDs aren't used to filter the channels.
+func (c *Campaigns) ListChannels(groupGUID string, campaignGUID string) (BitlyChannels, error) {
+	return c.ListChannelsContext(context.Background(), groupGUID, campaignGUID)
+}
+
+// ListChannelsContext is the same as ListChannels, but uses the context to control the lifetime of the request.
+func (c *Campaigns) ListChannelsContext(ctx context.Context, groupGUID string, campaignGUID string) (BitlyChannels, error) {
+	v := url.Values{}
+
+	if len(groupGUID) > 0 {
+		v.Add("group_guid", groupGUID)
+	}
+
+	if len(campaignGUID) > 0 {
+		v.Add("campaign_guid", campaignGUID)
+	}
+
+	data, err := c.CallContext(ctx, client.AppendQuery(channelsEndpoint, v), http.MethodGet, nil)
+	if err != nil {
+		return BitlyChannels{}, err
+	}
+
+	return unmarshalChannels(data)
+}
+
+// CreateChannel creates a channel in a group.
+func (c *Campaigns) CreateChannel(channel *ChannelRequest) (Channel, error) {
+	return c.CreateChannelContext(context.Background(), channel)
+}
+
+// CreateChannelContext is the same as CreateChannel, but uses the context to control the lifetime of the request.
+func (c *Campaigns) CreateChannelContext(ctx context.Context, channel *ChannelRequest) (Channel, error) {
+	if len(channel.Name) == 0 {
+		return Channel{}, fmt.Errorf("campaigns: the name of the channel is empty")
+	}
+
+	payload, err := channel.marshal()
+	if err != nil {
+		return Channel{}, err
+	}
+
+	data, err := c.CallContext(ctx, channelsEndpoint, http.MethodPost, payload)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	return unmarshalChannel(data)
+}
+
+// RetrieveChannel returns the details of a channel, including its Bitlinks.
+func (c *Campaigns) RetrieveChannel(channelGUID string) (Channel, error) {
+	return c.RetrieveChannelContext(context.Background(), channelGUID)
+}
+
+// RetrieveChannelContext is the same as RetrieveChannel, but uses the context to control the lifetime of the request.
+func (c *Campaigns) RetrieveChannelContext(ctx context.Context, channelGUID string) (Channel, error) {
+	data, err := c.CallContext(ctx, fmt.Sprintf(channelEndpoint, channelGUID), http.MethodGet, nil)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	return unmarshalChannel(data)
+}
+
+// UpdateChannel updates the fields of a channel that are set in the request. Setting Bitlinks replaces the
+// Bitlinks of the channel, use AddBitlinksToChannel and RemoveBitlinksFromChannel to change only some of them.
+func (c *Campaigns) UpdateChannel(channelGUID string, channel *ChannelRequest) (Channel, error) {
+	return c.UpdateChannelContext(context.Background(), channelGUID, channel)
+}
+
+// UpdateChannelContext is the same as UpdateChannel, but uses the context to control the lifetime of the request.
+func (c *Campaigns) UpdateChannelContext(ctx context.Context, channelGUID string, channel *ChannelRequest) (Channel, error) {
+	payload, err := channel.marshal()
+	if err != nil {
+		return Channel{}, err
+	}
+
+	data, err := c.CallContext(ctx, fmt.Sprintf(channelEndpoint, channelGUID), http.MethodPatch, payload)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	return unmarshalChannel(data)
+}
+
+// AddBitlinksToChannel adds Bitlinks to a channel, as part of the campaign. The channel is retrieved first,
+// so its other Bitlinks are kept. Bitlinks that are already in the channel for the campaign are skipped.
+func (c *Campaigns) AddBitlinksToChannel(channelGUID string, campaignGUID string, bitlinkIDs ...string) (Channel, error) {
+	return c.AddBitlinksToChannelContext(context.Background(), channelGUID, campaignGUID, bitlinkIDs...)
+}
+
+// AddBitlinksToChannelContext is the same as AddBitlinksToChannel, but uses the context to control the lifetime of the requests.
+func (c *Campaigns) AddBitlinksToChannelContext(ctx context.Context, channelGUID string, campaignGUID string, bitlinkIDs ...string) (Channel, error) {
+	return c.changeBitlinks(ctx, channelGUID, func(current []ChannelBitlink) []ChannelBitlink {
+		seen := make(map[ChannelBitlink]bool, len(current))
+		for _, b := range current {
+			seen[b] = true
+		}
+
+		for _, id := range bitlinkIDs {
+			b := ChannelBitlink{BitlinkID: client.TrimScheme(id), CampaignGUID: campaignGUID}
+			if !seen[b] {
+				seen[b] = true
+				current = append(current, b)
+			}
+		}
+		return current
+	})
+}
+
+// RemoveBitlinksFromChannel removes Bitlinks from a channel, for all campaigns.
+func (c *Campaigns) RemoveBitlinksFromChannel(channelGUID string, bitlinkIDs ...string) (Channel, error) {
+	return c.RemoveBitlinksFromChannelContext(context.Background(), channelGUID, bitlinkIDs...)
+}
+
+// RemoveBitlinksFromChannelContext is the same as RemoveBitlinksFromChannel, but uses the context to control the lifetime of the requests.
+func (c *Campaigns) RemoveBitlinksFromChannelContext(ctx context.Context, channelGUID string, bitlinkIDs ...string) (Channel, error) {
+	return c.changeBitlinks(ctx, channelGUID, func(current []ChannelBitlink) []ChannelBitlink {
+		removed := make(map[string]bool, len(bitlinkIDs))
+		for _, id := range bitlinkIDs {
+			removed[client.TrimScheme(id)] = true
+		}
+
+		bitlinks := []ChannelBitlink{}
+		for _, b := range current {
+			if !removed[b.BitlinkID] {
+				bitlinks = append(bitlinks, b)
+			}
+		}
+		return bitlinks
+	})
+}
+
+// changeBitlinks retrieves the channel and replaces its Bitlinks with the result of change.
+func (c *Campaigns) changeBitlinks(ctx context.Context, channelGUID string, change func(current []ChannelBitlink) []ChannelBitlink) (Channel, error) {
+	channel, err := c.RetrieveChannelContext(ctx, channelGUID)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	req := channelBitlinksRequest{Bitlinks: change(append([]ChannelBitlink{}, channel.Bitlinks...))}
+	payload, err := req.marshal()
+	if err != nil {
+		return Channel{}, err
+	}
+
+	data, err := c.CallContext(ctx, fmt.Sprintf(channelEndpoint, channelGUID), http.MethodPatch, payload)
+	if err != nil {
+		return Channel{}, err
+	}
+
+	return unmarshalChannel(data)
+}
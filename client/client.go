@@ -1,15 +1,21 @@
 package client
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 )
 
 const (
 	// BitlyBaseURL is the base URL for the Bitly v4 API
 	BitlyBaseURL = "https://api-ssl.bitly.com/v4/"
+	// DefaultUserAgent is the User-Agent header sent to Bitly when no other value is configured
+	DefaultUserAgent = "retgits-bitly-go"
 )
 
 // Client contains all the functions to communicate between Bitly and your app.
@@ -17,6 +23,21 @@ type Client struct {
 	// Many of Bitly's API methods require an OAuth access token for authentication.
 	// You can generate a generic access token by confirming your password on https://bitly.is/accesstoken.
 	AccessToken string
+	// TokenSource provides the access token for every request. When nil, AccessToken is used.
+	TokenSource TokenSource
+	// RetryPolicy determines if, and how, failed requests are retried. When nil, each request is attempted once.
+	RetryPolicy *RetryPolicy
+	// HTTPClient is the client used to send requests to Bitly. When nil, http.DefaultClient is used.
+	HTTPClient *http.Client
+	// BaseURL is the URL all requests are sent to. When empty, BitlyBaseURL is used.
+	BaseURL string
+	// UserAgent is sent as the User-Agent header. When empty, DefaultUserAgent is used.
+	UserAgent string
+	// RateLimiter blocks requests until they fit in the budget of their EndpointClass. When nil,
+	// requests are not limited on the client side.
+	RateLimiter *RateLimiter
+
+	middlewares []Middleware
 }
 
 // NewClient returns a new Client pointer that can be chained with builder
@@ -32,28 +53,165 @@ func (c *Client) WithAccessToken(accessToken string) *Client {
 	return c
 }
 
-// Call sends a request to Bitly and receives the response.
+// WithTokenSource sets a config TokenSource value returning a Client pointer for
+// chaining. Use this when the access token can change while the client is used.
+func (c *Client) WithTokenSource(source TokenSource) *Client {
+	c.TokenSource = source
+	return c
+}
+
+// WithRetryPolicy sets a config RetryPolicy value returning a Client pointer for
+// chaining.
+func (c *Client) WithRetryPolicy(policy *RetryPolicy) *Client {
+	c.RetryPolicy = policy
+	return c
+}
+
+// WithHTTPClient sets a config HTTPClient value returning a Client pointer for
+// chaining. Use this to configure timeouts, proxies or TLS settings.
+func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
+	c.HTTPClient = httpClient
+	return c
+}
+
+// WithBaseURL sets a config BaseURL value returning a Client pointer for
+// chaining. Use this to send requests to a different server, like a local stub server in tests.
+func (c *Client) WithBaseURL(baseURL string) *Client {
+	if len(baseURL) > 0 && !strings.HasSuffix(baseURL, "/") {
+		baseURL = fmt.Sprintf("%s/", baseURL)
+	}
+	c.BaseURL = baseURL
+	return c
+}
+
+// WithUserAgent sets a config UserAgent value returning a Client pointer for
+// chaining.
+func (c *Client) WithUserAgent(userAgent string) *Client {
+	c.UserAgent = userAgent
+	return c
+}
+
+// WithTimeout sets the timeout of the HTTPClient returning a Client pointer for
+// chaining. The timeout is set on a copy of the HTTPClient, so http.DefaultClient, or a client
+// set using WithHTTPClient, isn't modified.
+func (c *Client) WithTimeout(timeout time.Duration) *Client {
+	httpClient := *c.httpClient()
+	httpClient.Timeout = timeout
+	c.HTTPClient = &httpClient
+	return c
+}
+
+// WithRateLimiter sets a config RateLimiter value returning a Client pointer for
+// chaining.
+func (c *Client) WithRateLimiter(limiter *RateLimiter) *Client {
+	c.RateLimiter = limiter
+	return c
+}
+
+// Call sends a request to Bitly and receives the response. When Bitly responds with a status code
+// outside of the 2xx range, the returned error is an *APIError.
 func (c *Client) Call(urlSuffix string, httpMethod string, payload []byte) ([]byte, error) {
-	var req *http.Request
-	var err error
+	return c.CallContext(context.Background(), urlSuffix, httpMethod, payload)
+}
+
+// CallContext sends a request to Bitly and receives the response. The request is aborted when the
+// context is cancelled or its deadline expires.
+func (c *Client) CallContext(ctx context.Context, urlSuffix string, httpMethod string, payload []byte) ([]byte, error) {
+	attempts := c.RetryPolicy.attempts(httpMethod)
+	class := ClassifyEndpoint(urlSuffix, httpMethod)
 
+	for attempt := 1; ; attempt++ {
+		// The token is fetched for every attempt, so a TokenSource can rotate it between retries
+		token, err := c.token(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if c.RateLimiter != nil {
+			if err := c.RateLimiter.Wait(ctx, class); err != nil {
+				return nil, err
+			}
+		}
+
+		data, header, err := c.do(ctx, token, urlSuffix, httpMethod, payload)
+		if c.RateLimiter != nil {
+			c.RateLimiter.Update(class, header)
+		}
+
+		if attempt >= attempts || !isRetryable(ctx, err) {
+			return data, err
+		}
+
+		if sleepErr := sleep(ctx, c.RetryPolicy.backoff(attempt, err, header)); sleepErr != nil {
+			return nil, sleepErr
+		}
+	}
+}
+
+// do makes a single attempt to send the request to Bitly.
+func (c *Client) do(ctx context.Context, token string, urlSuffix string, httpMethod string, payload []byte) ([]byte, http.Header, error) {
+	var body io.Reader
 	if len(payload) > 0 {
-		req, err = http.NewRequest(httpMethod, fmt.Sprintf("%s%s", BitlyBaseURL, urlSuffix), strings.NewReader(string(payload)))
-	} else {
-		req, err = http.NewRequest(httpMethod, fmt.Sprintf("%s%s", BitlyBaseURL, urlSuffix), nil)
+		body = bytes.NewReader(payload)
 	}
 
+	req, err := http.NewRequest(httpMethod, fmt.Sprintf("%s%s", c.baseURL(), urlSuffix), body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	req.Header.Add("authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	req = req.WithContext(ctx)
+	req.Header.Add("authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("User-Agent", c.userAgent())
+	if len(payload) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
-	res, err := http.DefaultClient.Do(req)
+	res, err := c.doer().Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	defer res.Body.Close()
-	return ioutil.ReadAll(res.Body)
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, res.Header, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, res.Header, newAPIError(res.StatusCode, res.Header, data)
+	}
+
+	return data, res.Header, nil
+}
+
+// token returns the access token from the TokenSource, or the AccessToken when there is no TokenSource. Errors
+// of the TokenSource are returned unchanged, so callers can check their type.
+func (c *Client) token(ctx context.Context) (string, error) {
+	if c.TokenSource == nil {
+		return c.AccessToken, nil
+	}
+
+	return c.TokenSource.Token(ctx)
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) baseURL() string {
+	if len(c.BaseURL) > 0 {
+		return c.BaseURL
+	}
+	return BitlyBaseURL
+}
+
+func (c *Client) userAgent() string {
+	if len(c.UserAgent) > 0 {
+		return c.UserAgent
+	}
+	return DefaultUserAgent
 }
@@ -0,0 +1,306 @@
+package client_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/retgits/bitly/client"
+)
+
+// stubTransport answers requests with the status codes in order, and repeats the last one.
+type stubTransport struct {
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	requests int
+	last     *http.Request
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := s.statuses[len(s.statuses)-1]
+	if s.requests < len(s.statuses) {
+		status = s.statuses[s.requests]
+	}
+	s.requests++
+	s.last = req
+
+	return &http.Response{
+		StatusCode: status,
+		Header:     s.header,
+		Body:       ioutil.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestClientOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		client    *client.Client
+		url       string
+		userAgent string
+	}{
+		{
+			name:      "defaults",
+			client:    client.NewClient(),
+			url:       client.BitlyBaseURL + "user",
+			userAgent: client.DefaultUserAgent,
+		},
+		{
+			name:      "base URL without a slash",
+			client:    client.NewClient().WithBaseURL("http://127.0.0.1:8080/v4").WithUserAgent("my-app"),
+			url:       "http://127.0.0.1:8080/v4/user",
+			userAgent: "my-app",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &stubTransport{statuses: []int{200}}
+			if _, err := tt.client.WithHTTPClient(&http.Client{Transport: transport}).Call("user", http.MethodGet, nil); err != nil {
+				t.Fatalf("Call() returned %s", err.Error())
+			}
+			if got := transport.last.URL.String(); got != tt.url {
+				t.Errorf("sent the request to %s, want %s", got, tt.url)
+			}
+			if got := transport.last.Header.Get("User-Agent"); got != tt.userAgent {
+				t.Errorf("User-Agent = %s, want %s", got, tt.userAgent)
+			}
+		})
+	}
+}
+
+func TestWithTimeoutCopiesTheHTTPClient(t *testing.T) {
+	httpClient := &http.Client{}
+	c := client.NewClient().WithHTTPClient(httpClient).WithTimeout(time.Second)
+	if httpClient.Timeout != 0 || c.HTTPClient.Timeout != time.Second {
+		t.Errorf("Timeout = %s of the given client and %s of the Client", httpClient.Timeout, c.HTTPClient.Timeout)
+	}
+}
+
+func TestCallRetries(t *testing.T) {
+	policy := &client.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int
+		header   http.Header
+		policy   *client.RetryPolicy
+		requests int
+		status   int
+	}{
+		{
+			name:     "retries until success",
+			method:   http.MethodGet,
+			statuses: []int{503, 503, 200},
+			policy:   policy,
+			requests: 3,
+		},
+		{
+			name:     "waits for retry-after",
+			method:   http.MethodGet,
+			statuses: []int{429, 200},
+			header:   http.Header{"Retry-After": []string{"0"}},
+			policy:   &client.RetryPolicy{MaxAttempts: 2, MinBackoff: time.Hour, MaxBackoff: time.Hour},
+			requests: 2,
+		},
+		{
+			name:     "gives up after max attempts",
+			method:   http.MethodGet,
+			statuses: []int{502},
+			policy:   policy,
+			requests: 3,
+			status:   http.StatusBadGateway,
+		},
+		{
+			name:     "client errors aren't retried",
+			method:   http.MethodGet,
+			statuses: []int{400},
+			policy:   policy,
+			requests: 1,
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:     "non-idempotent requests aren't retried",
+			method:   http.MethodPost,
+			statuses: []int{503},
+			policy:   policy,
+			requests: 1,
+			status:   http.StatusServiceUnavailable,
+		},
+		{
+			name:     "no retry policy",
+			method:   http.MethodGet,
+			statuses: []int{503},
+			requests: 1,
+			status:   http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &stubTransport{statuses: tt.statuses, header: tt.header}
+			c := client.NewClient().WithHTTPClient(&http.Client{Transport: transport}).WithRetryPolicy(tt.policy)
+			start := time.Now()
+			_, err := c.Call("user", tt.method, nil)
+			if time.Since(start) > 5*time.Second {
+				t.Errorf("Call() took %s", time.Since(start))
+			}
+
+			if tt.status == 0 && err != nil {
+				t.Fatalf("Call() returned %s", err.Error())
+			}
+			if tt.status != 0 {
+				apiErr, ok := err.(*client.APIError)
+				if !ok || apiErr.StatusCode != tt.status {
+					t.Errorf("Call() = %v, want an *APIError with status %d", err, tt.status)
+				}
+			}
+
+			if transport.requests != tt.requests {
+				t.Errorf("sent %d requests, want %d", transport.requests, tt.requests)
+			}
+		})
+	}
+}
+
+func TestCallContextCancelledDuringBackoff(t *testing.T) {
+	transport := &stubTransport{statuses: []int{503}}
+	c := client.NewClient().WithHTTPClient(&http.Client{Transport: transport}).WithRetryPolicy(&client.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Hour, MaxBackoff: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.CallContext(ctx, "user", http.MethodGet, nil); err != context.DeadlineExceeded {
+		t.Errorf("CallContext() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if transport.requests != 1 {
+		t.Errorf("sent %d requests, want 1", transport.requests)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) client.Middleware {
+		return func(next client.Doer) client.Doer {
+			return client.DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" before")
+				res, err := next.Do(req)
+				calls = append(calls, name+" after")
+				return res, err
+			})
+		}
+	}
+
+	c := client.NewClient().WithHTTPClient(&http.Client{Transport: &stubTransport{statuses: []int{200}}}).Use(record("outer"), record("inner"))
+	if _, err := c.Call("user", http.MethodGet, nil); err != nil {
+		t.Fatalf("Call() returned %s", err.Error())
+	}
+
+	want := "outer before,inner before,inner after,outer after"
+	if got := strings.Join(calls, ","); got != want {
+		t.Errorf("calls = %s, want %s", got, want)
+	}
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	c := client.NewClient().
+		WithAccessToken("secret-token").
+		WithHTTPClient(&http.Client{Transport: &stubTransport{statuses: []int{200}}}).
+		Use(client.RequestIDMiddleware(), client.LoggingMiddleware(log.New(&buf, "", 0)))
+	if _, err := c.Call("user", http.MethodGet, nil); err != nil {
+		t.Fatalf("Call() returned %s", err.Error())
+	}
+
+	line := buf.String()
+	if strings.Contains(line, "secret-token") {
+		t.Errorf("the access token was logged: %s", line)
+	}
+	for _, want := range []string{"method=GET", "status=200", "request_id="} {
+		if !strings.Contains(line, want) {
+			t.Errorf("the log line %s doesn't contain %s", line, want)
+		}
+	}
+}
+
+func TestTokenSources(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  client.TokenSource
+		header  string
+		wantErr string
+	}{
+		{name: "static", source: client.StaticTokenSource("token"), header: "Bearer token"},
+		{name: "empty static", source: client.StaticTokenSource(""), wantErr: "bitly: the access token is empty"},
+		{name: "unset environment variable", source: client.EnvTokenSource("BITLY_TEST_UNSET_TOKEN"), wantErr: "bitly: the environment variable BITLY_TEST_UNSET_TOKEN is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &stubTransport{statuses: []int{200}}
+			_, err := client.NewClient().WithHTTPClient(&http.Client{Transport: transport}).WithTokenSource(tt.source).Call("user", http.MethodGet, nil)
+
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Call() = %v, want %s", err, tt.wantErr)
+				}
+				if transport.requests != 0 {
+					t.Errorf("sent %d requests without a token", transport.requests)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Call() returned %s", err.Error())
+			}
+			if got := transport.last.Header.Get("Authorization"); got != tt.header {
+				t.Errorf("Authorization = %s, want %s", got, tt.header)
+			}
+		})
+	}
+}
+
+func TestTokenSourceErrorsAreReturnedUnchanged(t *testing.T) {
+	want := errors.New("token expired")
+	c := client.NewClient().
+		WithHTTPClient(&http.Client{Transport: &stubTransport{statuses: []int{200}}}).
+		WithRetryPolicy(&client.RetryPolicy{MaxAttempts: 3}).
+		WithTokenSource(client.TokenSourceFunc(func(ctx context.Context) (string, error) {
+			return "", want
+		}))
+
+	if _, err := c.Call("user", http.MethodGet, nil); err != want {
+		t.Errorf("Call() = %v, want %v", err, want)
+	}
+}
+
+func TestTokenFetchedForEveryAttempt(t *testing.T) {
+	transport := &stubTransport{statuses: []int{503, 503, 200}}
+	fetched := 0
+	c := client.NewClient().
+		WithHTTPClient(&http.Client{Transport: transport}).
+		WithRetryPolicy(&client.RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond}).
+		WithTokenSource(client.TokenSourceFunc(func(ctx context.Context) (string, error) {
+			fetched++
+			return fmt.Sprintf("token-%d", fetched), nil
+		}))
+
+	if _, err := c.Call("user", http.MethodGet, nil); err != nil {
+		t.Fatalf("Call() returned %s", err.Error())
+	}
+	// A token that was rotated between attempts is used for the next attempt
+	if got := transport.last.Header.Get("Authorization"); fetched != 3 || got != "Bearer token-3" {
+		t.Errorf("the token was fetched %d times and the last attempt used %s, want 3 and Bearer token-3", fetched, got)
+	}
+}
@@ -0,0 +1,93 @@
+// Package custombitlinks contains the methods to interact with the custom Bitlinks in Bitly
+package custombitlinks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/retgits/bitly"
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/bitlinks"
+)
+
+// CustomBitlink is a custom back-half on a domain, like go.ourbrand.co/launch, which redirects to a Bitlink
+type CustomBitlink struct {
+	CustomBitlink string `json:"custom_bitlink"`
+	// The Bitlink the custom Bitlink currently redirects to
+	Bitlink bitlinks.BitlinkDetails `json:"bitlink"`
+	// The Bitlinks the custom Bitlink redirected to, including the current one
+	BitlinkHistory []HistoryEntry `json:"bitlink_history"`
+}
+
+// HistoryEntry is a Bitlink a custom Bitlink redirected to
+type HistoryEntry struct {
+	bitlinks.BitlinkDetails
+	// Whether the custom Bitlink currently redirects to this Bitlink
+	IsActive bool `json:"is_active"`
+	// The time the custom Bitlink was moved to another Bitlink
+	DeactivatedAt *bitly.Time `json:"deactivated_at,omitempty"`
+}
+
+// AddRequest contains the custom Bitlink to add to an existing Bitlink
+type AddRequest struct {
+	// The custom Bitlink, consisting of a domain and a back-half, like go.ourbrand.co/launch
+	CustomBitlink string `json:"custom_bitlink"`
+	// The ID of the Bitlink the custom Bitlink redirects to, like bit.ly/2Bq3bLx
+	BitlinkID string `json:"bitlink_id"`
+}
+
+// Availability tells if a custom Bitlink can be added
+type Availability struct {
+	CustomBitlink string
+	Available     bool
+	// The Bitlink that uses the custom Bitlink, when it isn't available
+	Bitlink string
+}
+
+// retargetRequest contains the Bitlink to move a custom Bitlink to
+type retargetRequest struct {
+	BitlinkID string `json:"bitlink_id"`
+}
+
+// Split returns the domain and the back-half of a custom Bitlink. A scheme, like https://, is removed.
+func Split(customBitlink string) (domain string, backHalf string, err error) {
+	id := client.TrimScheme(customBitlink)
+
+	slash := strings.Index(id, "/")
+	if slash <= 0 || slash == len(id)-1 {
+		return "", "", fmt.Errorf("custombitlinks: %q must consist of a domain and a back-half, like bit.ly/launch", customBitlink)
+	}
+
+	domain, backHalf = id[:slash], id[slash+1:]
+	for _, r := range backHalf {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return "", "", fmt.Errorf("custombitlinks: the back-half of %q may only contain letters, digits, - and _", customBitlink)
+		}
+	}
+
+	return domain, backHalf, nil
+}
+
+// normalize returns the custom Bitlink without a scheme, after validating it.
+func normalize(customBitlink string) (string, error) {
+	domain, backHalf, err := Split(customBitlink)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/%s", domain, backHalf), nil
+}
+
+func (r *AddRequest) marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *retargetRequest) marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func unmarshalCustomBitlink(data []byte) (CustomBitlink, error) {
+	var r CustomBitlink
+	err := json.Unmarshal(data, &r)
+	return r, err
+}
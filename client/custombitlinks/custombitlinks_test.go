@@ -0,0 +1,228 @@
+package custombitlinks_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/custombitlinks"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		customBitlink string
+		domain        string
+		backHalf      string
+		wantErr       string
+	}{
+		{customBitlink: "bit.ly/launch", domain: "bit.ly", backHalf: "launch"},
+		{customBitlink: "https://go.ourbrand.co/Launch-2019_a", domain: "go.ourbrand.co", backHalf: "Launch-2019_a"},
+		{customBitlink: "bit.ly", wantErr: "must consist of a domain and a back-half"},
+		{customBitlink: "bit.ly/", wantErr: "must consist of a domain and a back-half"},
+		{customBitlink: "/launch", wantErr: "must consist of a domain and a back-half"},
+		{customBitlink: "bit.ly/launch/2019", wantErr: "may only contain letters, digits, - and _"},
+		{customBitlink: "bit.ly/launch 2019", wantErr: "may only contain letters, digits, - and _"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.customBitlink, func(t *testing.T) {
+			domain, backHalf, err := custombitlinks.Split(tt.customBitlink)
+
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Split() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Split() returned %s", err.Error())
+			}
+			if domain != tt.domain || backHalf != tt.backHalf {
+				t.Errorf("Split() = %s, %s, want %s, %s", domain, backHalf, tt.domain, tt.backHalf)
+			}
+		})
+	}
+}
+
+func TestCustomBitlinks(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	first := srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{LongURL: "https://example.org/v1"})
+	second := srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{LongURL: "https://example.org/v2"})
+	c := custombitlinks.New(srv.Client())
+
+	added, err := c.AddCustomBitlink(&custombitlinks.AddRequest{CustomBitlink: "https://bit.ly/launch", BitlinkID: "https://" + first.ID})
+	if err != nil {
+		t.Fatalf("AddCustomBitlink() returned %s", err.Error())
+	}
+	if added.CustomBitlink != "bit.ly/launch" || added.Bitlink.ID != first.ID {
+		t.Errorf("AddCustomBitlink() = %s to %s, want bit.ly/launch to %s", added.CustomBitlink, added.Bitlink.ID, first.ID)
+	}
+
+	moved, err := c.RetargetCustomBitlink("bit.ly/launch", second.ID)
+	if err != nil {
+		t.Fatalf("RetargetCustomBitlink() returned %s", err.Error())
+	}
+	if moved.Bitlink.ID != second.ID {
+		t.Errorf("RetargetCustomBitlink() redirects to %s, want %s", moved.Bitlink.ID, second.ID)
+	}
+
+	history, err := c.GetCustomBitlinkHistory("bit.ly/launch")
+	if err != nil {
+		t.Fatalf("GetCustomBitlinkHistory() returned %s", err.Error())
+	}
+	tests := []struct {
+		id          string
+		active      bool
+		deactivated bool
+	}{
+		{id: first.ID, deactivated: true},
+		{id: second.ID, active: true},
+	}
+	if len(history) != len(tests) {
+		t.Fatalf("GetCustomBitlinkHistory() returned %d entries, want %d", len(history), len(tests))
+	}
+	for idx, tt := range tests {
+		entry := history[idx]
+		if entry.ID != tt.id || entry.IsActive != tt.active || (entry.DeactivatedAt != nil) != tt.deactivated {
+			t.Errorf("entry %d = %s active %t deactivated %t, want %s active %t deactivated %t", idx, entry.ID, entry.IsActive, entry.DeactivatedAt != nil, tt.id, tt.active, tt.deactivated)
+		}
+	}
+
+	// The Bitlinks know which custom Bitlinks redirect to them
+	if l, _ := srv.Bitlink(first.ID); len(l.CustomBitlinks) != 0 {
+		t.Errorf("the previous Bitlink still has the custom Bitlinks %v", l.CustomBitlinks)
+	}
+	if l, _ := srv.Bitlink(second.ID); len(l.CustomBitlinks) != 1 || l.CustomBitlinks[0] != "bit.ly/launch" {
+		t.Errorf("the new Bitlink has the custom Bitlinks %v", l.CustomBitlinks)
+	}
+}
+
+func TestCustomBitlinkErrors(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	l := srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{LongURL: "https://example.org"})
+	c := custombitlinks.New(srv.Client())
+	if _, err := c.AddCustomBitlink(&custombitlinks.AddRequest{CustomBitlink: "bit.ly/taken", BitlinkID: l.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		call       func() error
+		statusCode int
+		wantErr    string
+	}{
+		{
+			name: "custom Bitlink in use",
+			call: func() error {
+				_, err := c.AddCustomBitlink(&custombitlinks.AddRequest{CustomBitlink: "bit.ly/taken", BitlinkID: l.ID})
+				return err
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "custom Bitlink is a Bitlink",
+			call: func() error {
+				_, err := c.AddCustomBitlink(&custombitlinks.AddRequest{CustomBitlink: l.ID, BitlinkID: l.ID})
+				return err
+			},
+			statusCode: http.StatusConflict,
+		},
+		{
+			name: "unknown Bitlink",
+			call: func() error {
+				_, err := c.AddCustomBitlink(&custombitlinks.AddRequest{CustomBitlink: "bit.ly/new", BitlinkID: "bit.ly/unknown"})
+				return err
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "unknown custom Bitlink",
+			call: func() error {
+				_, err := c.RetargetCustomBitlink("bit.ly/unknown", l.ID)
+				return err
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "empty Bitlink ID",
+			call: func() error {
+				_, err := c.AddCustomBitlink(&custombitlinks.AddRequest{CustomBitlink: "bit.ly/new"})
+				return err
+			},
+			wantErr: "custombitlinks: the Bitlink ID is empty",
+		},
+		{
+			name: "invalid custom Bitlink",
+			call: func() error {
+				_, err := c.RetrieveCustomBitlink("bit.ly/a/b")
+				return err
+			},
+			wantErr: "custombitlinks: the back-half of",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.ResetRequests()
+			err := tt.call()
+
+			if len(tt.wantErr) > 0 {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Errorf("the call returned %v, want %s", err, tt.wantErr)
+				}
+				if got := len(srv.Requests()); got != 0 {
+					t.Errorf("sent %d requests for an invalid call", got)
+				}
+				return
+			}
+
+			apiErr, ok := err.(*client.APIError)
+			if !ok || apiErr.StatusCode != tt.statusCode {
+				t.Errorf("the call returned %v, want status %d", err, tt.statusCode)
+			}
+		})
+	}
+}
+
+func TestCheckAvailability(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	l := srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{LongURL: "https://example.org"})
+	c := custombitlinks.New(srv.Client())
+	if _, err := c.AddCustomBitlink(&custombitlinks.AddRequest{CustomBitlink: "bit.ly/taken", BitlinkID: l.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		customBitlink string
+		available     bool
+		bitlink       string
+	}{
+		{customBitlink: "bit.ly/free", available: true},
+		{customBitlink: "https://bit.ly/taken", bitlink: l.ID},
+		{customBitlink: l.ID, bitlink: l.ID},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.customBitlink, func(t *testing.T) {
+			got, err := c.CheckAvailability(tt.customBitlink)
+			if err != nil {
+				t.Fatalf("CheckAvailability() returned %s", err.Error())
+			}
+			if got.Available != tt.available || got.Bitlink != tt.bitlink {
+				t.Errorf("CheckAvailability() = %+v, want available %t and Bitlink %s", got, tt.available, tt.bitlink)
+			}
+		})
+	}
+
+	// Other errors than 404 are returned
+	srv.InjectFault(bitlytest.Fault{StatusCode: http.StatusInternalServerError})
+	if _, err := c.CheckAvailability("bit.ly/free"); err == nil {
+		t.Errorf("CheckAvailability() ignored a server error")
+	}
+}
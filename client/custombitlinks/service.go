@@ -0,0 +1,183 @@
+// Package custombitlinks contains the methods to interact with the custom Bitlinks in Bitly
+package custombitlinks
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/retgits/bitly/client"
+)
+
+const (
+	customBitlinksEndpoint = "custom_bitlinks"
+	customBitlinkEndpoint  = "custom_bitlinks/%s"
+	bitlinkEndpoint        = "bitlinks/%s"
+)
+
+// Service is the interface implemented by CustomBitlinks. Code that depends on Service, instead of *CustomBitlinks,
+// can use a mock in its unit tests.
+type Service interface {
+	AddCustomBitlink(request *AddRequest) (CustomBitlink, error)
+	AddCustomBitlinkContext(ctx context.Context, request *AddRequest) (CustomBitlink, error)
+
+	RetrieveCustomBitlink(customBitlink string) (CustomBitlink, error)
+	RetrieveCustomBitlinkContext(ctx context.Context, customBitlink string) (CustomBitlink, error)
+
+	RetargetCustomBitlink(customBitlink string, bitlinkID string) (CustomBitlink, error)
+	RetargetCustomBitlinkContext(ctx context.Context, customBitlink string, bitlinkID string) (CustomBitlink, error)
+
+	GetCustomBitlinkHistory(customBitlink string) ([]HistoryEntry, error)
+	GetCustomBitlinkHistoryContext(ctx context.Context, customBitlink string) ([]HistoryEntry, error)
+
+	CheckAvailability(customBitlink string) (Availability, error)
+	CheckAvailabilityContext(ctx context.Context, customBitlink string) (Availability, error)
+}
+
+// CustomBitlinks are back-halves chosen by the user, on bit.ly or a branded short domain, which redirect to a
+// Bitlink. A custom Bitlink can be moved to another Bitlink, so printed links keep working when the
+// destination changes. (Example: go.ourbrand.co/launch)
+type CustomBitlinks struct {
+	*client.Client
+}
+
+var _ Service = (*CustomBitlinks)(nil)
+
+// New creates a new instance of the CustomBitlinks client.
+func New(c *client.Client) *CustomBitlinks {
+	return &CustomBitlinks{
+		c,
+	}
+}
+
+// AddCustomBitlink adds a custom back-half to an existing Bitlink.
+func (c *CustomBitlinks) AddCustomBitlink(request *AddRequest) (CustomBitlink, error) {
+	return c.AddCustomBitlinkContext(context.Background(), request)
+}
+
+// AddCustomBitlinkContext is the same as AddCustomBitlink, but uses the context to control the lifetime of the request.
+func (c *CustomBitlinks) AddCustomBitlinkContext(ctx context.Context, request *AddRequest) (CustomBitlink, error) {
+	customBitlink, err := normalize(request.CustomBitlink)
+	if err != nil {
+		return CustomBitlink{}, err
+	}
+
+	if len(request.BitlinkID) == 0 {
+		return CustomBitlink{}, fmt.Errorf("custombitlinks: the Bitlink ID is empty")
+	}
+
+	req := AddRequest{CustomBitlink: customBitlink, BitlinkID: client.TrimScheme(request.BitlinkID)}
+	payload, err := req.marshal()
+	if err != nil {
+		return CustomBitlink{}, err
+	}
+
+	data, err := c.CallContext(ctx, customBitlinksEndpoint, http.MethodPost, payload)
+	if err != nil {
+		return CustomBitlink{}, err
+	}
+
+	return unmarshalCustomBitlink(data)
+}
+
+// RetrieveCustomBitlink returns a custom Bitlink with the Bitlink it redirects to.
+func (c *CustomBitlinks) RetrieveCustomBitlink(customBitlink string) (CustomBitlink, error) {
+	return c.RetrieveCustomBitlinkContext(context.Background(), customBitlink)
+}
+
+// RetrieveCustomBitlinkContext is the same as RetrieveCustomBitlink, but uses the context to control the lifetime of the request.
+func (c *CustomBitlinks) RetrieveCustomBitlinkContext(ctx context.Context, customBitlink string) (CustomBitlink, error) {
+	customBitlink, err := normalize(customBitlink)
+	if err != nil {
+		return CustomBitlink{}, err
+	}
+
+	data, err := c.CallContext(ctx, fmt.Sprintf(customBitlinkEndpoint, customBitlink), http.MethodGet, nil)
+	if err != nil {
+		return CustomBitlink{}, err
+	}
+
+	return unmarshalCustomBitlink(data)
+}
+
+// RetargetCustomBitlink moves a custom Bitlink to another Bitlink. The previous Bitlink stays in the history
+// of the custom Bitlink.
+func (c *CustomBitlinks) RetargetCustomBitlink(customBitlink string, bitlinkID string) (CustomBitlink, error) {
+	return c.RetargetCustomBitlinkContext(context.Background(), customBitlink, bitlinkID)
+}
+
+// RetargetCustomBitlinkContext is the same as RetargetCustomBitlink, but uses the context to control the lifetime of the request.
+func (c *CustomBitlinks) RetargetCustomBitlinkContext(ctx context.Context, customBitlink string, bitlinkID string) (CustomBitlink, error) {
+	customBitlink, err := normalize(customBitlink)
+	if err != nil {
+		return CustomBitlink{}, err
+	}
+
+	if len(bitlinkID) == 0 {
+		return CustomBitlink{}, fmt.Errorf("custombitlinks: the Bitlink ID is empty")
+	}
+
+	req := retargetRequest{BitlinkID: client.TrimScheme(bitlinkID)}
+	payload, err := req.marshal()
+	if err != nil {
+		return CustomBitlink{}, err
+	}
+
+	data, err := c.CallContext(ctx, fmt.Sprintf(customBitlinkEndpoint, customBitlink), http.MethodPatch, payload)
+	if err != nil {
+		return CustomBitlink{}, err
+	}
+
+	return unmarshalCustomBitlink(data)
+}
+
+// GetCustomBitlinkHistory returns the Bitlinks a custom Bitlink redirected to, including the current one.
+func (c *CustomBitlinks) GetCustomBitlinkHistory(customBitlink string) ([]HistoryEntry, error) {
+	return c.GetCustomBitlinkHistoryContext(context.Background(), customBitlink)
+}
+
+// GetCustomBitlinkHistoryContext is the same as GetCustomBitlinkHistory, but uses the context to control the lifetime of the request.
+func (c *CustomBitlinks) GetCustomBitlinkHistoryContext(ctx context.Context, customBitlink string) ([]HistoryEntry, error) {
+	custom, err := c.RetrieveCustomBitlinkContext(ctx, customBitlink)
+	if err != nil {
+		return nil, err
+	}
+
+	if custom.BitlinkHistory == nil {
+		return []HistoryEntry{}, nil
+	}
+	return custom.BitlinkHistory, nil
+}
+
+// CheckAvailability checks if a custom Bitlink is unused, so it can be added. Bitly has no endpoint for this,
+// so the custom Bitlink and a Bitlink with the same ID are looked up. The result can be outdated by the time
+// the custom Bitlink is added.
+func (c *CustomBitlinks) CheckAvailability(customBitlink string) (Availability, error) {
+	return c.CheckAvailabilityContext(context.Background(), customBitlink)
+}
+
+// CheckAvailabilityContext is the same as CheckAvailability, but uses the context to control the lifetime of the requests.
+func (c *CustomBitlinks) CheckAvailabilityContext(ctx context.Context, customBitlink string) (Availability, error) {
+	customBitlink, err := normalize(customBitlink)
+	if err != nil {
+		return Availability{}, err
+	}
+
+	custom, err := c.RetrieveCustomBitlinkContext(ctx, customBitlink)
+	if err == nil {
+		return Availability{CustomBitlink: customBitlink, Bitlink: custom.Bitlink.ID}, nil
+	}
+	if !client.IsNotFound(err) {
+		return Availability{}, err
+	}
+
+	_, err = c.CallContext(ctx, fmt.Sprintf(bitlinkEndpoint, customBitlink), http.MethodGet, nil)
+	if err == nil {
+		return Availability{CustomBitlink: customBitlink, Bitlink: customBitlink}, nil
+	}
+	if !client.IsNotFound(err) {
+		return Availability{}, err
+	}
+
+	return Availability{CustomBitlink: customBitlink, Available: true}, nil
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIError is returned by Call when Bitly answers with a non-2xx status code.
+type APIError struct {
+	// The HTTP status code returned by Bitly
+	StatusCode int `json:"-"`
+	// A short, machine readable, message (like NOT_FOUND or RATE_LIMIT_EXCEEDED)
+	Message string `json:"message"`
+	// A human readable description of the error
+	Description string `json:"description"`
+	// The resource the error applies to
+	Resource string `json:"resource"`
+	// The individual field errors, if any
+	Errors []FieldError `json:"errors"`
+	// The raw response body as received from Bitly
+	Body []byte `json:"-"`
+	// The response headers as received from Bitly
+	Header http.Header `json:"-"`
+}
+
+// FieldError contains the details of an error on a single field of the request
+type FieldError struct {
+	Field     string `json:"field"`
+	Message   string `json:"message"`
+	ErrorCode string `json:"error_code"`
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "bitly: %d", e.StatusCode)
+
+	if len(e.Message) > 0 {
+		fmt.Fprintf(&sb, " %s", e.Message)
+	} else {
+		fmt.Fprintf(&sb, " %s", http.StatusText(e.StatusCode))
+	}
+
+	if len(e.Resource) > 0 {
+		fmt.Fprintf(&sb, " (resource: %s)", e.Resource)
+	}
+
+	if len(e.Description) > 0 {
+		fmt.Fprintf(&sb, ": %s", e.Description)
+	}
+
+	for _, f := range e.Errors {
+		fmt.Fprintf(&sb, "; %s: %s", f.Field, f.Message)
+		if len(f.ErrorCode) > 0 {
+			fmt.Fprintf(&sb, " [%s]", f.ErrorCode)
+		}
+	}
+
+	return sb.String()
+}
+
+// IsNotFound returns true if the error is an APIError caused by a resource that doesn't exist.
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsRateLimited returns true if the error is an APIError caused by exceeding the rate limit.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, http.StatusTooManyRequests)
+}
+
+// IsForbidden returns true if the error is an APIError caused by a lack of permissions.
+func IsForbidden(err error) bool {
+	return hasStatus(err, http.StatusForbidden)
+}
+
+func hasStatus(err error, statusCode int) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == statusCode
+}
+
+func newAPIError(statusCode int, header http.Header, body []byte) *APIError {
+	e := &APIError{}
+	// Bitly doesn't always return a JSON body (for example when a proxy in front of it fails),
+	// so a failure to unmarshal is not an error in itself.
+	_ = json.Unmarshal(body, e)
+	e.StatusCode = statusCode
+	e.Body = body
+	e.Header = header
+	return e
+}
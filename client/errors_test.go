@@ -0,0 +1,107 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		message    string
+		resource   string
+		fields     int
+		errString  string
+	}{
+		{
+			name:       "bitly error",
+			statusCode: http.StatusNotFound,
+			body:       `{"message":"NOT_FOUND","resource":"bitlinks","description":"What you are looking for cannot be found."}`,
+			message:    "NOT_FOUND",
+			resource:   "bitlinks",
+			errString:  "bitly: 404 NOT_FOUND (resource: bitlinks): What you are looking for cannot be found.",
+		},
+		{
+			name:       "field errors",
+			statusCode: http.StatusBadRequest,
+			body:       `{"message":"INVALID_ARG_LONG_URL","errors":[{"field":"long_url","message":"invalid","error_code":"invalid"}]}`,
+			message:    "INVALID_ARG_LONG_URL",
+			fields:     1,
+			errString:  "bitly: 400 INVALID_ARG_LONG_URL; long_url: invalid [invalid]",
+		},
+		{
+			name:       "body isn't json",
+			statusCode: http.StatusBadGateway,
+			body:       "<html>Bad Gateway</html>",
+			errString:  "bitly: 502 Bad Gateway",
+		},
+		{
+			name:       "empty body",
+			statusCode: http.StatusServiceUnavailable,
+			errString:  "bitly: 503 Service Unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{"X-Test": []string{"1"}}
+			err := newAPIError(tt.statusCode, header, []byte(tt.body))
+
+			if err.StatusCode != tt.statusCode {
+				t.Errorf("StatusCode = %d, want %d", err.StatusCode, tt.statusCode)
+			}
+			if err.Message != tt.message {
+				t.Errorf("Message = %q, want %q", err.Message, tt.message)
+			}
+			if err.Resource != tt.resource {
+				t.Errorf("Resource = %q, want %q", err.Resource, tt.resource)
+			}
+			if len(err.Errors) != tt.fields {
+				t.Errorf("len(Errors) = %d, want %d", len(err.Errors), tt.fields)
+			}
+			if string(err.Body) != tt.body {
+				t.Errorf("Body = %q, want %q", err.Body, tt.body)
+			}
+			if err.Header.Get("X-Test") != "1" {
+				t.Errorf("Header wasn't kept")
+			}
+			if err.Error() != tt.errString {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.errString)
+			}
+		})
+	}
+}
+
+func TestErrorStatusHelpers(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		notFound    bool
+		rateLimited bool
+		forbidden   bool
+	}{
+		{name: "not found", err: &APIError{StatusCode: http.StatusNotFound}, notFound: true},
+		{name: "rate limited", err: &APIError{StatusCode: http.StatusTooManyRequests}, rateLimited: true},
+		{name: "forbidden", err: &APIError{StatusCode: http.StatusForbidden}, forbidden: true},
+		{name: "server error", err: &APIError{StatusCode: http.StatusInternalServerError}},
+		{name: "other error", err: errors.New("connection refused")},
+		{name: "nil", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.err); got != tt.notFound {
+				t.Errorf("IsNotFound() = %t, want %t", got, tt.notFound)
+			}
+			if got := IsRateLimited(tt.err); got != tt.rateLimited {
+				t.Errorf("IsRateLimited() = %t, want %t", got, tt.rateLimited)
+			}
+			if got := IsForbidden(tt.err); got != tt.forbidden {
+				t.Errorf("IsForbidden() = %t, want %t", got, tt.forbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+// Package export writes all Bitlinks of a group, together with their click totals, as CSV, TSV or JSON lines.
+//
+// The Bitlinks are read page by page and the click totals are retrieved by a pool of workers, so large groups
+// are streamed without keeping them in memory. The number of requests per second is limited by the
+// RateLimiter of the client, if it has one.
+package export
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/groups"
+)
+
+// Format is the format of an export.
+type Format string
+
+const (
+	// FormatCSV writes a header and a line per Bitlink, separated by commas
+	FormatCSV Format = "csv"
+	// FormatTSV writes a header and a line per Bitlink, separated by tabs
+	FormatTSV Format = "tsv"
+	// FormatJSONL writes a JSON object per Bitlink per line
+	FormatJSONL Format = "jsonl"
+)
+
+const (
+	// DefaultWorkers is the number of concurrent click requests of an export
+	DefaultWorkers = 4
+	// pageSize is the number of Bitlinks retrieved per page
+	pageSize = 100
+)
+
+// Row is a Bitlink with its click total.
+type Row struct {
+	Link        groups.Link
+	TotalClicks int64
+}
+
+// Column is a column of the export.
+type Column struct {
+	Name string
+	// needsClicks is true if the column needs the click total of the Bitlink
+	needsClicks bool
+	value       func(r Row) interface{}
+}
+
+// Columns are all columns that can be exported, in their default order.
+var Columns = []Column{
+	{Name: "id", value: func(r Row) interface{} { return r.Link.ID }},
+	{Name: "link", value: func(r Row) interface{} { return r.Link.Link }},
+	{Name: "long_url", value: func(r Row) interface{} { return r.Link.LongURL }},
+	{Name: "title", value: func(r Row) interface{} { return r.Link.Title }},
+	{Name: "tags", value: func(r Row) interface{} { return nonNil(r.Link.Tags) }},
+	{Name: "archived", value: func(r Row) interface{} { return r.Link.Archived }},
+	{Name: "created_at", value: func(r Row) interface{} { return r.Link.CreatedAt }},
+	{Name: "created_by", value: func(r Row) interface{} { return r.Link.CreatedBy }},
+	{Name: "custom_bitlinks", value: func(r Row) interface{} { return nonNil(r.Link.CustomBitlinks) }},
+	{Name: "total_clicks", needsClicks: true, value: func(r Row) interface{} { return r.TotalClicks }},
+}
+
+// DefaultColumns are the columns that are exported when no columns are set.
+var DefaultColumns = []string{"id", "long_url", "title", "tags", "created_at", "total_clicks"}
+
+// Options contains the options of an export.
+type Options struct {
+	// The format of the export, defaults to FormatCSV
+	Format Format
+	// The names of the columns to export, defaults to DefaultColumns
+	Columns []string
+	// Only export Bitlinks with all of the tags
+	Tags []string
+	// Whether to export archived Bitlinks: on, off or both, defaults to off
+	Archived string
+	// The period of the click totals, defaults to all clicks
+	Clicks *bitlinks.MetricsRequest
+	// The number of concurrent click requests, defaults to DefaultWorkers
+	Workers int
+}
+
+// pendingRow is a Bitlink whose click total is being retrieved.
+type pendingRow struct {
+	row  Row
+	done chan struct{}
+	err  error
+}
+
+// Export writes all Bitlinks of the group to w, in the order in which Bitly returns them. It stops at the
+// first error and returns the number of Bitlinks that were written.
+func Export(groupsSvc groups.Service, bitlinksSvc bitlinks.Service, groupGUID string, w io.Writer, options *Options) (int, error) {
+	return ExportContext(context.Background(), groupsSvc, bitlinksSvc, groupGUID, w, options)
+}
+
+// ExportContext is the same as Export, but uses the context to control the lifetime of the requests.
+func ExportContext(ctx context.Context, groupsSvc groups.Service, bitlinksSvc bitlinks.Service, groupGUID string, w io.Writer, options *Options) (int, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	columns, err := selectColumns(options.Columns)
+	if err != nil {
+		return 0, err
+	}
+
+	enc, err := newEncoder(options.Format, w, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	needsClicks := false
+	for _, c := range columns {
+		needsClicks = needsClicks || c.needsClicks
+	}
+
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	work := make(chan *pendingRow)
+	order := make(chan *pendingRow, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range work {
+				summary, err := bitlinksSvc.GetClicksSummaryContext(ctx, p.row.Link.ID, options.Clicks)
+				if err != nil {
+					p.err = fmt.Errorf("export: retrieving the clicks of %s: %s", p.row.Link.ID, err.Error())
+				}
+				p.row.TotalClicks = summary.TotalClicks
+				close(p.done)
+			}
+		}()
+	}
+
+	// Read the pages, and hand every Bitlink to the workers and the writer
+	var iterErr error
+	go func() {
+		defer close(order)
+		defer close(work)
+
+		archived := options.Archived
+		if len(archived) == 0 {
+			archived = "off"
+		}
+
+		it := groups.NewBitlinkIterator(ctx, groupsSvc, groupGUID, &groups.BitlinksGroupRequest{
+			Size:     pageSize,
+			Archived: archived,
+			Tags:     options.Tags,
+		}, 0)
+
+		for it.Next() {
+			p := &pendingRow{row: Row{Link: it.Link()}, done: make(chan struct{})}
+			if needsClicks {
+				select {
+				case work <- p:
+				case <-ctx.Done():
+					return
+				}
+			} else {
+				close(p.done)
+			}
+
+			select {
+			case order <- p:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		iterErr = it.Err()
+	}()
+
+	// Write the Bitlinks in order, and stop at the first error
+	count := 0
+	for p := range order {
+		<-p.done
+		if err == nil && p.err != nil {
+			err = p.err
+		}
+		if err == nil {
+			err = enc.encode(p.row)
+		}
+		if err != nil {
+			cancel()
+			continue
+		}
+		count++
+	}
+	wg.Wait()
+
+	if err == nil && iterErr != nil {
+		err = fmt.Errorf("export: retrieving the Bitlinks of group %s: %s", groupGUID, iterErr.Error())
+	}
+	if err == nil {
+		err = enc.flush()
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	return count, err
+}
+
+// selectColumns returns the columns with the names, in the same order.
+func selectColumns(names []string) ([]Column, error) {
+	if len(names) == 0 {
+		names = DefaultColumns
+	}
+
+	columns := make([]Column, 0, len(names))
+	for _, name := range names {
+		found := false
+		for _, c := range Columns {
+			if c.Name == strings.TrimSpace(name) {
+				columns = append(columns, c)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("export: unknown column %q", name)
+		}
+	}
+
+	return columns, nil
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// encoder writes the rows of an export in a format.
+type encoder struct {
+	columns []Column
+	csv     *csv.Writer
+	json    *json.Encoder
+}
+
+func newEncoder(format Format, w io.Writer, columns []Column) (*encoder, error) {
+	enc := &encoder{columns: columns}
+
+	switch format {
+	case FormatCSV, FormatTSV, "":
+		enc.csv = csv.NewWriter(w)
+		if format == FormatTSV {
+			enc.csv.Comma = '\t'
+		}
+
+		header := make([]string, len(columns))
+		for idx, c := range columns {
+			header[idx] = c.Name
+		}
+		if err := enc.csv.Write(header); err != nil {
+			return nil, err
+		}
+	case FormatJSONL:
+		enc.json = json.NewEncoder(w)
+	default:
+		return nil, fmt.Errorf("export: unknown format %q, use csv, tsv or jsonl", string(format))
+	}
+
+	return enc, nil
+}
+
+func (e *encoder) encode(r Row) error {
+	if e.json != nil {
+		// A slice of key/value pairs keeps the columns in order, which a map wouldn't
+		var b strings.Builder
+		b.WriteString("{")
+		for idx, c := range e.columns {
+			if idx > 0 {
+				b.WriteString(",")
+			}
+			key, _ := json.Marshal(c.Name)
+			value, err := json.Marshal(c.value(r))
+			if err != nil {
+				return err
+			}
+			b.Write(key)
+			b.WriteString(":")
+			b.Write(value)
+		}
+		b.WriteString("}")
+		return e.json.Encode(json.RawMessage(b.String()))
+	}
+
+	record := make([]string, len(e.columns))
+	for idx, c := range e.columns {
+		switch v := c.value(r).(type) {
+		case []string:
+			record[idx] = strings.Join(v, ",")
+		default:
+			record[idx] = fmt.Sprintf("%v", v)
+		}
+	}
+	return e.csv.Write(record)
+}
+
+func (e *encoder) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package export_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/retgits/bitly"
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/export"
+	"github.com/retgits/bitly/client/groups"
+)
+
+// newServer returns a server with the Bitlinks bit.ly/e0 to bit.ly/e<n-1>, where bit.ly/e<i> has i clicks.
+func newServer(t *testing.T, n int) *bitlytest.Server {
+	srv := bitlytest.NewServer()
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("bit.ly/e%d", i)
+		srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{ID: id, LongURL: fmt.Sprintf("https://example.org/%d", i), Title: fmt.Sprintf("Link %d", i)})
+		if err := srv.SetClicks(id, []bitlinks.LinkClick{{Date: bitly.Time{Time: time.Now()}, Clicks: int64(i)}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return srv
+}
+
+func TestExportFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *export.Options
+		want    string
+	}{
+		{
+			name:    "csv",
+			options: &export.Options{Columns: []string{"id", "title", "total_clicks"}},
+			want:    "id,title,total_clicks\nbit.ly/e0,Link 0,0\nbit.ly/e1,Link 1,1\n",
+		},
+		{
+			name:    "tsv",
+			options: &export.Options{Format: export.FormatTSV, Columns: []string{"id", "long_url", "tags"}},
+			want:    "id\tlong_url\ttags\nbit.ly/e0\thttps://example.org/0\t\nbit.ly/e1\thttps://example.org/1\t\n",
+		},
+		{
+			name:    "jsonl",
+			options: &export.Options{Format: export.FormatJSONL, Columns: []string{"total_clicks", "id", "tags"}},
+			want:    "{\"total_clicks\":0,\"id\":\"bit.ly/e0\",\"tags\":[]}\n{\"total_clicks\":1,\"id\":\"bit.ly/e1\",\"tags\":[]}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, 2)
+			defer srv.Close()
+			c := srv.Client()
+
+			var buf bytes.Buffer
+			count, err := export.Export(groups.New(c), bitlinks.New(c), bitlytest.DefaultGroupGUID, &buf, tt.options)
+			if err != nil {
+				t.Fatalf("Export() returned %s", err.Error())
+			}
+			if count != 2 {
+				t.Errorf("Export() wrote %d Bitlinks, want 2", count)
+			}
+			if got := sortLines(buf.String()); got != sortLines(tt.want) {
+				t.Errorf("Export() wrote\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExportOptionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		options *export.Options
+		wantErr string
+	}{
+		{name: "unknown column", options: &export.Options{Columns: []string{"id", "clicks"}}, wantErr: `export: unknown column "clicks"`},
+		{name: "unknown format", options: &export.Options{Format: "xml"}, wantErr: `export: unknown format "xml"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, 1)
+			defer srv.Close()
+			c := srv.Client()
+
+			_, err := export.Export(groups.New(c), bitlinks.New(c), bitlytest.DefaultGroupGUID, &bytes.Buffer{}, tt.options)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("Export() = %v, want %s", err, tt.wantErr)
+			}
+			if got := len(srv.Requests()); got != 0 {
+				t.Errorf("sent %d requests, want 0", got)
+			}
+		})
+	}
+}
+
+func TestExportKeepsOrder(t *testing.T) {
+	const n = 250
+	srv := newServer(t, n)
+	defer srv.Close()
+	c := srv.Client()
+
+	// The clicks of the first Bitlinks are slow, so later Bitlinks are done first and must wait
+	srv.InjectFault(bitlytest.Fault{Method: http.MethodGet, PathPrefix: "bitlinks/", Delay: 20 * time.Millisecond, Times: 3})
+
+	var want []string
+	links, err := groups.New(c).IterateBitlinksByGroup(bitlytest.DefaultGroupGUID, &groups.BitlinksGroupRequest{Size: 100}, 0).All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range links {
+		var clicks int
+		fmt.Sscanf(l.ID, "bit.ly/e%d", &clicks)
+		want = append(want, fmt.Sprintf("%s,%d", l.ID, clicks))
+	}
+
+	var buf bytes.Buffer
+	count, err := export.Export(groups.New(c), bitlinks.New(c), bitlytest.DefaultGroupGUID, &buf, &export.Options{Columns: []string{"id", "total_clicks"}, Workers: 8})
+	if err != nil {
+		t.Fatalf("Export() returned %s", err.Error())
+	}
+	if count != n {
+		t.Errorf("Export() wrote %d Bitlinks, want %d", count, n)
+	}
+
+	got := strings.Split(strings.TrimSpace(buf.String()), "\n")[1:]
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("the Bitlinks weren't written in the order of the pages")
+	}
+}
+
+func TestExportStopsAtFirstError(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault bitlytest.Fault
+		want  string
+	}{
+		{
+			name:  "clicks",
+			fault: bitlytest.Fault{Method: http.MethodGet, PathPrefix: "bitlinks/", StatusCode: http.StatusInternalServerError},
+			want:  "export: retrieving the clicks of",
+		},
+		{
+			name:  "pages",
+			fault: bitlytest.Fault{Method: http.MethodGet, PathPrefix: "groups/", StatusCode: http.StatusForbidden},
+			want:  "export: retrieving the Bitlinks of group " + bitlytest.DefaultGroupGUID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newServer(t, 50)
+			defer srv.Close()
+			c := srv.Client()
+			srv.InjectFault(tt.fault)
+
+			var buf bytes.Buffer
+			count, err := export.Export(groups.New(c), bitlinks.New(c), bitlytest.DefaultGroupGUID, &buf, &export.Options{Workers: 4})
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Fatalf("Export() = %v, want %s", err, tt.want)
+			}
+			if count != 0 {
+				t.Errorf("Export() wrote %d Bitlinks, want 0", count)
+			}
+
+			// The export stops early, instead of retrieving the clicks of every Bitlink
+			if got := len(srv.Requests()); got >= 50 {
+				t.Errorf("sent %d requests after the first error", got)
+			}
+		})
+	}
+}
+
+func TestExportWithoutClicks(t *testing.T) {
+	srv := newServer(t, 10)
+	defer srv.Close()
+	c := srv.Client()
+
+	count, err := export.Export(groups.New(c), bitlinks.New(c), bitlytest.DefaultGroupGUID, &bytes.Buffer{}, &export.Options{Columns: []string{"id", "title"}})
+	if err != nil {
+		t.Fatalf("Export() returned %s", err.Error())
+	}
+	if count != 10 {
+		t.Errorf("Export() wrote %d Bitlinks, want 10", count)
+	}
+
+	// Only the page is retrieved, the clicks aren't needed
+	if got := len(srv.Requests()); got != 1 {
+		t.Errorf("sent %d requests, want 1", got)
+	}
+}
+
+// sortLines keeps the header as the first line and sorts the other lines, since the order of the Bitlinks
+// depends on the order of the server.
+func sortLines(s string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	if len(lines) > 1 && !strings.HasPrefix(lines[0], "{") {
+		sort.Strings(lines[1:])
+	} else {
+		sort.Strings(lines)
+	}
+	return strings.Join(lines, "\n")
+}
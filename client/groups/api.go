@@ -1,7 +1,15 @@
 // Package groups contains the methods to interact with the Groups in Bitly
 package groups
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/retgits/bitly"
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/metrics"
+)
 
 // Bitlinks contains the Bitlink information
 type Bitlinks struct {
@@ -56,8 +64,8 @@ type BitlyGroups struct {
 
 // Group contains group details
 type Group struct {
-	Created          string        `json:"created,omitempty"`
-	Modified         string        `json:"modified,omitempty"`
+	Created          *bitly.Time   `json:"created,omitempty"`
+	Modified         *bitly.Time   `json:"modified,omitempty"`
 	Bsds             []interface{} `json:"bsds,omitempty"`
 	GUID             string        `json:"guid,omitempty"`
 	OrganizationGUID string        `json:"organization_guid"`
@@ -69,18 +77,18 @@ type Group struct {
 
 // Link contains details information on Bitlinks
 type Link struct {
-	CreatedAt      string     `json:"created_at"`
-	ID             string     `json:"id"`
-	Link           string     `json:"link"`
-	CustomBitlinks []string   `json:"custom_bitlinks"`
-	LongURL        string     `json:"long_url"`
-	Title          string     `json:"title,omitempty"`
-	Archived       bool       `json:"archived"`
-	CreatedBy      string     `json:"created_by"`
-	ClientID       string     `json:"client_id"`
-	Tags           []string   `json:"tags"`
-	Deeplinks      []string   `json:"deeplinks"`
-	References     References `json:"references"`
+	CreatedAt      bitly.Time          `json:"created_at"`
+	ID             string              `json:"id"`
+	Link           string              `json:"link"`
+	CustomBitlinks []string            `json:"custom_bitlinks"`
+	LongURL        string              `json:"long_url"`
+	Title          string              `json:"title,omitempty"`
+	Archived       bool                `json:"archived"`
+	CreatedBy      string              `json:"created_by"`
+	ClientID       string              `json:"client_id"`
+	Tags           []string            `json:"tags"`
+	Deeplinks      []bitlinks.Deeplink `json:"deeplinks"`
+	References     References          `json:"references"`
 }
 
 // Metric contains information on the selected metric
@@ -91,13 +99,55 @@ type Metric struct {
 
 // Metrics is the response for a metrics request
 type Metrics struct {
-	UnitReference string   `json:"unit_reference"`
-	Metrics       []Metric `json:"metrics"`
-	Units         int64    `json:"units"`
-	Unit          string   `json:"unit"`
-	Facet         string   `json:"facet"`
+	UnitReference bitly.Time `json:"unit_reference"`
+	Metrics       []Metric   `json:"metrics"`
+	Units         int64      `json:"units"`
+	Unit          string     `json:"unit"`
+	Facet         string     `json:"facet"`
+}
+
+// Patch contains the fields to update on a group. Only the fields that are explicitly set are sent to
+// Bitly, so all other fields are left untouched.
+type Patch struct {
+	name             *string
+	organizationGUID *string
+	bsds             *[]string
+}
+
+// NewPatch creates a new Patch that can be chained with builder methods to set the fields to update.
+func NewPatch() *Patch {
+	return &Patch{}
 }
 
+// Name sets the name of the group returning a Patch pointer for chaining.
+func (p *Patch) Name(name string) *Patch {
+	p.name = &name
+	return p
+}
+
+// OrganizationGUID sets the organization of the group returning a Patch pointer for chaining.
+func (p *Patch) OrganizationGUID(organizationGUID string) *Patch {
+	p.organizationGUID = &organizationGUID
+	return p
+}
+
+// BSDs replaces the branded short domains of the group returning a Patch pointer for chaining.
+func (p *Patch) BSDs(bsds ...string) *Patch {
+	b := append([]string{}, bsds...)
+	p.bsds = &b
+	return p
+}
+
+// patchRequest is the payload sent to Bitly for a Patch
+type patchRequest struct {
+	Name             *string   `json:"name,omitempty"`
+	OrganizationGUID *string   `json:"organization_guid,omitempty"`
+	Bsds             *[]string `json:"bsds,omitempty"`
+}
+
+// MetricsRequest is used to generate the metrics request to Bitly
+type MetricsRequest = metrics.Request
+
 // Pagination contains data if more pages are available
 type Pagination struct {
 	Prev  string `json:"prev"`
@@ -117,14 +167,8 @@ type References struct {
 type SortedBitlinksGroupRequest struct {
 	// The type of sorting that you would like to do
 	SortType string
-	// A unit of time
-	Unit string
-	// An integer representing the time units to query data for. pass -1 to return all units of time.
-	Units int
-	// An ISO-8601 timestamp, indicating the most recent time for which to pull metrics. Will default to current time.
-	UnitReference string
-	// The quantity of items to be be returned
-	Size int
+	// The unit of time, number of units, unit reference and size of the list
+	metrics.Request
 }
 
 // SortedLink contains the link details of sorted links
@@ -138,10 +182,85 @@ type Tags struct {
 	Tags []string `json:"tags"`
 }
 
+// values returns the query parameters for the request
+func (r *BitlinksGroupRequest) values() url.Values {
+	v := url.Values{}
+
+	if r.Size != 0 {
+		v.Add("size", fmt.Sprintf("%d", r.Size))
+	}
+
+	if r.Page != 0 {
+		v.Add("page", fmt.Sprintf("%d", r.Page))
+	}
+
+	if r.CreatedBefore != 0 {
+		v.Add("created_before", fmt.Sprintf("%d", r.CreatedBefore))
+	}
+
+	if r.CreatedAfter != 0 {
+		v.Add("created_after", fmt.Sprintf("%d", r.CreatedAfter))
+	}
+
+	if r.ModifiedAfter != 0 {
+		v.Add("modified_after", fmt.Sprintf("%d", r.ModifiedAfter))
+	}
+
+	if len(r.Keyword) > 0 {
+		v.Add("keyword", r.Keyword)
+	}
+
+	if len(r.Query) > 0 {
+		v.Add("query", r.Query)
+	}
+
+	if len(r.Archived) > 0 {
+		v.Add("archived", r.Archived)
+	}
+
+	if len(r.Deeplinks) > 0 {
+		v.Add("deeplinks", r.Deeplinks)
+	}
+
+	if len(r.DomainDeeplinks) > 0 {
+		v.Add("domain_deeplinks", r.DomainDeeplinks)
+	}
+
+	if len(r.CampaignGUID) > 0 {
+		v.Add("campaign_guid", r.CampaignGUID)
+	}
+
+	if len(r.ChannelGUID) > 0 {
+		v.Add("channel_guid", r.ChannelGUID)
+	}
+
+	if len(r.CustomBitlinks) > 0 {
+		v.Add("custom_bitlink", r.CustomBitlinks)
+	}
+
+	if r.Tags != nil {
+		for idx := range r.Tags {
+			v.Add("tags", r.Tags[idx])
+		}
+	}
+
+	if r.EncodingLogin != nil {
+		for idx := range r.EncodingLogin {
+			v.Add("encoding_login", r.EncodingLogin[idx])
+		}
+	}
+
+	return v
+}
+
 func (r *BitlyGroupPreferences) marshal() ([]byte, error) {
 	return json.Marshal(r)
 }
 
+func (r *patchRequest) marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
 func (r *Group) marshal() ([]byte, error) {
 	return json.Marshal(r)
 }
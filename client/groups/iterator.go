@@ -0,0 +1,154 @@
+// Package groups contains the methods to interact with the Groups in Bitly
+package groups
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+)
+
+// BitlinkIterator walks over all Bitlinks of a Group, retrieving the next page from Bitly when the
+// current page has been consumed. A BitlinkIterator is not safe for concurrent use.
+//
+//	it := groupsSvc.IterateBitlinksByGroup("myGroupGUID", &groups.BitlinksGroupRequest{Size: 50}, 0)
+//	for it.Next() {
+//		fmt.Println(it.Link().Link)
+//	}
+//	if err := it.Err(); err != nil {
+//		// handle the error
+//	}
+type BitlinkIterator struct {
+	ctx       context.Context
+	svc       Service
+	groupGUID string
+	maxItems  int
+
+	input   BitlinksGroupRequest
+	links   []Link
+	idx     int
+	count   int
+	current Link
+	done    bool
+	err     error
+}
+
+// IterateBitlinksByGroup returns an iterator over all Bitlinks for a Group that match the filters of the
+// request. The iterator stops after maxItems Bitlinks, or when all Bitlinks have been returned if maxItems
+// is zero or less.
+func (g *Groups) IterateBitlinksByGroup(groupGUID string, input *BitlinksGroupRequest, maxItems int) *BitlinkIterator {
+	return g.IterateBitlinksByGroupContext(context.Background(), groupGUID, input, maxItems)
+}
+
+// IterateBitlinksByGroupContext is the same as IterateBitlinksByGroup, but uses the context to control the lifetime of the requests.
+func (g *Groups) IterateBitlinksByGroupContext(ctx context.Context, groupGUID string, input *BitlinksGroupRequest, maxItems int) *BitlinkIterator {
+	return NewBitlinkIterator(ctx, g, groupGUID, input, maxItems)
+}
+
+// NewBitlinkIterator returns an iterator over all Bitlinks for a Group, which retrieves the pages using the
+// Service. This makes it possible to iterate over the Bitlinks of a mocked Service.
+func NewBitlinkIterator(ctx context.Context, svc Service, groupGUID string, input *BitlinksGroupRequest, maxItems int) *BitlinkIterator {
+	it := &BitlinkIterator{
+		ctx:       ctx,
+		svc:       svc,
+		groupGUID: groupGUID,
+		maxItems:  maxItems,
+	}
+
+	if input != nil {
+		it.input = *input
+	}
+
+	return it
+}
+
+// Next advances the iterator to the next Bitlink, which is then available through Link. It returns false
+// when there are no more Bitlinks, or when an error occurred.
+func (it *BitlinkIterator) Next() bool {
+	if it.err != nil || (it.maxItems > 0 && it.count >= it.maxItems) {
+		return false
+	}
+
+	for it.idx >= len(it.links) {
+		if it.done {
+			return false
+		}
+
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+
+	it.current = it.links[it.idx]
+	it.idx++
+	it.count++
+	return true
+}
+
+// Link returns the current Bitlink.
+func (it *BitlinkIterator) Link() Link {
+	return it.current
+}
+
+// Err returns the first error that occurred while retrieving the Bitlinks.
+func (it *BitlinkIterator) Err() error {
+	return it.err
+}
+
+// All consumes the rest of the iterator and returns the Bitlinks.
+func (it *BitlinkIterator) All() ([]Link, error) {
+	var links []Link
+	for it.Next() {
+		links = append(links, it.Link())
+	}
+	return links, it.Err()
+}
+
+// fetch retrieves the next page from Bitly and determines the page after that.
+func (it *BitlinkIterator) fetch() error {
+	res, err := it.svc.RetrieveBitlinksByGroupContext(it.ctx, it.groupGUID, &it.input)
+	if err != nil {
+		return err
+	}
+
+	it.links = res.Links
+	it.idx = 0
+
+	if len(res.Links) == 0 {
+		it.done = true
+		return nil
+	}
+
+	page, ok := nextPage(it.input.Page, res.Pagination)
+	if !ok {
+		it.done = true
+		return nil
+	}
+
+	it.input.Page = page
+	return nil
+}
+
+// nextPage determines the number of the next page. The page in the URL Bitly sends in pagination.next is
+// used when available, otherwise the page number is incremented while there are more Bitlinks left.
+func nextPage(current int, p Pagination) (int, bool) {
+	if current < 1 {
+		current = 1
+	}
+
+	if len(p.Next) > 0 {
+		u, err := url.Parse(p.Next)
+		if err == nil {
+			if page, err := strconv.Atoi(u.Query().Get("page")); err == nil {
+				// Guard against a next URL that points to the page that was just retrieved
+				return page, page != current
+			}
+		}
+	}
+
+	if p.Total <= 0 || p.Size <= 0 || p.Page*p.Size >= p.Total {
+		return 0, false
+	}
+
+	return current + 1, true
+}
@@ -0,0 +1,166 @@
+package groups_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/groups"
+	"github.com/retgits/bitly/client/mocks"
+)
+
+func page(n int, next string, ids ...string) groups.Bitlinks {
+	res := groups.Bitlinks{Pagination: groups.Pagination{Page: int64(n), Size: 2, Next: next}}
+	for _, id := range ids {
+		res.Links = append(res.Links, groups.Link{ID: id})
+	}
+	return res
+}
+
+func TestBitlinkIterator(t *testing.T) {
+	tests := []struct {
+		name     string
+		pages    map[int]groups.Bitlinks
+		maxItems int
+		want     []string
+		requests int
+		wantErr  bool
+	}{
+		{
+			name: "follows next",
+			pages: map[int]groups.Bitlinks{
+				1: page(1, "https://api-ssl.bitly.com/v4/groups/g/bitlinks?page=2&size=2", "a", "b"),
+				2: page(2, "", "c"),
+			},
+			want:     []string{"a", "b", "c"},
+			requests: 2,
+		},
+		{
+			name: "next points to the same page",
+			pages: map[int]groups.Bitlinks{
+				1: page(1, "https://api-ssl.bitly.com/v4/groups/g/bitlinks?size=2", "a", "b"),
+			},
+			want:     []string{"a", "b"},
+			requests: 1,
+		},
+		{
+			name: "uses the total without next",
+			pages: map[int]groups.Bitlinks{
+				1: {Links: []groups.Link{{ID: "a"}, {ID: "b"}}, Pagination: groups.Pagination{Page: 1, Size: 2, Total: 3}},
+				2: {Links: []groups.Link{{ID: "c"}}, Pagination: groups.Pagination{Page: 2, Size: 2, Total: 3}},
+			},
+			want:     []string{"a", "b", "c"},
+			requests: 2,
+		},
+		{
+			name: "stops at an empty page",
+			pages: map[int]groups.Bitlinks{
+				1: page(1, "https://api-ssl.bitly.com/v4/groups/g/bitlinks?page=2", "a"),
+				2: page(2, "https://api-ssl.bitly.com/v4/groups/g/bitlinks?page=3"),
+			},
+			want:     []string{"a"},
+			requests: 2,
+		},
+		{
+			name: "max items on the first page",
+			pages: map[int]groups.Bitlinks{
+				1: page(1, "https://api-ssl.bitly.com/v4/groups/g/bitlinks?page=2", "a", "b"),
+			},
+			maxItems: 1,
+			want:     []string{"a"},
+			requests: 1,
+		},
+		{
+			name: "max items on a later page",
+			pages: map[int]groups.Bitlinks{
+				1: page(1, "https://api-ssl.bitly.com/v4/groups/g/bitlinks?page=2", "a", "b"),
+				2: page(2, "https://api-ssl.bitly.com/v4/groups/g/bitlinks?page=3", "c", "d"),
+			},
+			maxItems: 3,
+			want:     []string{"a", "b", "c"},
+			requests: 2,
+		},
+		{
+			name: "error on a later page",
+			pages: map[int]groups.Bitlinks{
+				1: page(1, "https://api-ssl.bitly.com/v4/groups/g/bitlinks?page=2", "a"),
+			},
+			want:     []string{"a"},
+			requests: 2,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			requests := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				requests++
+				mu.Unlock()
+
+				n, _ := strconv.Atoi(r.URL.Query().Get("page"))
+				if n == 0 {
+					n = 1
+				}
+				res, ok := tt.pages[n]
+				if !ok {
+					http.Error(w, `{"message":"INTERNAL_ERROR"}`, http.StatusInternalServerError)
+					return
+				}
+				json.NewEncoder(w).Encode(res)
+			}))
+			defer srv.Close()
+
+			it := groups.New(client.NewClient().WithBaseURL(srv.URL)).IterateBitlinksByGroup("g", &groups.BitlinksGroupRequest{Size: 2}, tt.maxItems)
+			var got []string
+			for it.Next() {
+				got = append(got, it.Link().ID)
+			}
+
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if (it.Err() != nil) != tt.wantErr {
+				t.Errorf("Err() = %v, want an error: %t", it.Err(), tt.wantErr)
+			}
+			if requests != tt.requests {
+				t.Errorf("retrieved %d pages, want %d", requests, tt.requests)
+			}
+			if it.Next() {
+				t.Errorf("Next() returned true after the last Bitlink")
+			}
+		})
+	}
+}
+
+func TestNewBitlinkIteratorWithMock(t *testing.T) {
+	svc := &mocks.Groups{
+		RetrieveBitlinksByGroupFunc: func(ctx context.Context, groupGUID string, input *groups.BitlinksGroupRequest) (groups.Bitlinks, error) {
+			if input.Page > 1 {
+				return page(int(input.Page), ""), nil
+			}
+			return page(1, "https://api-ssl.bitly.com/v4/groups/g/bitlinks?page=2", "a", "b"), nil
+		},
+	}
+
+	it := groups.NewBitlinkIterator(context.Background(), svc, "g", nil, 0)
+	var got []string
+	for it.Next() {
+		got = append(got, it.Link().ID)
+	}
+
+	if fmt.Sprint(got) != "[a b]" || it.Err() != nil {
+		t.Errorf("got %v and %v, want [a b]", got, it.Err())
+	}
+	if calls := svc.CallsTo("RetrieveBitlinksByGroup"); len(calls) != 2 {
+		t.Errorf("retrieved %d pages, want 2", len(calls))
+	}
+}
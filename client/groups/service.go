@@ -2,11 +2,13 @@
 package groups
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
 
 	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/metrics"
 )
 
 const (
@@ -21,6 +23,46 @@ const (
 	sortedBitlinksEndpoint     = "groups/%s/bitlinks/%s"
 )
 
+// Service is the interface implemented by Groups. Code that depends on Service, instead of *Groups, can use a
+// mock in its unit tests.
+type Service interface {
+	RetrieveGroups(organizationGUID string) (BitlyGroups, error)
+	RetrieveGroupsContext(ctx context.Context, organizationGUID string) (BitlyGroups, error)
+
+	RetrieveGroupDetails(groupGUID string) (Group, error)
+	RetrieveGroupDetailsContext(ctx context.Context, groupGUID string) (Group, error)
+
+	RetrieveGroupPreferences(groupGUID string) (BitlyGroupPreferences, error)
+	RetrieveGroupPreferencesContext(ctx context.Context, groupGUID string) (BitlyGroupPreferences, error)
+
+	UpdateGroupDetails(groupGUID string, prefs Group) (Group, error)
+	UpdateGroupDetailsContext(ctx context.Context, groupGUID string, prefs Group) (Group, error)
+
+	PatchGroupDetails(groupGUID string, patch *Patch) (Group, error)
+	PatchGroupDetailsContext(ctx context.Context, groupGUID string, patch *Patch) (Group, error)
+
+	UpdateGroupPreferences(groupGUID string, prefs BitlyGroupPreferences) (BitlyGroupPreferences, error)
+	UpdateGroupPreferencesContext(ctx context.Context, groupGUID string, prefs BitlyGroupPreferences) (BitlyGroupPreferences, error)
+
+	RetrieveBitlinksByGroup(groupGUID string, input *BitlinksGroupRequest) (Bitlinks, error)
+	RetrieveBitlinksByGroupContext(ctx context.Context, groupGUID string, input *BitlinksGroupRequest) (Bitlinks, error)
+
+	RetrieveTagsByGroup(groupGUID string) (Tags, error)
+	RetrieveTagsByGroupContext(ctx context.Context, groupGUID string) (Tags, error)
+
+	GetGroupClickMetricsByCountries(groupGUID string, input *MetricsRequest) (Metrics, error)
+	GetGroupClickMetricsByCountriesContext(ctx context.Context, groupGUID string, input *MetricsRequest) (Metrics, error)
+
+	GetGroupClickMetricsByReferringNetworks(groupGUID string, input *MetricsRequest) (Metrics, error)
+	GetGroupClickMetricsByReferringNetworksContext(ctx context.Context, groupGUID string, input *MetricsRequest) (Metrics, error)
+
+	RetrieveGroupShortenCounts(groupGUID string, input *MetricsRequest) (Metrics, error)
+	RetrieveGroupShortenCountsContext(ctx context.Context, groupGUID string, input *MetricsRequest) (Metrics, error)
+
+	RetrieveSortedBitlinksForGroup(groupGUID string, input *SortedBitlinksGroupRequest) (Bitlinks, error)
+	RetrieveSortedBitlinksForGroupContext(ctx context.Context, groupGUID string, input *SortedBitlinksGroupRequest) (Bitlinks, error)
+}
+
 // Groups are a subdivision within an organization. A user will belong to a group within an organization.
 // Most actions on our API will be on behalf of a group. For example, when you shorten a link, it will be
 // on behalf of a user and a group.
@@ -28,6 +70,8 @@ type Groups struct {
 	*client.Client
 }
 
+var _ Service = (*Groups)(nil)
+
 // New creates a new instance of the Groups client.
 func New(c *client.Client) *Groups {
 	return &Groups{
@@ -37,6 +81,11 @@ func New(c *client.Client) *Groups {
 
 // RetrieveGroups is to retrieve all groups from Bitly by organization
 func (g *Groups) RetrieveGroups(organizationGUID string) (BitlyGroups, error) {
+	return g.RetrieveGroupsContext(context.Background(), organizationGUID)
+}
+
+// RetrieveGroupsContext is the same as RetrieveGroups, but uses the context to control the lifetime of the request.
+func (g *Groups) RetrieveGroupsContext(ctx context.Context, organizationGUID string) (BitlyGroups, error) {
 	v := url.Values{}
 
 	if len(organizationGUID) > 0 {
@@ -50,7 +99,7 @@ func (g *Groups) RetrieveGroups(organizationGUID string) (BitlyGroups, error) {
 		url = fmt.Sprintf("%s?%s", url, queryParams)
 	}
 
-	data, err := g.Call(url, http.MethodGet, nil)
+	data, err := g.CallContext(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return BitlyGroups{}, err
 	}
@@ -60,7 +109,12 @@ func (g *Groups) RetrieveGroups(organizationGUID string) (BitlyGroups, error) {
 
 // RetrieveGroupDetails is to retrieve details for a group
 func (g *Groups) RetrieveGroupDetails(groupGUID string) (Group, error) {
-	data, err := g.Call(fmt.Sprintf(groupDetailsEndpoint, groupGUID), http.MethodGet, nil)
+	return g.RetrieveGroupDetailsContext(context.Background(), groupGUID)
+}
+
+// RetrieveGroupDetailsContext is the same as RetrieveGroupDetails, but uses the context to control the lifetime of the request.
+func (g *Groups) RetrieveGroupDetailsContext(ctx context.Context, groupGUID string) (Group, error) {
+	data, err := g.CallContext(ctx, fmt.Sprintf(groupDetailsEndpoint, groupGUID), http.MethodGet, nil)
 	if err != nil {
 		return Group{}, err
 	}
@@ -70,7 +124,12 @@ func (g *Groups) RetrieveGroupDetails(groupGUID string) (Group, error) {
 
 // RetrieveGroupPreferences is to retrieve preferences for a specific group
 func (g *Groups) RetrieveGroupPreferences(groupGUID string) (BitlyGroupPreferences, error) {
-	data, err := g.Call(fmt.Sprintf(groupPreferencesEndpoint, groupGUID), http.MethodGet, nil)
+	return g.RetrieveGroupPreferencesContext(context.Background(), groupGUID)
+}
+
+// RetrieveGroupPreferencesContext is the same as RetrieveGroupPreferences, but uses the context to control the lifetime of the request.
+func (g *Groups) RetrieveGroupPreferencesContext(ctx context.Context, groupGUID string) (BitlyGroupPreferences, error) {
+	data, err := g.CallContext(ctx, fmt.Sprintf(groupPreferencesEndpoint, groupGUID), http.MethodGet, nil)
 	if err != nil {
 		return BitlyGroupPreferences{}, err
 	}
@@ -78,14 +137,46 @@ func (g *Groups) RetrieveGroupPreferences(groupGUID string) (BitlyGroupPreferenc
 	return unmarshalGroupPreferences(data)
 }
 
-// UpdateGroupDetails is to update details for a specific group
+// UpdateGroupDetails is to update details for a specific group. All fields of the Group are sent to Bitly,
+// use PatchGroupDetails to only update specific fields.
 func (g *Groups) UpdateGroupDetails(groupGUID string, prefs Group) (Group, error) {
+	return g.UpdateGroupDetailsContext(context.Background(), groupGUID, prefs)
+}
+
+// UpdateGroupDetailsContext is the same as UpdateGroupDetails, but uses the context to control the lifetime of the request.
+func (g *Groups) UpdateGroupDetailsContext(ctx context.Context, groupGUID string, prefs Group) (Group, error) {
 	payload, err := prefs.marshal()
 	if err != nil {
 		return Group{}, err
 	}
 
-	data, err := g.Call(fmt.Sprintf(groupDetailsEndpoint, groupGUID), http.MethodPatch, payload)
+	data, err := g.CallContext(ctx, fmt.Sprintf(groupDetailsEndpoint, groupGUID), http.MethodPatch, payload)
+	if err != nil {
+		return Group{}, err
+	}
+
+	return unmarshalGroupDetails(data)
+}
+
+// PatchGroupDetails is to update only the details of a specific group that are set in the Patch
+func (g *Groups) PatchGroupDetails(groupGUID string, patch *Patch) (Group, error) {
+	return g.PatchGroupDetailsContext(context.Background(), groupGUID, patch)
+}
+
+// PatchGroupDetailsContext is the same as PatchGroupDetails, but uses the context to control the lifetime of the request.
+func (g *Groups) PatchGroupDetailsContext(ctx context.Context, groupGUID string, patch *Patch) (Group, error) {
+	req := patchRequest{
+		Name:             patch.name,
+		OrganizationGUID: patch.organizationGUID,
+		Bsds:             patch.bsds,
+	}
+
+	payload, err := req.marshal()
+	if err != nil {
+		return Group{}, err
+	}
+
+	data, err := g.CallContext(ctx, fmt.Sprintf(groupDetailsEndpoint, groupGUID), http.MethodPatch, payload)
 	if err != nil {
 		return Group{}, err
 	}
@@ -95,12 +186,17 @@ func (g *Groups) UpdateGroupDetails(groupGUID string, prefs Group) (Group, error
 
 // UpdateGroupPreferences is to update preferences for a specific group
 func (g *Groups) UpdateGroupPreferences(groupGUID string, prefs BitlyGroupPreferences) (BitlyGroupPreferences, error) {
+	return g.UpdateGroupPreferencesContext(context.Background(), groupGUID, prefs)
+}
+
+// UpdateGroupPreferencesContext is the same as UpdateGroupPreferences, but uses the context to control the lifetime of the request.
+func (g *Groups) UpdateGroupPreferencesContext(ctx context.Context, groupGUID string, prefs BitlyGroupPreferences) (BitlyGroupPreferences, error) {
 	payload, err := prefs.marshal()
 	if err != nil {
 		return BitlyGroupPreferences{}, err
 	}
 
-	data, err := g.Call(fmt.Sprintf(groupPreferencesEndpoint, groupGUID), http.MethodPatch, payload)
+	data, err := g.CallContext(ctx, fmt.Sprintf(groupPreferencesEndpoint, groupGUID), http.MethodPatch, payload)
 	if err != nil {
 		return BitlyGroupPreferences{}, err
 	}
@@ -110,80 +206,19 @@ func (g *Groups) UpdateGroupPreferences(groupGUID string, prefs BitlyGroupPrefer
 
 // RetrieveBitlinksByGroup is to retrieve a paginated collection of Bitlinks for a Group
 func (g *Groups) RetrieveBitlinksByGroup(groupGUID string, input *BitlinksGroupRequest) (Bitlinks, error) {
-	v := url.Values{}
-
-	if input.Size != 0 {
-		v.Add("size", fmt.Sprintf("%d", input.Size))
-	}
-
-	if input.Page != 0 {
-		v.Add("page", fmt.Sprintf("%d", input.Page))
-	}
-
-	if input.CreatedBefore != 0 {
-		v.Add("created_before", fmt.Sprintf("%d", input.CreatedBefore))
-	}
-
-	if input.CreatedAfter != 0 {
-		v.Add("created_after", fmt.Sprintf("%d", input.CreatedAfter))
-	}
-
-	if input.ModifiedAfter != 0 {
-		v.Add("modified_after", fmt.Sprintf("%d", input.ModifiedAfter))
-	}
-
-	if len(input.Keyword) > 0 {
-		v.Add("keyword", input.Keyword)
-	}
-
-	if len(input.Query) > 0 {
-		v.Add("query", input.Query)
-	}
-
-	if len(input.Archived) > 0 {
-		v.Add("archived", input.Archived)
-	}
-
-	if len(input.Deeplinks) > 0 {
-		v.Add("deeplinks", input.Deeplinks)
-	}
-
-	if len(input.DomainDeeplinks) > 0 {
-		v.Add("domain_deeplinks", input.DomainDeeplinks)
-	}
-
-	if len(input.CampaignGUID) > 0 {
-		v.Add("campaign_guid", input.CampaignGUID)
-	}
-
-	if len(input.ChannelGUID) > 0 {
-		v.Add("channel_guid", input.ChannelGUID)
-	}
-
-	if len(input.CustomBitlinks) > 0 {
-		v.Add("custom_bitlink", input.CustomBitlinks)
-	}
-
-	if input.Tags != nil {
-		for idx := range input.Tags {
-			v.Add("tags", input.Tags[idx])
-		}
-	}
-
-	if input.EncodingLogin != nil {
-		for idx := range input.EncodingLogin {
-			v.Add("encoding_login", input.EncodingLogin[idx])
-		}
-	}
+	return g.RetrieveBitlinksByGroupContext(context.Background(), groupGUID, input)
+}
 
-	queryParams := v.Encode()
+// RetrieveBitlinksByGroupContext is the same as RetrieveBitlinksByGroup, but uses the context to control the lifetime of the request.
+func (g *Groups) RetrieveBitlinksByGroupContext(ctx context.Context, groupGUID string, input *BitlinksGroupRequest) (Bitlinks, error) {
+	queryParams := input.values().Encode()
 
 	url := fmt.Sprintf(bitlinksByGroupEndpoint, groupGUID)
 	if len(queryParams) > 1 {
 		url = fmt.Sprintf("%s?%s", url, queryParams)
 	}
 
-	data, err := g.Call(url, http.MethodGet, nil)
+	data, err := g.CallContext(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return Bitlinks{}, err
 	}
@@ -193,7 +228,12 @@ func (g *Groups) RetrieveBitlinksByGroup(groupGUID string, input *BitlinksGroupR
 
 // RetrieveTagsByGroup is to retrieve the currently used tags for a group
 func (g *Groups) RetrieveTagsByGroup(groupGUID string) (Tags, error) {
-	data, err := g.Call(fmt.Sprintf(tagsByGroupEndpoint, groupGUID), http.MethodGet, nil)
+	return g.RetrieveTagsByGroupContext(context.Background(), groupGUID)
+}
+
+// RetrieveTagsByGroupContext is the same as RetrieveTagsByGroup, but uses the context to control the lifetime of the request.
+func (g *Groups) RetrieveTagsByGroupContext(ctx context.Context, groupGUID string) (Tags, error) {
+	data, err := g.CallContext(ctx, fmt.Sprintf(tagsByGroupEndpoint, groupGUID), http.MethodGet, nil)
 	if err != nil {
 		return Tags{}, err
 	}
@@ -202,28 +242,43 @@ func (g *Groups) RetrieveTagsByGroup(groupGUID string) (Tags, error) {
 }
 
 // GetGroupClickMetricsByCountries will return metrics about the countries referring click traffic rolled up to a Group
-func (g *Groups) GetGroupClickMetricsByCountries(groupGUID string) (Metrics, error) {
-	data, err := g.Call(fmt.Sprintf(metricsByCountryEndpoint, groupGUID), http.MethodGet, nil)
-	if err != nil {
-		return Metrics{}, err
-	}
+func (g *Groups) GetGroupClickMetricsByCountries(groupGUID string, input *MetricsRequest) (Metrics, error) {
+	return g.GetGroupClickMetricsByCountriesContext(context.Background(), groupGUID, input)
+}
 
-	return unmarshalMetrics(data)
+// GetGroupClickMetricsByCountriesContext is the same as GetGroupClickMetricsByCountries, but uses the context to control the lifetime of the request.
+func (g *Groups) GetGroupClickMetricsByCountriesContext(ctx context.Context, groupGUID string, input *MetricsRequest) (Metrics, error) {
+	return g.getMetrics(ctx, fmt.Sprintf(metricsByCountryEndpoint, groupGUID), input)
 }
 
 // GetGroupClickMetricsByReferringNetworks will return metrics about the referring network click traffic rolled up to a Group
-func (g *Groups) GetGroupClickMetricsByReferringNetworks(groupGUID string) (Metrics, error) {
-	data, err := g.Call(fmt.Sprintf(metricsByReferrersEndpoint, groupGUID), http.MethodGet, nil)
-	if err != nil {
-		return Metrics{}, err
-	}
+func (g *Groups) GetGroupClickMetricsByReferringNetworks(groupGUID string, input *MetricsRequest) (Metrics, error) {
+	return g.GetGroupClickMetricsByReferringNetworksContext(context.Background(), groupGUID, input)
+}
 
-	return unmarshalMetrics(data)
+// GetGroupClickMetricsByReferringNetworksContext is the same as GetGroupClickMetricsByReferringNetworks, but uses the context to control the lifetime of the request.
+func (g *Groups) GetGroupClickMetricsByReferringNetworksContext(ctx context.Context, groupGUID string, input *MetricsRequest) (Metrics, error) {
+	return g.getMetrics(ctx, fmt.Sprintf(metricsByReferrersEndpoint, groupGUID), input)
 }
 
 // RetrieveGroupShortenCounts will get all the shorten counts for a specific group
-func (g *Groups) RetrieveGroupShortenCounts(groupGUID string) (Metrics, error) {
-	data, err := g.Call(fmt.Sprintf(groupShortenCountsEndpoint, groupGUID), http.MethodGet, nil)
+func (g *Groups) RetrieveGroupShortenCounts(groupGUID string, input *MetricsRequest) (Metrics, error) {
+	return g.RetrieveGroupShortenCountsContext(context.Background(), groupGUID, input)
+}
+
+// RetrieveGroupShortenCountsContext is the same as RetrieveGroupShortenCounts, but uses the context to control the lifetime of the request.
+func (g *Groups) RetrieveGroupShortenCountsContext(ctx context.Context, groupGUID string, input *MetricsRequest) (Metrics, error) {
+	return g.getMetrics(ctx, fmt.Sprintf(groupShortenCountsEndpoint, groupGUID), input)
+}
+
+// getMetrics retrieves the metrics from the endpoint, after validating the request.
+func (g *Groups) getMetrics(ctx context.Context, endpoint string, input *MetricsRequest) (Metrics, error) {
+	url, err := metrics.Encode(endpoint, input)
+	if err != nil {
+		return Metrics{}, err
+	}
+
+	data, err := g.CallContext(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return Metrics{}, err
 	}
@@ -233,32 +288,17 @@ func (g *Groups) RetrieveGroupShortenCounts(groupGUID string) (Metrics, error) {
 
 // RetrieveSortedBitlinksForGroup will retrieve a paginated response for Bitlinks that are sorted for the Group
 func (g *Groups) RetrieveSortedBitlinksForGroup(groupGUID string, input *SortedBitlinksGroupRequest) (Bitlinks, error) {
-	v := url.Values{}
-
-	if len(input.Unit) > 0 {
-		v.Add("unit", input.Unit)
-	}
-
-	if input.Units != 0 {
-		v.Add("units", fmt.Sprintf("%d", input.Units))
-	}
-
-	if len(input.UnitReference) > 0 {
-		v.Add("unit_reference", input.UnitReference)
-	}
-
-	if input.Size != 0 {
-		v.Add("size", fmt.Sprintf("%d", input.Size))
-	}
-
-	queryParams := v.Encode()
+	return g.RetrieveSortedBitlinksForGroupContext(context.Background(), groupGUID, input)
+}
 
-	url := fmt.Sprintf(sortedBitlinksEndpoint, groupGUID, input.SortType)
-	if len(queryParams) > 1 {
-		url = fmt.Sprintf("%s?%s", url, queryParams)
+// RetrieveSortedBitlinksForGroupContext is the same as RetrieveSortedBitlinksForGroup, but uses the context to control the lifetime of the request.
+func (g *Groups) RetrieveSortedBitlinksForGroupContext(ctx context.Context, groupGUID string, input *SortedBitlinksGroupRequest) (Bitlinks, error) {
+	url, err := metrics.Encode(fmt.Sprintf(sortedBitlinksEndpoint, groupGUID, input.SortType), &input.Request)
+	if err != nil {
+		return Bitlinks{}, err
 	}
 
-	data, err := g.Call(url, http.MethodGet, nil)
+	data, err := g.CallContext(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return Bitlinks{}, err
 	}
@@ -0,0 +1,133 @@
+package manifest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/custombitlinks"
+)
+
+// ErrCustomBackHalf is returned when a Bitlink with a custom back-half has to be created without a
+// CustomBitlinks service in the ApplyOptions, because Bitly creates Bitlinks with a random back-half.
+var ErrCustomBackHalf = errors.New("manifest: creating a Bitlink with a custom back-half requires the CustomBitlinks option")
+
+// ApplyOptions contains the options of applying a plan.
+type ApplyOptions struct {
+	// Don't change any Bitlink, but return the results as if the plan was applied
+	DryRun bool
+	// Continue with the next change when a change fails
+	ContinueOnError bool
+	// The service that adds the custom back-half to Bitlinks that are created. When nil, creating a
+	// Bitlink with a custom back-half fails with ErrCustomBackHalf.
+	CustomBitlinks custombitlinks.Service
+}
+
+// Result is the result of a single change of an applied plan.
+type Result struct {
+	Change Change
+	// The Bitlink after the change, which is empty for a dry run
+	Details bitlinks.BitlinkDetails
+	// Skipped is true when the change wasn't made, because of a dry run or an earlier error
+	Skipped bool
+	Err     error
+}
+
+// Apply makes the changes of the plan in order. It stops at the first error, unless ContinueOnError is set,
+// and returns a result for every change.
+func Apply(svc bitlinks.Service, plan *Plan, options *ApplyOptions) []Result {
+	return ApplyContext(context.Background(), svc, plan, options)
+}
+
+// ApplyContext is the same as Apply, but uses the context to control the lifetime of the requests.
+func ApplyContext(ctx context.Context, svc bitlinks.Service, plan *Plan, options *ApplyOptions) []Result {
+	if options == nil {
+		options = &ApplyOptions{}
+	}
+
+	results := make([]Result, len(plan.Changes))
+	failed := false
+
+	for idx, c := range plan.Changes {
+		results[idx].Change = c
+
+		if options.DryRun || (failed && !options.ContinueOnError) {
+			results[idx].Skipped = true
+			continue
+		}
+
+		results[idx].Details, results[idx].Err = apply(ctx, svc, options.CustomBitlinks, c)
+		if results[idx].Err != nil {
+			failed = true
+		}
+	}
+
+	return results
+}
+
+// apply makes a single change.
+func apply(ctx context.Context, svc bitlinks.Service, custom custombitlinks.Service, c Change) (bitlinks.BitlinkDetails, error) {
+	switch c.Action {
+	case ActionCreate:
+		if len(c.Desired.CustomBackHalf) > 0 && custom == nil {
+			return bitlinks.BitlinkDetails{}, ErrCustomBackHalf
+		}
+
+		details, err := svc.CreateBitlinkContext(ctx, &bitlinks.Bitlink{
+			LongURL:   c.Desired.LongURL,
+			Domain:    c.Desired.Domain,
+			GroupGUID: c.GroupGUID,
+			Title:     c.Desired.Title,
+			Tags:      c.Desired.Tags,
+		})
+		if err != nil || len(c.Desired.CustomBackHalf) == 0 {
+			return details, err
+		}
+
+		return addBackHalf(ctx, custom, details, c.Desired)
+	case ActionUpdate:
+		patch := bitlinks.NewPatch()
+		for _, d := range c.Diffs {
+			switch d.Field {
+			case "long_url":
+				patch.LongURL(c.Desired.LongURL)
+			case "title":
+				patch.Title(c.Desired.Title)
+			case "tags":
+				patch.Tags(c.Desired.Tags...)
+			case "archived":
+				patch.Archived(false)
+			}
+		}
+		return svc.PatchBitlinkContext(ctx, c.Bitlink, patch)
+	case ActionArchive:
+		return svc.ArchiveBitlinkContext(ctx, c.Bitlink)
+	}
+
+	return bitlinks.BitlinkDetails{}, errors.New("manifest: unknown action " + string(c.Action))
+}
+
+// addBackHalf adds the custom back-half of the link to the Bitlink that was created for it. The back-half is
+// added on the domain of the link, or on the domain of the Bitlink when the link has none.
+func addBackHalf(ctx context.Context, custom custombitlinks.Service, details bitlinks.BitlinkDetails, desired *Link) (bitlinks.BitlinkDetails, error) {
+	domain := desired.Domain
+	if len(domain) == 0 {
+		domain = strings.SplitN(details.ID, "/", 2)[0]
+	}
+
+	res, err := custom.AddCustomBitlinkContext(ctx, &custombitlinks.AddRequest{
+		CustomBitlink: fmt.Sprintf("%s/%s", domain, desired.CustomBackHalf),
+		BitlinkID:     details.ID,
+	})
+	if err != nil {
+		return details, fmt.Errorf("manifest: created %s, but adding the back-half %s failed: %s", details.ID, desired.CustomBackHalf, err.Error())
+	}
+
+	if len(res.Bitlink.ID) > 0 {
+		return res.Bitlink, nil
+	}
+	details.CustomBitlinks = append(details.CustomBitlinks, res.CustomBitlink)
+	return details, nil
+}
@@ -0,0 +1,142 @@
+// Package manifest reconciles the Bitlinks in Bitly with a manifest of the desired Bitlinks. A plan is made
+// by comparing the manifest with the Bitlinks of the groups, and the plan can then be applied.
+//
+// Manifests are written in JSON or YAML:
+//
+//	{
+//	  "group_guid": "Ba1bc23dE4F",
+//	  "domain": "bit.ly",
+//	  "prune": false,
+//	  "links": [
+//	    {"long_url": "https://example.org/launch", "title": "Launch", "tags": ["campaign"]},
+//	    {"long_url": "https://example.org/sale", "custom_back_half": "sale2019"}
+//	  ]
+//	}
+//
+// This module has no dependencies outside the standard library, so YAML manifests are read by a parser for
+// the subset of YAML that manifests need, see ParseYAML.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Manifest contains the desired Bitlinks.
+type Manifest struct {
+	// The group of the links that don't set a group
+	GroupGUID string `json:"group_guid"`
+	// The domain of the links that don't set a domain, defaults to the domain of the group
+	Domain string `json:"domain,omitempty"`
+	// Archive the Bitlinks of the groups in the manifest that aren't in the manifest
+	Prune bool `json:"prune,omitempty"`
+	// The desired Bitlinks
+	Links []Link `json:"links"`
+}
+
+// Link is a desired Bitlink. The title and tags are only compared when they are set, so Bitlinks can be
+// managed partially.
+type Link struct {
+	LongURL        string   `json:"long_url"`
+	Title          string   `json:"title,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	CustomBackHalf string   `json:"custom_back_half,omitempty"`
+	GroupGUID      string   `json:"group_guid,omitempty"`
+	Domain         string   `json:"domain,omitempty"`
+}
+
+// Load reads and validates the manifest in the file. Files with a .yaml or .yml extension are read as YAML,
+// all other files as JSON.
+func Load(path string) (*Manifest, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return ParseYAML(file)
+	}
+	return Parse(file)
+}
+
+// Parse reads and validates a manifest. Unknown fields are rejected, so typos don't go unnoticed. The group
+// and domain of the manifest are copied to the links that don't set them.
+func Parse(r io.Reader) (*Manifest, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("manifest: %s", err.Error())
+	}
+
+	for idx := range m.Links {
+		if len(m.Links[idx].GroupGUID) == 0 {
+			m.Links[idx].GroupGUID = m.GroupGUID
+		}
+		if len(m.Links[idx].Domain) == 0 {
+			m.Links[idx].Domain = m.Domain
+		}
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// Validate checks that all links have a valid long url and a group, and that no link is in the manifest twice.
+func (m *Manifest) Validate() error {
+	seen := make(map[string]int)
+
+	for idx, l := range m.Links {
+		u, err := url.Parse(l.LongURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+			return fmt.Errorf("manifest: links[%d]: %q is not a valid http or https url", idx, l.LongURL)
+		}
+
+		if len(l.GroupGUID) == 0 {
+			return fmt.Errorf("manifest: links[%d]: no group_guid set on the link or the manifest", idx)
+		}
+
+		if strings.Contains(l.CustomBackHalf, "/") {
+			return fmt.Errorf("manifest: links[%d]: custom_back_half %q can't contain a /", idx, l.CustomBackHalf)
+		}
+
+		if prev, dup := seen[l.key()]; dup {
+			return fmt.Errorf("manifest: links[%d]: duplicate of links[%d]", idx, prev)
+		}
+		seen[l.key()] = idx
+	}
+
+	return nil
+}
+
+// key identifies a link in the manifest. Links with a custom back-half are identified by it, so their long
+// url can change, and all other links by their long url.
+func (l Link) key() string {
+	if len(l.CustomBackHalf) > 0 {
+		return fmt.Sprintf("%s\x00%s\x00%s", l.GroupGUID, l.Domain, l.CustomBackHalf)
+	}
+	return fmt.Sprintf("%s\x00\x00%s", l.GroupGUID, l.LongURL)
+}
+
+// name returns the name of the link as shown in the plan.
+func (l Link) name() string {
+	if len(l.CustomBackHalf) > 0 {
+		domain := l.Domain
+		if len(domain) == 0 {
+			domain = "*"
+		}
+		return domain + "/" + l.CustomBackHalf
+	}
+	return l.LongURL
+}
@@ -0,0 +1,306 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/groups"
+)
+
+// Action is the change a plan makes to a Bitlink.
+type Action string
+
+const (
+	// ActionCreate creates a new Bitlink
+	ActionCreate Action = "create"
+	// ActionUpdate updates the fields of an existing Bitlink
+	ActionUpdate Action = "update"
+	// ActionArchive archives a Bitlink that isn't in the manifest
+	ActionArchive Action = "archive"
+)
+
+// Diff is a field of a Bitlink that changes.
+type Diff struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new"`
+}
+
+// Change is a single change to a Bitlink.
+type Change struct {
+	Action    Action `json:"action"`
+	GroupGUID string `json:"group_guid"`
+	// The ID of the existing Bitlink, which is empty when the Bitlink is created
+	Bitlink string `json:"bitlink,omitempty"`
+	// The desired Bitlink, which is nil when the Bitlink is archived
+	Desired *Link  `json:"desired,omitempty"`
+	Diffs   []Diff `json:"diffs,omitempty"`
+}
+
+// Plan contains the changes that are needed to make the Bitlinks match the manifest.
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// NewPlan compares the manifest with all Bitlinks, including the archived ones, of the groups in the
+// manifest and returns the changes that are needed to make them match.
+func NewPlan(svc groups.Service, m *Manifest) (*Plan, error) {
+	return NewPlanContext(context.Background(), svc, m)
+}
+
+// NewPlanContext is the same as NewPlan, but uses the context to control the lifetime of the requests.
+func NewPlanContext(ctx context.Context, svc groups.Service, m *Manifest) (*Plan, error) {
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Retrieve the Bitlinks of every group once, in the order the groups appear in the manifest
+	var groupGUIDs []string
+	current := make(map[string][]groups.Link)
+	for _, l := range m.Links {
+		if _, ok := current[l.GroupGUID]; ok {
+			continue
+		}
+
+		it := groups.NewBitlinkIterator(ctx, svc, l.GroupGUID, &groups.BitlinksGroupRequest{Size: 100, Archived: "both"}, 0)
+		links, err := it.All()
+		if err != nil {
+			return nil, fmt.Errorf("manifest: retrieving the Bitlinks of group %s: %s", l.GroupGUID, err.Error())
+		}
+
+		groupGUIDs = append(groupGUIDs, l.GroupGUID)
+		current[l.GroupGUID] = links
+	}
+
+	plan := &Plan{Changes: []Change{}}
+	matched := make(map[string]bool)
+
+	for idx := range m.Links {
+		desired := m.Links[idx]
+		existing := match(desired, current[desired.GroupGUID], matched)
+
+		if existing == nil {
+			plan.Changes = append(plan.Changes, Change{
+				Action:    ActionCreate,
+				GroupGUID: desired.GroupGUID,
+				Desired:   &desired,
+				Diffs:     createDiffs(desired),
+			})
+			continue
+		}
+
+		matched[existing.ID] = true
+		if diffs := updateDiffs(desired, existing); len(diffs) > 0 {
+			plan.Changes = append(plan.Changes, Change{
+				Action:    ActionUpdate,
+				GroupGUID: desired.GroupGUID,
+				Bitlink:   existing.ID,
+				Desired:   &desired,
+				Diffs:     diffs,
+			})
+		}
+	}
+
+	if m.Prune {
+		for _, groupGUID := range groupGUIDs {
+			var ids []string
+			for _, l := range current[groupGUID] {
+				if !l.Archived && !matched[l.ID] {
+					ids = append(ids, l.ID)
+				}
+			}
+			sort.Strings(ids)
+
+			for _, id := range ids {
+				plan.Changes = append(plan.Changes, Change{
+					Action:    ActionArchive,
+					GroupGUID: groupGUID,
+					Bitlink:   id,
+					Diffs:     []Diff{{Field: "archived", Old: "false", New: "true"}},
+				})
+			}
+		}
+	}
+
+	return plan, nil
+}
+
+// match returns the existing Bitlink for the desired link, or nil if there is none. Bitlinks that are
+// already matched to another link are skipped, and Bitlinks that aren't archived are preferred.
+func match(desired Link, links []groups.Link, matched map[string]bool) *groups.Link {
+	var found *groups.Link
+
+	for idx := range links {
+		l := &links[idx]
+		if matched[l.ID] {
+			continue
+		}
+
+		if len(desired.CustomBackHalf) > 0 {
+			if !hasBackHalf(l, desired.Domain, desired.CustomBackHalf) {
+				continue
+			}
+		} else if l.LongURL != desired.LongURL {
+			continue
+		}
+
+		if !l.Archived {
+			return l
+		}
+		if found == nil {
+			found = l
+		}
+	}
+
+	return found
+}
+
+// hasBackHalf returns true if the Bitlink, or one of its custom Bitlinks, ends in the back-half. When the
+// domain is empty the back-half matches on any domain.
+func hasBackHalf(l *groups.Link, domain string, backHalf string) bool {
+	for _, id := range append([]string{l.ID}, l.CustomBitlinks...) {
+		id = client.TrimScheme(id)
+
+		slash := strings.Index(id, "/")
+		if slash < 0 || id[slash+1:] != backHalf {
+			continue
+		}
+		if len(domain) == 0 || id[:slash] == domain {
+			return true
+		}
+	}
+
+	return false
+}
+
+// createDiffs returns the fields of a Bitlink that is created.
+func createDiffs(desired Link) []Diff {
+	diffs := []Diff{{Field: "long_url", New: desired.LongURL}}
+
+	if len(desired.Domain) > 0 {
+		diffs = append(diffs, Diff{Field: "domain", New: desired.Domain})
+	}
+	if len(desired.CustomBackHalf) > 0 {
+		diffs = append(diffs, Diff{Field: "custom_back_half", New: desired.CustomBackHalf})
+	}
+	if len(desired.Title) > 0 {
+		diffs = append(diffs, Diff{Field: "title", New: desired.Title})
+	}
+	if desired.Tags != nil {
+		diffs = append(diffs, Diff{Field: "tags", New: formatTags(desired.Tags)})
+	}
+
+	return diffs
+}
+
+// updateDiffs returns the fields of the existing Bitlink that don't match the desired link.
+func updateDiffs(desired Link, existing *groups.Link) []Diff {
+	var diffs []Diff
+
+	if existing.LongURL != desired.LongURL {
+		diffs = append(diffs, Diff{Field: "long_url", Old: existing.LongURL, New: desired.LongURL})
+	}
+	if len(desired.Title) > 0 && existing.Title != desired.Title {
+		diffs = append(diffs, Diff{Field: "title", Old: existing.Title, New: desired.Title})
+	}
+	if desired.Tags != nil && formatTags(existing.Tags) != formatTags(desired.Tags) {
+		diffs = append(diffs, Diff{Field: "tags", Old: formatTags(existing.Tags), New: formatTags(desired.Tags)})
+	}
+	if existing.Archived {
+		diffs = append(diffs, Diff{Field: "archived", Old: "true", New: "false"})
+	}
+
+	return diffs
+}
+
+// formatTags returns the tags as a sorted list, so the order of the tags doesn't cause a change.
+func formatTags(tags []string) string {
+	sorted := append([]string{}, tags...)
+	sort.Strings(sorted)
+
+	quoted := make([]string, len(sorted))
+	for idx, t := range sorted {
+		quoted[idx] = fmt.Sprintf("%q", t)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// HasChanges returns true if applying the plan changes any Bitlink.
+func (p *Plan) HasChanges() bool {
+	return len(p.Changes) > 0
+}
+
+// Count returns the number of changes with the action.
+func (p *Plan) Count(action Action) int {
+	count := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Print writes the plan in a human readable format, like:
+//
+//	  # https://example.org/launch will be created in group Ba1bc23dE4F
+//	  + create
+//	      long_url: "https://example.org/launch"
+//
+//	  # bit.ly/2VNw1Ib will be updated in-place
+//	  ~ update bit.ly/2VNw1Ib
+//	      title: "Sale" -> "Summer sale"
+//
+//	Plan: 1 to create, 1 to update, 0 to archive.
+func (p *Plan) Print(w io.Writer) error {
+	if !p.HasChanges() {
+		_, err := fmt.Fprintln(w, "No changes. The Bitlinks match the manifest.")
+		return err
+	}
+
+	for _, c := range p.Changes {
+		var err error
+		switch c.Action {
+		case ActionCreate:
+			_, err = fmt.Fprintf(w, "  # %s will be created in group %s\n  + create\n", c.Desired.name(), c.GroupGUID)
+		case ActionUpdate:
+			_, err = fmt.Fprintf(w, "  # %s will be updated in-place\n  ~ update %s\n", c.Bitlink, c.Bitlink)
+		case ActionArchive:
+			_, err = fmt.Fprintf(w, "  # %s will be archived\n  - archive %s\n", c.Bitlink, c.Bitlink)
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, d := range c.Diffs {
+			if c.Action == ActionUpdate {
+				_, err = fmt.Fprintf(w, "      %s: %s -> %s\n", d.Field, quote(d.Field, d.Old), quote(d.Field, d.New))
+			} else if c.Action == ActionCreate {
+				_, err = fmt.Fprintf(w, "      %s: %s\n", d.Field, quote(d.Field, d.New))
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "Plan: %d to create, %d to update, %d to archive.\n",
+		p.Count(ActionCreate), p.Count(ActionUpdate), p.Count(ActionArchive))
+	return err
+}
+
+// quote quotes the value of a field, unless it is already formatted.
+func quote(field string, value string) string {
+	if field == "tags" || field == "archived" {
+		return value
+	}
+	return fmt.Sprintf("%q", value)
+}
@@ -0,0 +1,358 @@
+package manifest_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/custombitlinks"
+	"github.com/retgits/bitly/client/groups"
+	"github.com/retgits/bitly/client/manifest"
+)
+
+// parse reads the manifest like Load does, so the group and domain are copied to the links.
+func parse(t *testing.T, m manifest.Manifest) *manifest.Manifest {
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := manifest.Parse(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestPlanApplyConverges(t *testing.T) {
+	const group = bitlytest.DefaultGroupGUID
+
+	tests := []struct {
+		name     string
+		existing []bitlinks.BitlinkDetails
+		manifest manifest.Manifest
+		creates  int
+		updates  int
+		archives int
+		check    func(t *testing.T, srv *bitlytest.Server)
+	}{
+		{
+			name: "create",
+			manifest: manifest.Manifest{GroupGUID: group, Links: []manifest.Link{
+				{LongURL: "https://example.org/a", Title: "A", Tags: []string{"x"}},
+				{LongURL: "https://example.org/b"},
+			}},
+			creates: 2,
+		},
+		{
+			name:     "nothing to do",
+			existing: []bitlinks.BitlinkDetails{{ID: "bit.ly/a", LongURL: "https://example.org/a", Title: "A", Tags: []string{"y", "x"}}},
+			manifest: manifest.Manifest{GroupGUID: group, Links: []manifest.Link{
+				{LongURL: "https://example.org/a", Title: "A", Tags: []string{"x", "y"}},
+			}},
+		},
+		{
+			name:     "update title and tags",
+			existing: []bitlinks.BitlinkDetails{{ID: "bit.ly/a", LongURL: "https://example.org/a", Title: "Old", Tags: []string{"old"}}},
+			manifest: manifest.Manifest{GroupGUID: group, Links: []manifest.Link{
+				{LongURL: "https://example.org/a", Title: "New", Tags: []string{"new"}},
+			}},
+			updates: 1,
+			check: func(t *testing.T, srv *bitlytest.Server) {
+				details, _ := srv.Bitlink("bit.ly/a")
+				if details.Title != "New" || strings.Join(details.Tags, ",") != "new" {
+					t.Errorf("bit.ly/a = %+v", details)
+				}
+			},
+		},
+		{
+			name:     "title and tags that aren't set are left alone",
+			existing: []bitlinks.BitlinkDetails{{ID: "bit.ly/a", LongURL: "https://example.org/a", Title: "Kept", Tags: []string{"kept"}}},
+			manifest: manifest.Manifest{GroupGUID: group, Links: []manifest.Link{
+				{LongURL: "https://example.org/a"},
+			}},
+		},
+		{
+			name:     "unarchive",
+			existing: []bitlinks.BitlinkDetails{{ID: "bit.ly/a", LongURL: "https://example.org/a", Archived: true}},
+			manifest: manifest.Manifest{GroupGUID: group, Links: []manifest.Link{
+				{LongURL: "https://example.org/a"},
+			}},
+			updates: 1,
+			check: func(t *testing.T, srv *bitlytest.Server) {
+				if details, _ := srv.Bitlink("bit.ly/a"); details.Archived {
+					t.Errorf("bit.ly/a is still archived")
+				}
+			},
+		},
+		{
+			name: "prefer the Bitlink that isn't archived",
+			existing: []bitlinks.BitlinkDetails{
+				{ID: "bit.ly/old", LongURL: "https://example.org/a", Archived: true},
+				{ID: "bit.ly/new", LongURL: "https://example.org/a"},
+			},
+			manifest: manifest.Manifest{GroupGUID: group, Links: []manifest.Link{
+				{LongURL: "https://example.org/a"},
+			}},
+		},
+		{
+			name: "prune",
+			existing: []bitlinks.BitlinkDetails{
+				{ID: "bit.ly/keep", LongURL: "https://example.org/keep"},
+				{ID: "bit.ly/drop", LongURL: "https://example.org/drop"},
+				{ID: "bit.ly/gone", LongURL: "https://example.org/gone", Archived: true},
+			},
+			manifest: manifest.Manifest{GroupGUID: group, Prune: true, Links: []manifest.Link{
+				{LongURL: "https://example.org/keep"},
+			}},
+			archives: 1,
+			check: func(t *testing.T, srv *bitlytest.Server) {
+				if details, _ := srv.Bitlink("bit.ly/drop"); !details.Archived {
+					t.Errorf("bit.ly/drop isn't archived")
+				}
+				if details, _ := srv.Bitlink("bit.ly/keep"); details.Archived {
+					t.Errorf("bit.ly/keep is archived")
+				}
+			},
+		},
+		{
+			name: "without prune other Bitlinks are left alone",
+			existing: []bitlinks.BitlinkDetails{
+				{ID: "bit.ly/other", LongURL: "https://example.org/other"},
+			},
+			manifest: manifest.Manifest{GroupGUID: group, Links: []manifest.Link{
+				{LongURL: "https://example.org/a"},
+			}},
+			creates: 1,
+		},
+		{
+			name: "create with a custom back-half",
+			manifest: manifest.Manifest{GroupGUID: group, Domain: bitlytest.DefaultDomain, Links: []manifest.Link{
+				{LongURL: "https://example.org/sale", CustomBackHalf: "sale2019"},
+			}},
+			creates: 1,
+			check: func(t *testing.T, srv *bitlytest.Server) {
+				res, err := custombitlinks.New(srv.Client()).RetrieveCustomBitlink("bit.ly/sale2019")
+				if err != nil {
+					t.Fatalf("RetrieveCustomBitlink() returned %s", err.Error())
+				}
+				if res.Bitlink.LongURL != "https://example.org/sale" {
+					t.Errorf("bit.ly/sale2019 redirects to %s", res.Bitlink.LongURL)
+				}
+			},
+		},
+		{
+			name:     "change the long url of a custom back-half",
+			existing: []bitlinks.BitlinkDetails{{ID: "bit.ly/sale2019", LongURL: "https://example.org/old"}},
+			manifest: manifest.Manifest{GroupGUID: group, Links: []manifest.Link{
+				{LongURL: "https://example.org/new", CustomBackHalf: "sale2019"},
+			}},
+			updates: 1,
+			check: func(t *testing.T, srv *bitlytest.Server) {
+				if details, _ := srv.Bitlink("bit.ly/sale2019"); details.LongURL != "https://example.org/new" {
+					t.Errorf("bit.ly/sale2019 redirects to %s", details.LongURL)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			for _, details := range tt.existing {
+				srv.AddBitlink(group, details)
+			}
+
+			c := srv.Client()
+			m := parse(t, tt.manifest)
+			plan, err := manifest.NewPlan(groups.New(c), m)
+			if err != nil {
+				t.Fatalf("NewPlan() returned %s", err.Error())
+			}
+
+			if got := plan.Count(manifest.ActionCreate); got != tt.creates {
+				t.Errorf("plan creates %d Bitlinks, want %d", got, tt.creates)
+			}
+			if got := plan.Count(manifest.ActionUpdate); got != tt.updates {
+				t.Errorf("plan updates %d Bitlinks, want %d", got, tt.updates)
+			}
+			if got := plan.Count(manifest.ActionArchive); got != tt.archives {
+				t.Errorf("plan archives %d Bitlinks, want %d", got, tt.archives)
+			}
+
+			for _, res := range manifest.Apply(bitlinks.New(c), plan, &manifest.ApplyOptions{CustomBitlinks: custombitlinks.New(c)}) {
+				if res.Err != nil {
+					t.Fatalf("applying %s %s failed: %s", res.Change.Action, res.Change.Bitlink, res.Err.Error())
+				}
+			}
+
+			if tt.check != nil {
+				tt.check(t, srv)
+			}
+
+			// Once applied, the Bitlinks match the manifest
+			again, err := manifest.NewPlan(groups.New(c), m)
+			if err != nil {
+				t.Fatalf("NewPlan() returned %s", err.Error())
+			}
+			if again.HasChanges() {
+				var buf bytes.Buffer
+				again.Print(&buf)
+				t.Errorf("the plan after applying has changes:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestApplyOptions(t *testing.T) {
+	m := parse(t, manifest.Manifest{GroupGUID: bitlytest.DefaultGroupGUID, Links: []manifest.Link{
+		{LongURL: "https://example.org/a", CustomBackHalf: "a2019"},
+		{LongURL: "https://example.org/b"},
+	}})
+
+	tests := []struct {
+		name     string
+		options  *manifest.ApplyOptions
+		failed   int
+		skipped  int
+		bitlinks int
+	}{
+		{name: "dry run", options: &manifest.ApplyOptions{DryRun: true}, skipped: 2},
+		{name: "stop at the first error", options: &manifest.ApplyOptions{}, failed: 1, skipped: 1},
+		{name: "continue on error", options: &manifest.ApplyOptions{ContinueOnError: true}, failed: 1, bitlinks: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			c := srv.Client()
+
+			plan, err := manifest.NewPlan(groups.New(c), m)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var failed, skipped int
+			for _, res := range manifest.Apply(bitlinks.New(c), plan, tt.options) {
+				if res.Err != nil {
+					failed++
+					if res.Err != manifest.ErrCustomBackHalf {
+						t.Errorf("Apply() failed with %s, want %s", res.Err.Error(), manifest.ErrCustomBackHalf.Error())
+					}
+				}
+				if res.Skipped {
+					skipped++
+				}
+			}
+
+			if failed != tt.failed || skipped != tt.skipped {
+				t.Errorf("%d changes failed and %d were skipped, want %d and %d", failed, skipped, tt.failed, tt.skipped)
+			}
+
+			links, err := groups.New(c).IterateBitlinksByGroup(bitlytest.DefaultGroupGUID, nil, 0).All()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(links) != tt.bitlinks {
+				t.Errorf("the group has %d Bitlinks, want %d", len(links), tt.bitlinks)
+			}
+		})
+	}
+}
+
+func TestParseValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{name: "valid", json: `{"group_guid":"g","links":[{"long_url":"https://example.org"}]}`},
+		{name: "link group", json: `{"links":[{"long_url":"https://example.org","group_guid":"g"}]}`},
+		{name: "unknown field", json: `{"group_guid":"g","links":[{"long_url":"https://example.org","titel":"x"}]}`, wantErr: "unknown field"},
+		{name: "invalid url", json: `{"group_guid":"g","links":[{"long_url":"example.org"}]}`, wantErr: "is not a valid http or https url"},
+		{name: "no group", json: `{"links":[{"long_url":"https://example.org"}]}`, wantErr: "no group_guid set"},
+		{name: "slash in back-half", json: `{"group_guid":"g","links":[{"long_url":"https://example.org","custom_back_half":"a/b"}]}`, wantErr: "can't contain a /"},
+		{name: "duplicate url", json: `{"group_guid":"g","links":[{"long_url":"https://example.org"},{"long_url":"https://example.org"}]}`, wantErr: "links[1]: duplicate of links[0]"},
+		{
+			name: "same url with other back-halves",
+			json: `{"group_guid":"g","links":[{"long_url":"https://example.org","custom_back_half":"a"},{"long_url":"https://example.org","custom_back_half":"b"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manifest.Parse(strings.NewReader(tt.json))
+			switch {
+			case len(tt.wantErr) == 0 && err != nil:
+				t.Errorf("Parse() returned %s", err.Error())
+			case len(tt.wantErr) > 0 && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Parse() = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlanPrint(t *testing.T) {
+	tests := []struct {
+		name string
+		plan *manifest.Plan
+		want string
+	}{
+		{
+			name: "no changes",
+			plan: &manifest.Plan{},
+			want: "No changes. The Bitlinks match the manifest.\n",
+		},
+		{
+			name: "changes",
+			plan: &manifest.Plan{Changes: []manifest.Change{
+				{
+					Action:    manifest.ActionCreate,
+					GroupGUID: "g",
+					Desired:   &manifest.Link{LongURL: "https://example.org/launch"},
+					Diffs:     []manifest.Diff{{Field: "long_url", New: "https://example.org/launch"}, {Field: "tags", New: `["a"]`}},
+				},
+				{
+					Action:  manifest.ActionUpdate,
+					Bitlink: "bit.ly/2VNw1Ib",
+					Diffs:   []manifest.Diff{{Field: "title", Old: "Sale", New: "Summer sale"}},
+				},
+				{
+					Action:  manifest.ActionArchive,
+					Bitlink: "bit.ly/old",
+					Diffs:   []manifest.Diff{{Field: "archived", Old: "false", New: "true"}},
+				},
+			}},
+			want: "  # https://example.org/launch will be created in group g\n" +
+				"  + create\n" +
+				"      long_url: \"https://example.org/launch\"\n" +
+				"      tags: [\"a\"]\n" +
+				"\n" +
+				"  # bit.ly/2VNw1Ib will be updated in-place\n" +
+				"  ~ update bit.ly/2VNw1Ib\n" +
+				"      title: \"Sale\" -> \"Summer sale\"\n" +
+				"\n" +
+				"  # bit.ly/old will be archived\n" +
+				"  - archive bit.ly/old\n" +
+				"\n" +
+				"Plan: 1 to create, 1 to update, 1 to archive.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.plan.Print(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Print() wrote\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
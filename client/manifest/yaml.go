@@ -0,0 +1,450 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ParseYAML reads and validates a manifest written in YAML. Only the subset of YAML needed for manifests is
+// supported: block mappings and sequences, flow sequences like [a, b] and flow mappings like {a: b} on a
+// single line, quoted and plain scalars, and comments. Plain scalars are strings, except for null and ~, and
+// the booleans of YAML 1.1 (true, false, yes, no, on and off) in fields like prune. Anchors, tags, block
+// scalars and multiple documents aren't supported, and give an error.
+//
+//	group_guid: Ba1bc23dE4F
+//	prune: yes
+//	links:
+//	  - long_url: https://example.org/launch
+//	    title: Launch
+//	    tags: [campaign]
+//	  - {long_url: https://example.org/sale, custom_back_half: sale2019}
+func ParseYAML(r io.Reader) (*Manifest, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := yamlLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &yamlParser{lines: lines}
+	v, err := p.node(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, p.errorf(p.lines[p.pos], "unexpected indentation")
+	}
+	if v == nil {
+		v = map[string]interface{}{}
+	}
+
+	js, err := json.Marshal(resolve(v, reflect.TypeOf(Manifest{})))
+	if err != nil {
+		return nil, fmt.Errorf("manifest: %s", err.Error())
+	}
+	return Parse(bytes.NewReader(js))
+}
+
+// yamlLine is a line of a YAML document without its indentation and comment.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// yamlLines splits the document in lines, and removes comments, blank lines and document markers.
+func yamlLines(data []byte) ([]yamlLine, error) {
+	var lines []yamlLine
+
+	for idx, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, " \t\r")
+		text := strings.TrimLeft(raw, " ")
+		line := yamlLine{number: idx + 1, indent: len(raw) - len(text)}
+
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("manifest: line %d: tabs can't be used for indentation", line.number)
+		}
+
+		text = strings.TrimSpace(stripComment(text))
+		if len(text) == 0 || (line.indent == 0 && (text == "---" || text == "...")) {
+			continue
+		}
+		if strings.HasPrefix(text, "%") {
+			return nil, fmt.Errorf("manifest: line %d: directives aren't supported", line.number)
+		}
+
+		line.text = text
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// stripComment removes a comment, which starts with a # at the start of the line or after whitespace, outside
+// of quotes.
+func stripComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.IndexByte(" \t[{,", text[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// yamlParser turns the lines of a document into maps, slices and scalars.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (p *yamlParser) errorf(line yamlLine, format string, args ...interface{}) error {
+	return fmt.Errorf("manifest: line %d: %s", line.number, fmt.Sprintf(format, args...))
+}
+
+// node parses the mapping or sequence that starts at the current line, which must be indented at least
+// minIndent. It returns nil when there are no more lines at that indentation.
+func (p *yamlParser) node(minIndent int) (interface{}, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent < minIndent {
+		return nil, nil
+	}
+
+	line := p.lines[p.pos]
+	if isSequenceItem(line.text) {
+		return p.sequence(line.indent)
+	}
+	if _, _, ok := splitKey(line.text); ok {
+		return p.mapping(line.indent)
+	}
+
+	p.pos++
+	return p.scalar(line, line.text)
+}
+
+// mapping parses the keys at the indentation.
+func (p *yamlParser) mapping(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && !isSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		key, value, ok := splitKey(line.text)
+		if !ok {
+			return nil, p.errorf(line, "expected a key, like name: value")
+		}
+
+		k, err := p.scalar(line, key)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := k.(string)
+		if !ok {
+			return nil, p.errorf(line, "the key %s must be a string", key)
+		}
+		if _, ok := m[name]; ok {
+			return nil, p.errorf(line, "the key %s is used twice", name)
+		}
+		p.pos++
+
+		switch {
+		case len(value) > 0:
+			m[name], err = p.scalar(line, value)
+		case p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text):
+			// A sequence may have the same indentation as the key it belongs to
+			m[name], err = p.sequence(indent)
+		default:
+			m[name], err = p.node(indent + 1)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// sequence parses the items at the indentation.
+func (p *yamlParser) sequence(indent int) (interface{}, error) {
+	s := []interface{}{}
+
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		rest := strings.TrimLeft(line.text[1:], " ")
+
+		if len(rest) == 0 {
+			p.pos++
+			item, err := p.node(indent + 1)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, item)
+			continue
+		}
+
+		if _, _, ok := splitKey(rest); ok || isSequenceItem(rest) {
+			// The item is a mapping or sequence which starts on the line of the dash, so the line is parsed
+			// again as if it only contained the item, at the indentation of the item
+			p.lines[p.pos] = yamlLine{number: line.number, indent: indent + len(line.text) - len(rest), text: rest}
+			item, err := p.node(indent + 1)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, item)
+			continue
+		}
+
+		p.pos++
+		item, err := p.scalar(line, rest)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, item)
+	}
+
+	return s, nil
+}
+
+// scalar parses a quoted or plain scalar, or a flow sequence or mapping.
+func (p *yamlParser) scalar(line yamlLine, text string) (interface{}, error) {
+	switch text[0] {
+	case '[':
+		return p.flowSequence(line, text)
+	case '{':
+		return p.flowMapping(line, text)
+	case '&', '*', '!':
+		return nil, p.errorf(line, "anchors, aliases and tags aren't supported")
+	case '|', '>':
+		return nil, p.errorf(line, "block scalars aren't supported")
+	case '"':
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, p.errorf(line, "invalid double quoted string %s", text)
+		}
+		return s, nil
+	case '\'':
+		if len(text) < 2 || text[len(text)-1] != '\'' {
+			return nil, p.errorf(line, "invalid single quoted string %s", text)
+		}
+		inner := text[1 : len(text)-1]
+		if strings.Contains(strings.Replace(inner, "''", "", -1), "'") {
+			return nil, p.errorf(line, "invalid single quoted string %s", text)
+		}
+		return strings.Replace(inner, "''", "'", -1), nil
+	}
+
+	switch text {
+	case "true", "True", "TRUE", "yes", "Yes", "YES", "on", "On", "ON":
+		return yamlBool{value: true, text: text}, nil
+	case "false", "False", "FALSE", "no", "No", "NO", "off", "Off", "OFF":
+		return yamlBool{value: false, text: text}, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	return text, nil
+}
+
+// flowSequence parses a sequence in brackets, like [a, "b"].
+func (p *yamlParser) flowSequence(line yamlLine, text string) (interface{}, error) {
+	if text[len(text)-1] != ']' {
+		return nil, p.errorf(line, "the sequence %s must end with ]", text)
+	}
+
+	s := []interface{}{}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+	if len(inner) == 0 {
+		return s, nil
+	}
+
+	for _, item := range splitFlow(inner) {
+		item = strings.TrimSpace(item)
+		if len(item) == 0 {
+			return nil, p.errorf(line, "the sequence %s has an empty item", text)
+		}
+		v, err := p.scalar(line, item)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, v)
+	}
+
+	return s, nil
+}
+
+// flowMapping parses a mapping in braces, like {long_url: https://example.org, title: Launch}.
+func (p *yamlParser) flowMapping(line yamlLine, text string) (interface{}, error) {
+	if text[len(text)-1] != '}' {
+		return nil, p.errorf(line, "the mapping %s must end with }", text)
+	}
+
+	m := map[string]interface{}{}
+	inner := strings.TrimSpace(text[1 : len(text)-1])
+	if len(inner) == 0 {
+		return m, nil
+	}
+
+	for _, item := range splitFlow(inner) {
+		item = strings.TrimSpace(item)
+		key, value, ok := splitKey(item)
+		if !ok {
+			return nil, p.errorf(line, "expected a key, like name: value, in the mapping %s", text)
+		}
+
+		k, err := p.scalar(line, key)
+		if err != nil {
+			return nil, err
+		}
+		name, ok := k.(string)
+		if !ok {
+			return nil, p.errorf(line, "the key %s must be a string", key)
+		}
+		if _, ok := m[name]; ok {
+			return nil, p.errorf(line, "the key %s is used twice", name)
+		}
+
+		if len(value) == 0 {
+			m[name] = nil
+			continue
+		}
+		if m[name], err = p.scalar(line, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// splitFlow splits the items of a flow sequence or mapping on the commas outside of quotes and nested
+// sequences and mappings.
+func splitFlow(text string) []string {
+	var items []string
+	var quote byte
+	depth, start := 0, 0
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, text[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(items, text[start:])
+}
+
+// splitKey splits a line like key: value in the key and the value. The colon must be followed by a space or
+// end the line, so URLs aren't split.
+func splitKey(text string) (string, string, bool) {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case i == 0 && (c == '"' || c == '\''):
+			quote = c
+		case c == '[' || c == '{':
+			if i == 0 {
+				return "", "", false
+			}
+		case c == ':' && (i == len(text)-1 || text[i+1] == ' '):
+			key := strings.TrimSpace(text[:i])
+			if len(key) == 0 {
+				return "", "", false
+			}
+			return key, strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// isSequenceItem returns true if the line is an item of a block sequence.
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// yamlBool is a plain scalar that is a boolean in YAML 1.1. It only becomes a boolean for fields that are one,
+// so a title like no stays a string.
+type yamlBool struct {
+	value bool
+	text  string
+}
+
+// resolve replaces the booleans in v by a bool or their text, depending on the type of the field they are
+// decoded into.
+func resolve(v interface{}, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch v := v.(type) {
+	case yamlBool:
+		if t != nil && t.Kind() == reflect.String {
+			return v.text
+		}
+		return v.value
+	case []interface{}:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for idx := range v {
+			v[idx] = resolve(v[idx], elem)
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = resolve(v[key], fieldType(t, key))
+		}
+	}
+	return v
+}
+
+// fieldType returns the type of the field of struct t with the JSON name, or nil when there is no such field.
+func fieldType(t reflect.Type, name string) reflect.Type {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	for idx := 0; idx < t.NumField(); idx++ {
+		f := t.Field(idx)
+		if strings.Split(f.Tag.Get("json"), ",")[0] == name {
+			return f.Type
+		}
+	}
+	return nil
+}
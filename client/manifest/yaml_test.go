@@ -0,0 +1,172 @@
+package manifest_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/retgits/bitly/client/manifest"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		json string
+	}{
+		{
+			name: "block sequence of mappings",
+			yaml: `
+group_guid: Ba1bc23dE4F
+domain: bit.ly
+links:
+  - long_url: https://example.org/launch
+    title: Launch
+    tags: [campaign, "launch 2019"]
+  - long_url: https://example.org/sale
+    custom_back_half: sale2019
+`,
+			json: `{"group_guid":"Ba1bc23dE4F","domain":"bit.ly","links":[
+				{"long_url":"https://example.org/launch","title":"Launch","tags":["campaign","launch 2019"]},
+				{"long_url":"https://example.org/sale","custom_back_half":"sale2019"}]}`,
+		},
+		{
+			name: "sequence at the indentation of its key",
+			yaml: `
+group_guid: g
+links:
+- long_url: https://example.org/a
+  tags:
+  - one
+  - two
+`,
+			json: `{"group_guid":"g","links":[{"long_url":"https://example.org/a","tags":["one","two"]}]}`,
+		},
+		{
+			name: "dash on its own line",
+			yaml: `
+group_guid: g
+links:
+  -
+    long_url: https://example.org/a
+`,
+			json: `{"group_guid":"g","links":[{"long_url":"https://example.org/a"}]}`,
+		},
+		{
+			name: "comments, quotes and document markers",
+			yaml: `
+---
+# The production links
+group_guid: 'g' # the group
+prune: true
+links:
+  - long_url: "https://example.org/a#anchor"
+    title: 'It''s a "link"'
+    tags: []
+...
+`,
+			json: `{"group_guid":"g","prune":true,"links":[{"long_url":"https://example.org/a#anchor","title":"It's a \"link\"","tags":[]}]}`,
+		},
+		{
+			name: "plain scalars are strings",
+			yaml: `
+group_guid: 12345
+links:
+  - long_url: https://example.org/a
+    title: 3.14
+`,
+			json: `{"group_guid":"12345","links":[{"long_url":"https://example.org/a","title":"3.14"}]}`,
+		},
+		{
+			name: "flow mappings",
+			yaml: `
+{group_guid: g, domain: bit.ly, links: []}
+`,
+			json: `{"group_guid":"g","domain":"bit.ly","links":[]}`,
+		},
+		{
+			name: "flow mappings in a sequence",
+			yaml: `
+group_guid: g
+links:
+  - {long_url: https://example.org/a, title: "A, B", tags: [one, two]}
+  - {long_url: 'https://example.org/b', domain: }
+`,
+			json: `{"group_guid":"g","links":[
+				{"long_url":"https://example.org/a","title":"A, B","tags":["one","two"]},
+				{"long_url":"https://example.org/b"}]}`,
+		},
+		{
+			name: "YAML 1.1 booleans",
+			yaml: `
+group_guid: g
+prune: yes
+links:
+  - long_url: https://example.org/a
+    title: no
+    tags: [on, Off, TRUE]
+`,
+			json: `{"group_guid":"g","prune":true,"links":[{"long_url":"https://example.org/a","title":"no","tags":["on","Off","TRUE"]}]}`,
+		},
+		{
+			name: "null",
+			yaml: `
+group_guid: g
+domain: ~
+links:
+  - long_url: https://example.org/a
+    title: null
+`,
+			json: `{"group_guid":"g","links":[{"long_url":"https://example.org/a"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := manifest.ParseYAML(strings.NewReader(tt.yaml))
+			if err != nil {
+				t.Fatalf("ParseYAML() returned %s", err.Error())
+			}
+
+			want, err := manifest.Parse(strings.NewReader(tt.json))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ParseYAML() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{name: "tabs", yaml: "group_guid: g\nlinks:\n\t- long_url: https://example.org", wantErr: "line 3: tabs can't be used for indentation"},
+		{name: "flow mapping over lines", yaml: "group_guid: g\nlinks:\n  - {long_url: https://example.org,\n     title: Launch}", wantErr: "line 3: the mapping {long_url: https://example.org, must end with }"},
+		{name: "flow mapping without a key", yaml: "group_guid: g\nlinks: [{https://example.org}]", wantErr: "line 2: expected a key, like name: value, in the mapping"},
+		{name: "boolean for a string", yaml: "group_guid: g\nprune: maybe\nlinks: []", wantErr: "cannot unmarshal string"},
+		{name: "anchor", yaml: "group_guid: &g g\nlinks: []", wantErr: "line 1: anchors, aliases and tags aren't supported"},
+		{name: "block scalar", yaml: "group_guid: g\nlinks:\n  - long_url: https://example.org\n    title: |\n      Launch", wantErr: "line 4: block scalars aren't supported"},
+		{name: "duplicate key", yaml: "group_guid: g\ngroup_guid: h\nlinks: []", wantErr: "line 2: the key group_guid is used twice"},
+		{name: "unexpected indentation", yaml: "group_guid: g\n  domain: bit.ly\nlinks: []", wantErr: "line 2: unexpected indentation"},
+		{name: "unterminated quote", yaml: "group_guid: \"g\nlinks: []", wantErr: "line 1: invalid double quoted string"},
+		{name: "unterminated flow sequence", yaml: "group_guid: g\nlinks:\n  - long_url: https://example.org\n    tags: [a, b", wantErr: "line 4: the sequence [a, b must end with ]"},
+		{name: "directive", yaml: "%YAML 1.2\ngroup_guid: g", wantErr: "line 1: directives aren't supported"},
+		{name: "unknown field", yaml: "group_guid: g\nlinks:\n  - long_url: https://example.org\n    titel: Launch", wantErr: "unknown field"},
+		{name: "invalid manifest", yaml: "links:\n  - long_url: https://example.org", wantErr: "no group_guid set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manifest.ParseYAML(strings.NewReader(tt.yaml))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseYAML() = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Package metrics contains the request parameters shared by all metrics endpoints in Bitly
+package metrics
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/retgits/bitly"
+	"github.com/retgits/bitly/client"
+)
+
+const (
+	// UnitReferenceFormat is the ISO-8601 format in which the UnitReference is sent to Bitly
+	UnitReferenceFormat = bitly.TimeFormat
+)
+
+// Unit is a unit of time for which Bitly returns metrics
+type Unit string
+
+const (
+	// UnitMinute returns metrics per minute
+	UnitMinute Unit = "minute"
+	// UnitHour returns metrics per hour
+	UnitHour Unit = "hour"
+	// UnitDay returns metrics per day
+	UnitDay Unit = "day"
+	// UnitWeek returns metrics per week
+	UnitWeek Unit = "week"
+	// UnitMonth returns metrics per month
+	UnitMonth Unit = "month"
+)
+
+// Units contains all units of time supported by Bitly
+var Units = []Unit{UnitMinute, UnitHour, UnitDay, UnitWeek, UnitMonth}
+
+// Valid returns true if Bitly supports the unit of time.
+func (u Unit) Valid() bool {
+	for _, v := range Units {
+		if u == v {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseUnit returns the Unit for a string, like "day", or an error if Bitly doesn't support it.
+func ParseUnit(s string) (Unit, error) {
+	u := Unit(strings.ToLower(strings.TrimSpace(s)))
+	if !u.Valid() {
+		return "", &ValidationError{Field: "unit", Message: fmt.Sprintf("%q is not one of %s", s, joinUnits())}
+	}
+	return u, nil
+}
+
+// Request is used to generate the metrics request to Bitly
+type Request struct {
+	// A unit of time
+	Unit Unit
+	// An integer representing the time units to query data for. pass -1 to return all units of time.
+	Units int
+	// The most recent time for which to pull metrics. Will default to current time.
+	UnitReference time.Time
+	// The quantity of items to be be returned
+	Size int
+}
+
+// ValidationError is returned when a Request contains a value Bitly would reject
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid metrics request: %s: %s", e.Field, e.Message)
+}
+
+// Validate checks the request for values Bitly would reject, so no request has to be sent to find out.
+func (r *Request) Validate() error {
+	if r == nil {
+		return nil
+	}
+
+	if len(r.Unit) > 0 && !r.Unit.Valid() {
+		return &ValidationError{Field: "unit", Message: fmt.Sprintf("%q is not one of %s", string(r.Unit), joinUnits())}
+	}
+
+	if r.Units < -1 {
+		return &ValidationError{Field: "units", Message: "must be -1, to return all units of time, or a positive number"}
+	}
+
+	if r.Size < 0 {
+		return &ValidationError{Field: "size", Message: "must be a positive number"}
+	}
+
+	return nil
+}
+
+// Values validates the request and returns the query parameters for it. A nil request has no query parameters.
+func (r *Request) Values() (url.Values, error) {
+	v := url.Values{}
+
+	if r == nil {
+		return v, nil
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(r.Unit) > 0 {
+		v.Add("unit", string(r.Unit))
+	}
+
+	if r.Units != 0 {
+		v.Add("units", fmt.Sprintf("%d", r.Units))
+	}
+
+	if !r.UnitReference.IsZero() {
+		v.Add("unit_reference", r.UnitReference.Format(UnitReferenceFormat))
+	}
+
+	if r.Size != 0 {
+		v.Add("size", fmt.Sprintf("%d", r.Size))
+	}
+
+	return v, nil
+}
+
+// Encode validates the request and appends its query parameters to the endpoint.
+func Encode(endpoint string, r *Request) (string, error) {
+	v, err := r.Values()
+	if err != nil {
+		return "", err
+	}
+
+	return client.AppendQuery(endpoint, v), nil
+}
+
+func joinUnits() string {
+	s := make([]string, len(Units))
+	for idx, u := range Units {
+		s[idx] = string(u)
+	}
+	return strings.Join(s, ", ")
+}
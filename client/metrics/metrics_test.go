@@ -0,0 +1,120 @@
+package metrics_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/metrics"
+)
+
+func TestRequestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *metrics.Request
+		field   string
+	}{
+		{name: "nil", request: nil},
+		{name: "empty", request: &metrics.Request{}},
+		{name: "valid", request: &metrics.Request{Unit: metrics.UnitDay, Units: 7, Size: 10}},
+		{name: "all units", request: &metrics.Request{Unit: metrics.UnitHour, Units: -1}},
+		{name: "invalid unit", request: &metrics.Request{Unit: "year"}, field: "unit"},
+		{name: "unit in upper case", request: &metrics.Request{Unit: "DAY"}, field: "unit"},
+		{name: "negative units", request: &metrics.Request{Unit: metrics.UnitDay, Units: -2}, field: "units"},
+		{name: "negative size", request: &metrics.Request{Size: -1}, field: "size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if len(tt.field) == 0 {
+				if err != nil {
+					t.Errorf("Validate() returned %s", err.Error())
+				}
+				return
+			}
+
+			verr, ok := err.(*metrics.ValidationError)
+			if !ok {
+				t.Fatalf("Validate() = %v, want a *ValidationError", err)
+			}
+			if verr.Field != tt.field {
+				t.Errorf("Field = %s, want %s", verr.Field, tt.field)
+			}
+		})
+	}
+}
+
+func TestParseUnit(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    metrics.Unit
+		wantErr bool
+	}{
+		{s: "day", want: metrics.UnitDay},
+		{s: " Month ", want: metrics.UnitMonth},
+		{s: "MINUTE", want: metrics.UnitMinute},
+		{s: "year", wantErr: true},
+		{s: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := metrics.ParseUnit(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUnit(%q) returned %v", tt.s, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseUnit(%q) = %s, want %s", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	reference := time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		request *metrics.Request
+		want    string
+		wantErr bool
+	}{
+		{name: "nil", request: nil, want: "bitlinks/bit.ly/x/clicks"},
+		{name: "empty", request: &metrics.Request{}, want: "bitlinks/bit.ly/x/clicks"},
+		{name: "unit and units", request: &metrics.Request{Unit: metrics.UnitDay, Units: -1}, want: "bitlinks/bit.ly/x/clicks?unit=day&units=-1"},
+		{
+			name:    "all fields",
+			request: &metrics.Request{Unit: metrics.UnitWeek, Units: 4, UnitReference: reference, Size: 5},
+			want:    "bitlinks/bit.ly/x/clicks?size=5&unit=week&unit_reference=2019-06-01T12%3A00%3A00%2B0000&units=4",
+		},
+		{name: "invalid", request: &metrics.Request{Units: -5}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := metrics.Encode("bitlinks/bit.ly/x/clicks", tt.request)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Encode() returned %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Encode() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidRequestIsNotSent(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	details := srv.AddBitlink(bitlytest.DefaultGroupGUID, bitlinks.BitlinkDetails{LongURL: "https://example.org"})
+
+	_, err := bitlinks.New(srv.Client()).GetClicks(details.ID, &metrics.Request{Unit: "fortnight"})
+	if _, ok := err.(*metrics.ValidationError); !ok {
+		t.Errorf("GetClicks() = %v, want a *ValidationError", err)
+	}
+	if got := len(srv.Requests()); got != 0 {
+		t.Errorf("sent %d requests, want 0", got)
+	}
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// RequestIDHeader is the header set by the RequestIDMiddleware
+	RequestIDHeader = "X-Request-ID"
+	redacted        = "[REDACTED]"
+)
+
+// Doer sends an HTTP request and returns the HTTP response. *http.Client is a Doer.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as a Doer.
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req).
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer to add behavior before or after a request is sent to Bitly, like logging,
+// tracing headers or metrics.
+type Middleware func(next Doer) Doer
+
+// Use adds middlewares to the chain around each request returning a Client pointer for chaining.
+// The middleware that is added first is the outermost one, so it sees the request first and the
+// response last. Middlewares run for every attempt when a RetryPolicy is set.
+func (c *Client) Use(middlewares ...Middleware) *Client {
+	c.middlewares = append(c.middlewares, middlewares...)
+	return c
+}
+
+// doer returns the HTTP client wrapped in all middlewares.
+func (c *Client) doer() Doer {
+	var d Doer = c.httpClient()
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		d = c.middlewares[i](d)
+	}
+	return d
+}
+
+// LoggingMiddleware logs every request and response to the logger as key=value pairs. The bearer token in
+// the authorization header is redacted.
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.Do(req)
+
+			fields := []string{
+				fmt.Sprintf("method=%s", req.Method),
+				fmt.Sprintf("url=%q", req.URL.String()),
+				fmt.Sprintf("headers=%q", formatHeaders(req.Header)),
+				fmt.Sprintf("duration=%s", time.Since(start)),
+			}
+
+			if id := req.Header.Get(RequestIDHeader); len(id) > 0 {
+				fields = append(fields, fmt.Sprintf("request_id=%s", id))
+			}
+
+			if err != nil {
+				fields = append(fields, fmt.Sprintf("error=%q", err.Error()))
+			} else {
+				fields = append(fields, fmt.Sprintf("status=%d", res.StatusCode))
+			}
+
+			logger.Println(strings.Join(fields, " "))
+			return res, err
+		})
+	}
+}
+
+// RequestIDMiddleware sets a random request ID in the X-Request-ID header of every request that doesn't
+// have one yet, so requests can be correlated across logs.
+func RequestIDMiddleware() Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			if len(req.Header.Get(RequestIDHeader)) == 0 {
+				req.Header.Set(RequestIDHeader, newRequestID())
+			}
+			return next.Do(req)
+		})
+	}
+}
+
+// TimingMiddleware calls the observe function after every request with the time it took to complete. The
+// response is nil when the request failed.
+func TimingMiddleware(observe func(req *http.Request, res *http.Response, duration time.Duration, err error)) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			res, err := next.Do(req)
+			observe(req, res, time.Since(start), err)
+			return res, err
+		})
+	}
+}
+
+// formatHeaders formats the headers in a stable order with the credentials redacted.
+func formatHeaders(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := strings.Join(header[k], ",")
+		if strings.EqualFold(k, "Authorization") {
+			v = redactAuthorization(v)
+		}
+		parts = append(parts, fmt.Sprintf("%s: %s", k, v))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
+func redactAuthorization(v string) string {
+	if idx := strings.Index(v, " "); idx > 0 {
+		return fmt.Sprintf("%s %s", v[:idx], redacted)
+	}
+	return redacted
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
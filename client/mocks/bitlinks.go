@@ -0,0 +1,379 @@
+// Code generated by mockgen. DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/retgits/bitly/client/bitlinks"
+)
+
+// Bitlinks is a mock of bitlinks.Service. Set the Func fields to return canned responses; methods
+// without a Func return zero values. The methods without a context call their Context
+// variant with context.Background(), so only the Func of the Context variant needs to be set.
+type Bitlinks struct {
+	Recorder
+
+	ExpandBitlinkFunc                  func(context.Context, bitlinks.Link) (bitlinks.LinkInfo, error)
+	GetMetricsByCountriesFunc          func(context.Context, string, *bitlinks.MetricsRequest) (bitlinks.Metrics, error)
+	GetMetricsByReferrersFunc          func(context.Context, string, *bitlinks.MetricsRequest) (bitlinks.Metrics, error)
+	GetMetricsByReferrersAndDomainFunc func(context.Context, string, *bitlinks.MetricsRequest) (bitlinks.Metrics, error)
+	GetMetricsByReferringDomainsFunc   func(context.Context, string, *bitlinks.MetricsRequest) (bitlinks.Metrics, error)
+	CreateBitlinkFunc                  func(context.Context, *bitlinks.Bitlink) (bitlinks.BitlinkDetails, error)
+	GetClicksSummaryFunc               func(context.Context, string, *bitlinks.MetricsRequest) (bitlinks.Metrics, error)
+	GetClicksFunc                      func(context.Context, string, *bitlinks.MetricsRequest) (bitlinks.Metrics, error)
+	UpdateBitlinkFunc                  func(context.Context, string, *bitlinks.BitlinkDetails) (bitlinks.BitlinkDetails, error)
+	RetrieveBitlinkFunc                func(context.Context, string) (bitlinks.BitlinkDetails, error)
+	ShortenLinkFunc                    func(context.Context, *bitlinks.ShortenRequest) (bitlinks.BitlinkDetails, error)
+	PatchBitlinkFunc                   func(context.Context, string, *bitlinks.Patch) (bitlinks.BitlinkDetails, error)
+	DeleteBitlinkFunc                  func(context.Context, string) error
+	ArchiveBitlinkFunc                 func(context.Context, string) (bitlinks.BitlinkDetails, error)
+	UnarchiveBitlinkFunc               func(context.Context, string) (bitlinks.BitlinkDetails, error)
+	DeleteBitlinksFunc                 func(context.Context, []string) []bitlinks.BulkResult
+	ArchiveBitlinksFunc                func(context.Context, []string) []bitlinks.BulkResult
+	UnarchiveBitlinksFunc              func(context.Context, []string) []bitlinks.BulkResult
+	ListDeeplinksFunc                  func(context.Context, string) ([]bitlinks.Deeplink, error)
+	AddDeeplinkFunc                    func(context.Context, string, bitlinks.Deeplink) (bitlinks.BitlinkDetails, error)
+	UpdateDeeplinkFunc                 func(context.Context, string, string, bitlinks.Deeplink) (bitlinks.BitlinkDetails, error)
+	RemoveDeeplinkFunc                 func(context.Context, string, string) (bitlinks.BitlinkDetails, error)
+	BulkShortenFunc                    func(context.Context, []bitlinks.ShortenRequest, *bitlinks.BulkShortenOptions) []bitlinks.ShortenResult
+	BulkShortenStreamFunc              func(context.Context, <-chan bitlinks.ShortenRequest, *bitlinks.BulkShortenOptions) <-chan bitlinks.ShortenResult
+}
+
+var _ bitlinks.Service = (*Bitlinks)(nil)
+
+// ExpandBitlink implements bitlinks.Service.
+func (m *Bitlinks) ExpandBitlink(link bitlinks.Link) (r0 bitlinks.LinkInfo, r1 error) {
+	return m.ExpandBitlinkContext(context.Background(), link)
+}
+
+// ExpandBitlinkContext implements bitlinks.Service.
+func (m *Bitlinks) ExpandBitlinkContext(ctx context.Context, link bitlinks.Link) (r0 bitlinks.LinkInfo, r1 error) {
+	m.Record("ExpandBitlink", link)
+	if m.ExpandBitlinkFunc != nil {
+		return m.ExpandBitlinkFunc(ctx, link)
+	}
+	return
+}
+
+// GetMetricsByCountries implements bitlinks.Service.
+func (m *Bitlinks) GetMetricsByCountries(bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	return m.GetMetricsByCountriesContext(context.Background(), bitlink, input)
+}
+
+// GetMetricsByCountriesContext implements bitlinks.Service.
+func (m *Bitlinks) GetMetricsByCountriesContext(ctx context.Context, bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	m.Record("GetMetricsByCountries", bitlink, input)
+	if m.GetMetricsByCountriesFunc != nil {
+		return m.GetMetricsByCountriesFunc(ctx, bitlink, input)
+	}
+	return
+}
+
+// GetMetricsByReferrers implements bitlinks.Service.
+func (m *Bitlinks) GetMetricsByReferrers(bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	return m.GetMetricsByReferrersContext(context.Background(), bitlink, input)
+}
+
+// GetMetricsByReferrersContext implements bitlinks.Service.
+func (m *Bitlinks) GetMetricsByReferrersContext(ctx context.Context, bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	m.Record("GetMetricsByReferrers", bitlink, input)
+	if m.GetMetricsByReferrersFunc != nil {
+		return m.GetMetricsByReferrersFunc(ctx, bitlink, input)
+	}
+	return
+}
+
+// GetMetricsByReferrersAndDomain implements bitlinks.Service.
+func (m *Bitlinks) GetMetricsByReferrersAndDomain(bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	return m.GetMetricsByReferrersAndDomainContext(context.Background(), bitlink, input)
+}
+
+// GetMetricsByReferrersAndDomainContext implements bitlinks.Service.
+func (m *Bitlinks) GetMetricsByReferrersAndDomainContext(ctx context.Context, bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	m.Record("GetMetricsByReferrersAndDomain", bitlink, input)
+	if m.GetMetricsByReferrersAndDomainFunc != nil {
+		return m.GetMetricsByReferrersAndDomainFunc(ctx, bitlink, input)
+	}
+	return
+}
+
+// GetMetricsByReferringDomains implements bitlinks.Service.
+func (m *Bitlinks) GetMetricsByReferringDomains(bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	return m.GetMetricsByReferringDomainsContext(context.Background(), bitlink, input)
+}
+
+// GetMetricsByReferringDomainsContext implements bitlinks.Service.
+func (m *Bitlinks) GetMetricsByReferringDomainsContext(ctx context.Context, bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	m.Record("GetMetricsByReferringDomains", bitlink, input)
+	if m.GetMetricsByReferringDomainsFunc != nil {
+		return m.GetMetricsByReferringDomainsFunc(ctx, bitlink, input)
+	}
+	return
+}
+
+// CreateBitlink implements bitlinks.Service.
+func (m *Bitlinks) CreateBitlink(bitlink *bitlinks.Bitlink) (r0 bitlinks.BitlinkDetails, r1 error) {
+	return m.CreateBitlinkContext(context.Background(), bitlink)
+}
+
+// CreateBitlinkContext implements bitlinks.Service.
+func (m *Bitlinks) CreateBitlinkContext(ctx context.Context, bitlink *bitlinks.Bitlink) (r0 bitlinks.BitlinkDetails, r1 error) {
+	m.Record("CreateBitlink", bitlink)
+	if m.CreateBitlinkFunc != nil {
+		return m.CreateBitlinkFunc(ctx, bitlink)
+	}
+	return
+}
+
+// GetClicksSummary implements bitlinks.Service.
+func (m *Bitlinks) GetClicksSummary(bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	return m.GetClicksSummaryContext(context.Background(), bitlink, input)
+}
+
+// GetClicksSummaryContext implements bitlinks.Service.
+func (m *Bitlinks) GetClicksSummaryContext(ctx context.Context, bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	m.Record("GetClicksSummary", bitlink, input)
+	if m.GetClicksSummaryFunc != nil {
+		return m.GetClicksSummaryFunc(ctx, bitlink, input)
+	}
+	return
+}
+
+// GetClicks implements bitlinks.Service.
+func (m *Bitlinks) GetClicks(bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	return m.GetClicksContext(context.Background(), bitlink, input)
+}
+
+// GetClicksContext implements bitlinks.Service.
+func (m *Bitlinks) GetClicksContext(ctx context.Context, bitlink string, input *bitlinks.MetricsRequest) (r0 bitlinks.Metrics, r1 error) {
+	m.Record("GetClicks", bitlink, input)
+	if m.GetClicksFunc != nil {
+		return m.GetClicksFunc(ctx, bitlink, input)
+	}
+	return
+}
+
+// UpdateBitlink implements bitlinks.Service.
+func (m *Bitlinks) UpdateBitlink(bitlink string, bitlinkDetails *bitlinks.BitlinkDetails) (r0 bitlinks.BitlinkDetails, r1 error) {
+	return m.UpdateBitlinkContext(context.Background(), bitlink, bitlinkDetails)
+}
+
+// UpdateBitlinkContext implements bitlinks.Service.
+func (m *Bitlinks) UpdateBitlinkContext(ctx context.Context, bitlink string, bitlinkDetails *bitlinks.BitlinkDetails) (r0 bitlinks.BitlinkDetails, r1 error) {
+	m.Record("UpdateBitlink", bitlink, bitlinkDetails)
+	if m.UpdateBitlinkFunc != nil {
+		return m.UpdateBitlinkFunc(ctx, bitlink, bitlinkDetails)
+	}
+	return
+}
+
+// RetrieveBitlink implements bitlinks.Service.
+func (m *Bitlinks) RetrieveBitlink(bitlink string) (r0 bitlinks.BitlinkDetails, r1 error) {
+	return m.RetrieveBitlinkContext(context.Background(), bitlink)
+}
+
+// RetrieveBitlinkContext implements bitlinks.Service.
+func (m *Bitlinks) RetrieveBitlinkContext(ctx context.Context, bitlink string) (r0 bitlinks.BitlinkDetails, r1 error) {
+	m.Record("RetrieveBitlink", bitlink)
+	if m.RetrieveBitlinkFunc != nil {
+		return m.RetrieveBitlinkFunc(ctx, bitlink)
+	}
+	return
+}
+
+// ShortenLink implements bitlinks.Service.
+func (m *Bitlinks) ShortenLink(bitlink *bitlinks.ShortenRequest) (r0 bitlinks.BitlinkDetails, r1 error) {
+	return m.ShortenLinkContext(context.Background(), bitlink)
+}
+
+// ShortenLinkContext implements bitlinks.Service.
+func (m *Bitlinks) ShortenLinkContext(ctx context.Context, bitlink *bitlinks.ShortenRequest) (r0 bitlinks.BitlinkDetails, r1 error) {
+	m.Record("ShortenLink", bitlink)
+	if m.ShortenLinkFunc != nil {
+		return m.ShortenLinkFunc(ctx, bitlink)
+	}
+	return
+}
+
+// PatchBitlink implements bitlinks.Service.
+func (m *Bitlinks) PatchBitlink(bitlink string, patch *bitlinks.Patch) (r0 bitlinks.BitlinkDetails, r1 error) {
+	return m.PatchBitlinkContext(context.Background(), bitlink, patch)
+}
+
+// PatchBitlinkContext implements bitlinks.Service.
+func (m *Bitlinks) PatchBitlinkContext(ctx context.Context, bitlink string, patch *bitlinks.Patch) (r0 bitlinks.BitlinkDetails, r1 error) {
+	m.Record("PatchBitlink", bitlink, patch)
+	if m.PatchBitlinkFunc != nil {
+		return m.PatchBitlinkFunc(ctx, bitlink, patch)
+	}
+	return
+}
+
+// DeleteBitlink implements bitlinks.Service.
+func (m *Bitlinks) DeleteBitlink(bitlink string) (r0 error) {
+	return m.DeleteBitlinkContext(context.Background(), bitlink)
+}
+
+// DeleteBitlinkContext implements bitlinks.Service.
+func (m *Bitlinks) DeleteBitlinkContext(ctx context.Context, bitlink string) (r0 error) {
+	m.Record("DeleteBitlink", bitlink)
+	if m.DeleteBitlinkFunc != nil {
+		return m.DeleteBitlinkFunc(ctx, bitlink)
+	}
+	return
+}
+
+// ArchiveBitlink implements bitlinks.Service.
+func (m *Bitlinks) ArchiveBitlink(bitlink string) (r0 bitlinks.BitlinkDetails, r1 error) {
+	return m.ArchiveBitlinkContext(context.Background(), bitlink)
+}
+
+// ArchiveBitlinkContext implements bitlinks.Service.
+func (m *Bitlinks) ArchiveBitlinkContext(ctx context.Context, bitlink string) (r0 bitlinks.BitlinkDetails, r1 error) {
+	m.Record("ArchiveBitlink", bitlink)
+	if m.ArchiveBitlinkFunc != nil {
+		return m.ArchiveBitlinkFunc(ctx, bitlink)
+	}
+	return
+}
+
+// UnarchiveBitlink implements bitlinks.Service.
+func (m *Bitlinks) UnarchiveBitlink(bitlink string) (r0 bitlinks.BitlinkDetails, r1 error) {
+	return m.UnarchiveBitlinkContext(context.Background(), bitlink)
+}
+
+// UnarchiveBitlinkContext implements bitlinks.Service.
+func (m *Bitlinks) UnarchiveBitlinkContext(ctx context.Context, bitlink string) (r0 bitlinks.BitlinkDetails, r1 error) {
+	m.Record("UnarchiveBitlink", bitlink)
+	if m.UnarchiveBitlinkFunc != nil {
+		return m.UnarchiveBitlinkFunc(ctx, bitlink)
+	}
+	return
+}
+
+// DeleteBitlinks implements bitlinks.Service.
+func (m *Bitlinks) DeleteBitlinks(p0 []string) (r0 []bitlinks.BulkResult) {
+	return m.DeleteBitlinksContext(context.Background(), p0)
+}
+
+// DeleteBitlinksContext implements bitlinks.Service.
+func (m *Bitlinks) DeleteBitlinksContext(ctx context.Context, p1 []string) (r0 []bitlinks.BulkResult) {
+	m.Record("DeleteBitlinks", p1)
+	if m.DeleteBitlinksFunc != nil {
+		return m.DeleteBitlinksFunc(ctx, p1)
+	}
+	return
+}
+
+// ArchiveBitlinks implements bitlinks.Service.
+func (m *Bitlinks) ArchiveBitlinks(p0 []string) (r0 []bitlinks.BulkResult) {
+	return m.ArchiveBitlinksContext(context.Background(), p0)
+}
+
+// ArchiveBitlinksContext implements bitlinks.Service.
+func (m *Bitlinks) ArchiveBitlinksContext(ctx context.Context, p1 []string) (r0 []bitlinks.BulkResult) {
+	m.Record("ArchiveBitlinks", p1)
+	if m.ArchiveBitlinksFunc != nil {
+		return m.ArchiveBitlinksFunc(ctx, p1)
+	}
+	return
+}
+
+// UnarchiveBitlinks implements bitlinks.Service.
+func (m *Bitlinks) UnarchiveBitlinks(p0 []string) (r0 []bitlinks.BulkResult) {
+	return m.UnarchiveBitlinksContext(context.Background(), p0)
+}
+
+// UnarchiveBitlinksContext implements bitlinks.Service.
+func (m *Bitlinks) UnarchiveBitlinksContext(ctx context.Context, p1 []string) (r0 []bitlinks.BulkResult) {
+	m.Record("UnarchiveBitlinks", p1)
+	if m.UnarchiveBitlinksFunc != nil {
+		return m.UnarchiveBitlinksFunc(ctx, p1)
+	}
+	return
+}
+
+// ListDeeplinks implements bitlinks.Service.
+func (m *Bitlinks) ListDeeplinks(bitlink string) (r0 []bitlinks.Deeplink, r1 error) {
+	return m.ListDeeplinksContext(context.Background(), bitlink)
+}
+
+// ListDeeplinksContext implements bitlinks.Service.
+func (m *Bitlinks) ListDeeplinksContext(ctx context.Context, bitlink string) (r0 []bitlinks.Deeplink, r1 error) {
+	m.Record("ListDeeplinks", bitlink)
+	if m.ListDeeplinksFunc != nil {
+		return m.ListDeeplinksFunc(ctx, bitlink)
+	}
+	return
+}
+
+// AddDeeplink implements bitlinks.Service.
+func (m *Bitlinks) AddDeeplink(bitlink string, deeplink bitlinks.Deeplink) (r0 bitlinks.BitlinkDetails, r1 error) {
+	return m.AddDeeplinkContext(context.Background(), bitlink, deeplink)
+}
+
+// AddDeeplinkContext implements bitlinks.Service.
+func (m *Bitlinks) AddDeeplinkContext(ctx context.Context, bitlink string, deeplink bitlinks.Deeplink) (r0 bitlinks.BitlinkDetails, r1 error) {
+	m.Record("AddDeeplink", bitlink, deeplink)
+	if m.AddDeeplinkFunc != nil {
+		return m.AddDeeplinkFunc(ctx, bitlink, deeplink)
+	}
+	return
+}
+
+// UpdateDeeplink implements bitlinks.Service.
+func (m *Bitlinks) UpdateDeeplink(bitlink string, guid string, deeplink bitlinks.Deeplink) (r0 bitlinks.BitlinkDetails, r1 error) {
+	return m.UpdateDeeplinkContext(context.Background(), bitlink, guid, deeplink)
+}
+
+// UpdateDeeplinkContext implements bitlinks.Service.
+func (m *Bitlinks) UpdateDeeplinkContext(ctx context.Context, bitlink string, guid string, deeplink bitlinks.Deeplink) (r0 bitlinks.BitlinkDetails, r1 error) {
+	m.Record("UpdateDeeplink", bitlink, guid, deeplink)
+	if m.UpdateDeeplinkFunc != nil {
+		return m.UpdateDeeplinkFunc(ctx, bitlink, guid, deeplink)
+	}
+	return
+}
+
+// RemoveDeeplink implements bitlinks.Service.
+func (m *Bitlinks) RemoveDeeplink(bitlink string, guid string) (r0 bitlinks.BitlinkDetails, r1 error) {
+	return m.RemoveDeeplinkContext(context.Background(), bitlink, guid)
+}
+
+// RemoveDeeplinkContext implements bitlinks.Service.
+func (m *Bitlinks) RemoveDeeplinkContext(ctx context.Context, bitlink string, guid string) (r0 bitlinks.BitlinkDetails, r1 error) {
+	m.Record("RemoveDeeplink", bitlink, guid)
+	if m.RemoveDeeplinkFunc != nil {
+		return m.RemoveDeeplinkFunc(ctx, bitlink, guid)
+	}
+	return
+}
+
+// BulkShorten implements bitlinks.Service.
+func (m *Bitlinks) BulkShorten(requests []bitlinks.ShortenRequest, options *bitlinks.BulkShortenOptions) (r0 []bitlinks.ShortenResult) {
+	return m.BulkShortenContext(context.Background(), requests, options)
+}
+
+// BulkShortenContext implements bitlinks.Service.
+func (m *Bitlinks) BulkShortenContext(ctx context.Context, requests []bitlinks.ShortenRequest, options *bitlinks.BulkShortenOptions) (r0 []bitlinks.ShortenResult) {
+	m.Record("BulkShorten", requests, options)
+	if m.BulkShortenFunc != nil {
+		return m.BulkShortenFunc(ctx, requests, options)
+	}
+	return
+}
+
+// BulkShortenStream implements bitlinks.Service.
+func (m *Bitlinks) BulkShortenStream(requests <-chan bitlinks.ShortenRequest, options *bitlinks.BulkShortenOptions) (r0 <-chan bitlinks.ShortenResult) {
+	return m.BulkShortenStreamContext(context.Background(), requests, options)
+}
+
+// BulkShortenStreamContext implements bitlinks.Service.
+func (m *Bitlinks) BulkShortenStreamContext(ctx context.Context, requests <-chan bitlinks.ShortenRequest, options *bitlinks.BulkShortenOptions) (r0 <-chan bitlinks.ShortenResult) {
+	m.Record("BulkShortenStream", requests, options)
+	if m.BulkShortenStreamFunc != nil {
+		return m.BulkShortenStreamFunc(ctx, requests, options)
+	}
+	return
+}
@@ -0,0 +1,34 @@
+// Code generated by mockgen. DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/retgits/bitly/client/bsds"
+)
+
+// BSDs is a mock of bsds.Service. Set the Func fields to return canned responses; methods
+// without a Func return zero values. The methods without a context call their Context
+// variant with context.Background(), so only the Func of the Context variant needs to be set.
+type BSDs struct {
+	Recorder
+
+	GetBSDsFunc func(context.Context) (bsds.BSD, error)
+}
+
+var _ bsds.Service = (*BSDs)(nil)
+
+// GetBSDs implements bsds.Service.
+func (m *BSDs) GetBSDs() (r0 bsds.BSD, r1 error) {
+	return m.GetBSDsContext(context.Background())
+}
+
+// GetBSDsContext implements bsds.Service.
+func (m *BSDs) GetBSDsContext(ctx context.Context) (r0 bsds.BSD, r1 error) {
+	m.Record("GetBSDs")
+	if m.GetBSDsFunc != nil {
+		return m.GetBSDsFunc(ctx)
+	}
+	return
+}
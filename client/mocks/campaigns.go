@@ -0,0 +1,169 @@
+// Code generated by mockgen. DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/retgits/bitly/client/campaigns"
+)
+
+// Campaigns is a mock of campaigns.Service. Set the Func fields to return canned responses; methods
+// without a Func return zero values. The methods without a context call their Context
+// variant with context.Background(), so only the Func of the Context variant needs to be set.
+type Campaigns struct {
+	Recorder
+
+	ListCampaignsFunc             func(context.Context, string) (campaigns.BitlyCampaigns, error)
+	CreateCampaignFunc            func(context.Context, *campaigns.CampaignRequest) (campaigns.Campaign, error)
+	RetrieveCampaignFunc          func(context.Context, string) (campaigns.Campaign, error)
+	UpdateCampaignFunc            func(context.Context, string, *campaigns.CampaignRequest) (campaigns.Campaign, error)
+	ListChannelsFunc              func(context.Context, string, string) (campaigns.BitlyChannels, error)
+	CreateChannelFunc             func(context.Context, *campaigns.ChannelRequest) (campaigns.Channel, error)
+	RetrieveChannelFunc           func(context.Context, string) (campaigns.Channel, error)
+	UpdateChannelFunc             func(context.Context, string, *campaigns.ChannelRequest) (campaigns.Channel, error)
+	AddBitlinksToChannelFunc      func(context.Context, string, string, ...string) (campaigns.Channel, error)
+	RemoveBitlinksFromChannelFunc func(context.Context, string, ...string) (campaigns.Channel, error)
+}
+
+var _ campaigns.Service = (*Campaigns)(nil)
+
+// ListCampaigns implements campaigns.Service.
+func (m *Campaigns) ListCampaigns(groupGUID string) (r0 campaigns.BitlyCampaigns, r1 error) {
+	return m.ListCampaignsContext(context.Background(), groupGUID)
+}
+
+// ListCampaignsContext implements campaigns.Service.
+func (m *Campaigns) ListCampaignsContext(ctx context.Context, groupGUID string) (r0 campaigns.BitlyCampaigns, r1 error) {
+	m.Record("ListCampaigns", groupGUID)
+	if m.ListCampaignsFunc != nil {
+		return m.ListCampaignsFunc(ctx, groupGUID)
+	}
+	return
+}
+
+// CreateCampaign implements campaigns.Service.
+func (m *Campaigns) CreateCampaign(campaign *campaigns.CampaignRequest) (r0 campaigns.Campaign, r1 error) {
+	return m.CreateCampaignContext(context.Background(), campaign)
+}
+
+// CreateCampaignContext implements campaigns.Service.
+func (m *Campaigns) CreateCampaignContext(ctx context.Context, campaign *campaigns.CampaignRequest) (r0 campaigns.Campaign, r1 error) {
+	m.Record("CreateCampaign", campaign)
+	if m.CreateCampaignFunc != nil {
+		return m.CreateCampaignFunc(ctx, campaign)
+	}
+	return
+}
+
+// RetrieveCampaign implements campaigns.Service.
+func (m *Campaigns) RetrieveCampaign(campaignGUID string) (r0 campaigns.Campaign, r1 error) {
+	return m.RetrieveCampaignContext(context.Background(), campaignGUID)
+}
+
+// RetrieveCampaignContext implements campaigns.Service.
+func (m *Campaigns) RetrieveCampaignContext(ctx context.Context, campaignGUID string) (r0 campaigns.Campaign, r1 error) {
+	m.Record("RetrieveCampaign", campaignGUID)
+	if m.RetrieveCampaignFunc != nil {
+		return m.RetrieveCampaignFunc(ctx, campaignGUID)
+	}
+	return
+}
+
+// UpdateCampaign implements campaigns.Service.
+func (m *Campaigns) UpdateCampaign(campaignGUID string, campaign *campaigns.CampaignRequest) (r0 campaigns.Campaign, r1 error) {
+	return m.UpdateCampaignContext(context.Background(), campaignGUID, campaign)
+}
+
+// UpdateCampaignContext implements campaigns.Service.
+func (m *Campaigns) UpdateCampaignContext(ctx context.Context, campaignGUID string, campaign *campaigns.CampaignRequest) (r0 campaigns.Campaign, r1 error) {
+	m.Record("UpdateCampaign", campaignGUID, campaign)
+	if m.UpdateCampaignFunc != nil {
+		return m.UpdateCampaignFunc(ctx, campaignGUID, campaign)
+	}
+	return
+}
+
+// ListChannels implements campaigns.Service.
+func (m *Campaigns) ListChannels(groupGUID string, campaignGUID string) (r0 campaigns.BitlyChannels, r1 error) {
+	return m.ListChannelsContext(context.Background(), groupGUID, campaignGUID)
+}
+
+// ListChannelsContext implements campaigns.Service.
+func (m *Campaigns) ListChannelsContext(ctx context.Context, groupGUID string, campaignGUID string) (r0 campaigns.BitlyChannels, r1 error) {
+	m.Record("ListChannels", groupGUID, campaignGUID)
+	if m.ListChannelsFunc != nil {
+		return m.ListChannelsFunc(ctx, groupGUID, campaignGUID)
+	}
+	return
+}
+
+// CreateChannel implements campaigns.Service.
+func (m *Campaigns) CreateChannel(channel *campaigns.ChannelRequest) (r0 campaigns.Channel, r1 error) {
+	return m.CreateChannelContext(context.Background(), channel)
+}
+
+// CreateChannelContext implements campaigns.Service.
+func (m *Campaigns) CreateChannelContext(ctx context.Context, channel *campaigns.ChannelRequest) (r0 campaigns.Channel, r1 error) {
+	m.Record("CreateChannel", channel)
+	if m.CreateChannelFunc != nil {
+		return m.CreateChannelFunc(ctx, channel)
+	}
+	return
+}
+
+// RetrieveChannel implements campaigns.Service.
+func (m *Campaigns) RetrieveChannel(channelGUID string) (r0 campaigns.Channel, r1 error) {
+	return m.RetrieveChannelContext(context.Background(), channelGUID)
+}
+
+// RetrieveChannelContext implements campaigns.Service.
+func (m *Campaigns) RetrieveChannelContext(ctx context.Context, channelGUID string) (r0 campaigns.Channel, r1 error) {
+	m.Record("RetrieveChannel", channelGUID)
+	if m.RetrieveChannelFunc != nil {
+		return m.RetrieveChannelFunc(ctx, channelGUID)
+	}
+	return
+}
+
+// UpdateChannel implements campaigns.Service.
+func (m *Campaigns) UpdateChannel(channelGUID string, channel *campaigns.ChannelRequest) (r0 campaigns.Channel, r1 error) {
+	return m.UpdateChannelContext(context.Background(), channelGUID, channel)
+}
+
+// UpdateChannelContext implements campaigns.Service.
+func (m *Campaigns) UpdateChannelContext(ctx context.Context, channelGUID string, channel *campaigns.ChannelRequest) (r0 campaigns.Channel, r1 error) {
+	m.Record("UpdateChannel", channelGUID, channel)
+	if m.UpdateChannelFunc != nil {
+		return m.UpdateChannelFunc(ctx, channelGUID, channel)
+	}
+	return
+}
+
+// AddBitlinksToChannel implements campaigns.Service.
+func (m *Campaigns) AddBitlinksToChannel(channelGUID string, campaignGUID string, bitlinkIDs ...string) (r0 campaigns.Channel, r1 error) {
+	return m.AddBitlinksToChannelContext(context.Background(), channelGUID, campaignGUID, bitlinkIDs...)
+}
+
+// AddBitlinksToChannelContext implements campaigns.Service.
+func (m *Campaigns) AddBitlinksToChannelContext(ctx context.Context, channelGUID string, campaignGUID string, bitlinkIDs ...string) (r0 campaigns.Channel, r1 error) {
+	m.Record("AddBitlinksToChannel", channelGUID, campaignGUID, bitlinkIDs)
+	if m.AddBitlinksToChannelFunc != nil {
+		return m.AddBitlinksToChannelFunc(ctx, channelGUID, campaignGUID, bitlinkIDs...)
+	}
+	return
+}
+
+// RemoveBitlinksFromChannel implements campaigns.Service.
+func (m *Campaigns) RemoveBitlinksFromChannel(channelGUID string, bitlinkIDs ...string) (r0 campaigns.Channel, r1 error) {
+	return m.RemoveBitlinksFromChannelContext(context.Background(), channelGUID, bitlinkIDs...)
+}
+
+// RemoveBitlinksFromChannelContext implements campaigns.Service.
+func (m *Campaigns) RemoveBitlinksFromChannelContext(ctx context.Context, channelGUID string, bitlinkIDs ...string) (r0 campaigns.Channel, r1 error) {
+	m.Record("RemoveBitlinksFromChannel", channelGUID, bitlinkIDs)
+	if m.RemoveBitlinksFromChannelFunc != nil {
+		return m.RemoveBitlinksFromChannelFunc(ctx, channelGUID, bitlinkIDs...)
+	}
+	return
+}
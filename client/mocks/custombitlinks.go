@@ -0,0 +1,94 @@
+// Code generated by mockgen. DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/retgits/bitly/client/custombitlinks"
+)
+
+// CustomBitlinks is a mock of custombitlinks.Service. Set the Func fields to return canned responses; methods
+// without a Func return zero values. The methods without a context call their Context
+// variant with context.Background(), so only the Func of the Context variant needs to be set.
+type CustomBitlinks struct {
+	Recorder
+
+	AddCustomBitlinkFunc        func(context.Context, *custombitlinks.AddRequest) (custombitlinks.CustomBitlink, error)
+	RetrieveCustomBitlinkFunc   func(context.Context, string) (custombitlinks.CustomBitlink, error)
+	RetargetCustomBitlinkFunc   func(context.Context, string, string) (custombitlinks.CustomBitlink, error)
+	GetCustomBitlinkHistoryFunc func(context.Context, string) ([]custombitlinks.HistoryEntry, error)
+	CheckAvailabilityFunc       func(context.Context, string) (custombitlinks.Availability, error)
+}
+
+var _ custombitlinks.Service = (*CustomBitlinks)(nil)
+
+// AddCustomBitlink implements custombitlinks.Service.
+func (m *CustomBitlinks) AddCustomBitlink(request *custombitlinks.AddRequest) (r0 custombitlinks.CustomBitlink, r1 error) {
+	return m.AddCustomBitlinkContext(context.Background(), request)
+}
+
+// AddCustomBitlinkContext implements custombitlinks.Service.
+func (m *CustomBitlinks) AddCustomBitlinkContext(ctx context.Context, request *custombitlinks.AddRequest) (r0 custombitlinks.CustomBitlink, r1 error) {
+	m.Record("AddCustomBitlink", request)
+	if m.AddCustomBitlinkFunc != nil {
+		return m.AddCustomBitlinkFunc(ctx, request)
+	}
+	return
+}
+
+// RetrieveCustomBitlink implements custombitlinks.Service.
+func (m *CustomBitlinks) RetrieveCustomBitlink(customBitlink string) (r0 custombitlinks.CustomBitlink, r1 error) {
+	return m.RetrieveCustomBitlinkContext(context.Background(), customBitlink)
+}
+
+// RetrieveCustomBitlinkContext implements custombitlinks.Service.
+func (m *CustomBitlinks) RetrieveCustomBitlinkContext(ctx context.Context, customBitlink string) (r0 custombitlinks.CustomBitlink, r1 error) {
+	m.Record("RetrieveCustomBitlink", customBitlink)
+	if m.RetrieveCustomBitlinkFunc != nil {
+		return m.RetrieveCustomBitlinkFunc(ctx, customBitlink)
+	}
+	return
+}
+
+// RetargetCustomBitlink implements custombitlinks.Service.
+func (m *CustomBitlinks) RetargetCustomBitlink(customBitlink string, bitlinkID string) (r0 custombitlinks.CustomBitlink, r1 error) {
+	return m.RetargetCustomBitlinkContext(context.Background(), customBitlink, bitlinkID)
+}
+
+// RetargetCustomBitlinkContext implements custombitlinks.Service.
+func (m *CustomBitlinks) RetargetCustomBitlinkContext(ctx context.Context, customBitlink string, bitlinkID string) (r0 custombitlinks.CustomBitlink, r1 error) {
+	m.Record("RetargetCustomBitlink", customBitlink, bitlinkID)
+	if m.RetargetCustomBitlinkFunc != nil {
+		return m.RetargetCustomBitlinkFunc(ctx, customBitlink, bitlinkID)
+	}
+	return
+}
+
+// GetCustomBitlinkHistory implements custombitlinks.Service.
+func (m *CustomBitlinks) GetCustomBitlinkHistory(customBitlink string) (r0 []custombitlinks.HistoryEntry, r1 error) {
+	return m.GetCustomBitlinkHistoryContext(context.Background(), customBitlink)
+}
+
+// GetCustomBitlinkHistoryContext implements custombitlinks.Service.
+func (m *CustomBitlinks) GetCustomBitlinkHistoryContext(ctx context.Context, customBitlink string) (r0 []custombitlinks.HistoryEntry, r1 error) {
+	m.Record("GetCustomBitlinkHistory", customBitlink)
+	if m.GetCustomBitlinkHistoryFunc != nil {
+		return m.GetCustomBitlinkHistoryFunc(ctx, customBitlink)
+	}
+	return
+}
+
+// CheckAvailability implements custombitlinks.Service.
+func (m *CustomBitlinks) CheckAvailability(customBitlink string) (r0 custombitlinks.Availability, r1 error) {
+	return m.CheckAvailabilityContext(context.Background(), customBitlink)
+}
+
+// CheckAvailabilityContext implements custombitlinks.Service.
+func (m *CustomBitlinks) CheckAvailabilityContext(ctx context.Context, customBitlink string) (r0 custombitlinks.Availability, r1 error) {
+	m.Record("CheckAvailability", customBitlink)
+	if m.CheckAvailabilityFunc != nil {
+		return m.CheckAvailabilityFunc(ctx, customBitlink)
+	}
+	return
+}
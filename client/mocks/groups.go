@@ -0,0 +1,199 @@
+// Code generated by mockgen. DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/retgits/bitly/client/groups"
+)
+
+// Groups is a mock of groups.Service. Set the Func fields to return canned responses; methods
+// without a Func return zero values. The methods without a context call their Context
+// variant with context.Background(), so only the Func of the Context variant needs to be set.
+type Groups struct {
+	Recorder
+
+	RetrieveGroupsFunc                          func(context.Context, string) (groups.BitlyGroups, error)
+	RetrieveGroupDetailsFunc                    func(context.Context, string) (groups.Group, error)
+	RetrieveGroupPreferencesFunc                func(context.Context, string) (groups.BitlyGroupPreferences, error)
+	UpdateGroupDetailsFunc                      func(context.Context, string, groups.Group) (groups.Group, error)
+	PatchGroupDetailsFunc                       func(context.Context, string, *groups.Patch) (groups.Group, error)
+	UpdateGroupPreferencesFunc                  func(context.Context, string, groups.BitlyGroupPreferences) (groups.BitlyGroupPreferences, error)
+	RetrieveBitlinksByGroupFunc                 func(context.Context, string, *groups.BitlinksGroupRequest) (groups.Bitlinks, error)
+	RetrieveTagsByGroupFunc                     func(context.Context, string) (groups.Tags, error)
+	GetGroupClickMetricsByCountriesFunc         func(context.Context, string, *groups.MetricsRequest) (groups.Metrics, error)
+	GetGroupClickMetricsByReferringNetworksFunc func(context.Context, string, *groups.MetricsRequest) (groups.Metrics, error)
+	RetrieveGroupShortenCountsFunc              func(context.Context, string, *groups.MetricsRequest) (groups.Metrics, error)
+	RetrieveSortedBitlinksForGroupFunc          func(context.Context, string, *groups.SortedBitlinksGroupRequest) (groups.Bitlinks, error)
+}
+
+var _ groups.Service = (*Groups)(nil)
+
+// RetrieveGroups implements groups.Service.
+func (m *Groups) RetrieveGroups(organizationGUID string) (r0 groups.BitlyGroups, r1 error) {
+	return m.RetrieveGroupsContext(context.Background(), organizationGUID)
+}
+
+// RetrieveGroupsContext implements groups.Service.
+func (m *Groups) RetrieveGroupsContext(ctx context.Context, organizationGUID string) (r0 groups.BitlyGroups, r1 error) {
+	m.Record("RetrieveGroups", organizationGUID)
+	if m.RetrieveGroupsFunc != nil {
+		return m.RetrieveGroupsFunc(ctx, organizationGUID)
+	}
+	return
+}
+
+// RetrieveGroupDetails implements groups.Service.
+func (m *Groups) RetrieveGroupDetails(groupGUID string) (r0 groups.Group, r1 error) {
+	return m.RetrieveGroupDetailsContext(context.Background(), groupGUID)
+}
+
+// RetrieveGroupDetailsContext implements groups.Service.
+func (m *Groups) RetrieveGroupDetailsContext(ctx context.Context, groupGUID string) (r0 groups.Group, r1 error) {
+	m.Record("RetrieveGroupDetails", groupGUID)
+	if m.RetrieveGroupDetailsFunc != nil {
+		return m.RetrieveGroupDetailsFunc(ctx, groupGUID)
+	}
+	return
+}
+
+// RetrieveGroupPreferences implements groups.Service.
+func (m *Groups) RetrieveGroupPreferences(groupGUID string) (r0 groups.BitlyGroupPreferences, r1 error) {
+	return m.RetrieveGroupPreferencesContext(context.Background(), groupGUID)
+}
+
+// RetrieveGroupPreferencesContext implements groups.Service.
+func (m *Groups) RetrieveGroupPreferencesContext(ctx context.Context, groupGUID string) (r0 groups.BitlyGroupPreferences, r1 error) {
+	m.Record("RetrieveGroupPreferences", groupGUID)
+	if m.RetrieveGroupPreferencesFunc != nil {
+		return m.RetrieveGroupPreferencesFunc(ctx, groupGUID)
+	}
+	return
+}
+
+// UpdateGroupDetails implements groups.Service.
+func (m *Groups) UpdateGroupDetails(groupGUID string, prefs groups.Group) (r0 groups.Group, r1 error) {
+	return m.UpdateGroupDetailsContext(context.Background(), groupGUID, prefs)
+}
+
+// UpdateGroupDetailsContext implements groups.Service.
+func (m *Groups) UpdateGroupDetailsContext(ctx context.Context, groupGUID string, prefs groups.Group) (r0 groups.Group, r1 error) {
+	m.Record("UpdateGroupDetails", groupGUID, prefs)
+	if m.UpdateGroupDetailsFunc != nil {
+		return m.UpdateGroupDetailsFunc(ctx, groupGUID, prefs)
+	}
+	return
+}
+
+// PatchGroupDetails implements groups.Service.
+func (m *Groups) PatchGroupDetails(groupGUID string, patch *groups.Patch) (r0 groups.Group, r1 error) {
+	return m.PatchGroupDetailsContext(context.Background(), groupGUID, patch)
+}
+
+// PatchGroupDetailsContext implements groups.Service.
+func (m *Groups) PatchGroupDetailsContext(ctx context.Context, groupGUID string, patch *groups.Patch) (r0 groups.Group, r1 error) {
+	m.Record("PatchGroupDetails", groupGUID, patch)
+	if m.PatchGroupDetailsFunc != nil {
+		return m.PatchGroupDetailsFunc(ctx, groupGUID, patch)
+	}
+	return
+}
+
+// UpdateGroupPreferences implements groups.Service.
+func (m *Groups) UpdateGroupPreferences(groupGUID string, prefs groups.BitlyGroupPreferences) (r0 groups.BitlyGroupPreferences, r1 error) {
+	return m.UpdateGroupPreferencesContext(context.Background(), groupGUID, prefs)
+}
+
+// UpdateGroupPreferencesContext implements groups.Service.
+func (m *Groups) UpdateGroupPreferencesContext(ctx context.Context, groupGUID string, prefs groups.BitlyGroupPreferences) (r0 groups.BitlyGroupPreferences, r1 error) {
+	m.Record("UpdateGroupPreferences", groupGUID, prefs)
+	if m.UpdateGroupPreferencesFunc != nil {
+		return m.UpdateGroupPreferencesFunc(ctx, groupGUID, prefs)
+	}
+	return
+}
+
+// RetrieveBitlinksByGroup implements groups.Service.
+func (m *Groups) RetrieveBitlinksByGroup(groupGUID string, input *groups.BitlinksGroupRequest) (r0 groups.Bitlinks, r1 error) {
+	return m.RetrieveBitlinksByGroupContext(context.Background(), groupGUID, input)
+}
+
+// RetrieveBitlinksByGroupContext implements groups.Service.
+func (m *Groups) RetrieveBitlinksByGroupContext(ctx context.Context, groupGUID string, input *groups.BitlinksGroupRequest) (r0 groups.Bitlinks, r1 error) {
+	m.Record("RetrieveBitlinksByGroup", groupGUID, input)
+	if m.RetrieveBitlinksByGroupFunc != nil {
+		return m.RetrieveBitlinksByGroupFunc(ctx, groupGUID, input)
+	}
+	return
+}
+
+// RetrieveTagsByGroup implements groups.Service.
+func (m *Groups) RetrieveTagsByGroup(groupGUID string) (r0 groups.Tags, r1 error) {
+	return m.RetrieveTagsByGroupContext(context.Background(), groupGUID)
+}
+
+// RetrieveTagsByGroupContext implements groups.Service.
+func (m *Groups) RetrieveTagsByGroupContext(ctx context.Context, groupGUID string) (r0 groups.Tags, r1 error) {
+	m.Record("RetrieveTagsByGroup", groupGUID)
+	if m.RetrieveTagsByGroupFunc != nil {
+		return m.RetrieveTagsByGroupFunc(ctx, groupGUID)
+	}
+	return
+}
+
+// GetGroupClickMetricsByCountries implements groups.Service.
+func (m *Groups) GetGroupClickMetricsByCountries(groupGUID string, input *groups.MetricsRequest) (r0 groups.Metrics, r1 error) {
+	return m.GetGroupClickMetricsByCountriesContext(context.Background(), groupGUID, input)
+}
+
+// GetGroupClickMetricsByCountriesContext implements groups.Service.
+func (m *Groups) GetGroupClickMetricsByCountriesContext(ctx context.Context, groupGUID string, input *groups.MetricsRequest) (r0 groups.Metrics, r1 error) {
+	m.Record("GetGroupClickMetricsByCountries", groupGUID, input)
+	if m.GetGroupClickMetricsByCountriesFunc != nil {
+		return m.GetGroupClickMetricsByCountriesFunc(ctx, groupGUID, input)
+	}
+	return
+}
+
+// GetGroupClickMetricsByReferringNetworks implements groups.Service.
+func (m *Groups) GetGroupClickMetricsByReferringNetworks(groupGUID string, input *groups.MetricsRequest) (r0 groups.Metrics, r1 error) {
+	return m.GetGroupClickMetricsByReferringNetworksContext(context.Background(), groupGUID, input)
+}
+
+// GetGroupClickMetricsByReferringNetworksContext implements groups.Service.
+func (m *Groups) GetGroupClickMetricsByReferringNetworksContext(ctx context.Context, groupGUID string, input *groups.MetricsRequest) (r0 groups.Metrics, r1 error) {
+	m.Record("GetGroupClickMetricsByReferringNetworks", groupGUID, input)
+	if m.GetGroupClickMetricsByReferringNetworksFunc != nil {
+		return m.GetGroupClickMetricsByReferringNetworksFunc(ctx, groupGUID, input)
+	}
+	return
+}
+
+// RetrieveGroupShortenCounts implements groups.Service.
+func (m *Groups) RetrieveGroupShortenCounts(groupGUID string, input *groups.MetricsRequest) (r0 groups.Metrics, r1 error) {
+	return m.RetrieveGroupShortenCountsContext(context.Background(), groupGUID, input)
+}
+
+// RetrieveGroupShortenCountsContext implements groups.Service.
+func (m *Groups) RetrieveGroupShortenCountsContext(ctx context.Context, groupGUID string, input *groups.MetricsRequest) (r0 groups.Metrics, r1 error) {
+	m.Record("RetrieveGroupShortenCounts", groupGUID, input)
+	if m.RetrieveGroupShortenCountsFunc != nil {
+		return m.RetrieveGroupShortenCountsFunc(ctx, groupGUID, input)
+	}
+	return
+}
+
+// RetrieveSortedBitlinksForGroup implements groups.Service.
+func (m *Groups) RetrieveSortedBitlinksForGroup(groupGUID string, input *groups.SortedBitlinksGroupRequest) (r0 groups.Bitlinks, r1 error) {
+	return m.RetrieveSortedBitlinksForGroupContext(context.Background(), groupGUID, input)
+}
+
+// RetrieveSortedBitlinksForGroupContext implements groups.Service.
+func (m *Groups) RetrieveSortedBitlinksForGroupContext(ctx context.Context, groupGUID string, input *groups.SortedBitlinksGroupRequest) (r0 groups.Bitlinks, r1 error) {
+	m.Record("RetrieveSortedBitlinksForGroup", groupGUID, input)
+	if m.RetrieveSortedBitlinksForGroupFunc != nil {
+		return m.RetrieveSortedBitlinksForGroupFunc(ctx, groupGUID, input)
+	}
+	return
+}
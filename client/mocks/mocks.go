@@ -0,0 +1,72 @@
+// Package mocks contains mocks of the Service interfaces of the services, which record their calls and
+// return canned responses. The mocks are generated, run go generate after changing a Service interface.
+//
+//	m := &mocks.Bitlinks{}
+//	m.RetrieveBitlinkFunc = func(ctx context.Context, bitlink string) (bitlinks.BitlinkDetails, error) {
+//		return bitlinks.BitlinkDetails{ID: bitlink, LongURL: "https://example.org"}, nil
+//	}
+//	details, err := m.RetrieveBitlink("bit.ly/abc")
+//	calls := m.CallsTo("RetrieveBitlink")
+package mocks
+
+//go:generate go run ../../internal/mockgen -src ../bitlinks -import github.com/retgits/bitly/client/bitlinks -type Bitlinks -out bitlinks.go
+//go:generate go run ../../internal/mockgen -src ../bsds -import github.com/retgits/bitly/client/bsds -type BSDs -out bsds.go
+//go:generate go run ../../internal/mockgen -src ../campaigns -import github.com/retgits/bitly/client/campaigns -type Campaigns -out campaigns.go
+//go:generate go run ../../internal/mockgen -src ../custombitlinks -import github.com/retgits/bitly/client/custombitlinks -type CustomBitlinks -out custombitlinks.go
+//go:generate go run ../../internal/mockgen -src ../groups -import github.com/retgits/bitly/client/groups -type Groups -out groups.go
+//go:generate go run ../../internal/mockgen -src ../organizations -import github.com/retgits/bitly/client/organizations -type Organizations -out organizations.go
+//go:generate go run ../../internal/mockgen -src ../users -import github.com/retgits/bitly/client/users -type Users -out users.go
+
+import "sync"
+
+// Call is a single call to a mock. The context is not recorded.
+type Call struct {
+	// The name of the method, without the Context suffix
+	Method string
+	// The arguments of the call
+	Args []interface{}
+}
+
+// Recorder records the calls to a mock. It is safe for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Record adds a call.
+func (r *Recorder) Record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Calls returns all recorded calls, in order.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo returns the recorded calls to the method, in order.
+func (r *Recorder) CallsTo(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var calls []Call
+	for _, c := range r.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// Reset removes all recorded calls.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
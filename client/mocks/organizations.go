@@ -0,0 +1,64 @@
+// Code generated by mockgen. DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/retgits/bitly/client/organizations"
+)
+
+// Organizations is a mock of organizations.Service. Set the Func fields to return canned responses; methods
+// without a Func return zero values. The methods without a context call their Context
+// variant with context.Background(), so only the Func of the Context variant needs to be set.
+type Organizations struct {
+	Recorder
+
+	RetrieveOrganizationDetailsFunc       func(context.Context, string) (organizations.OrganizationDetails, error)
+	RetrieveOrganizationsFunc             func(context.Context) (organizations.BitlyOrganizations, error)
+	RetrieveOrganizationShortenCountsFunc func(context.Context, string, *organizations.MetricsRequest) (organizations.Metrics, error)
+}
+
+var _ organizations.Service = (*Organizations)(nil)
+
+// RetrieveOrganizationDetails implements organizations.Service.
+func (m *Organizations) RetrieveOrganizationDetails(organizationGUID string) (r0 organizations.OrganizationDetails, r1 error) {
+	return m.RetrieveOrganizationDetailsContext(context.Background(), organizationGUID)
+}
+
+// RetrieveOrganizationDetailsContext implements organizations.Service.
+func (m *Organizations) RetrieveOrganizationDetailsContext(ctx context.Context, organizationGUID string) (r0 organizations.OrganizationDetails, r1 error) {
+	m.Record("RetrieveOrganizationDetails", organizationGUID)
+	if m.RetrieveOrganizationDetailsFunc != nil {
+		return m.RetrieveOrganizationDetailsFunc(ctx, organizationGUID)
+	}
+	return
+}
+
+// RetrieveOrganizations implements organizations.Service.
+func (m *Organizations) RetrieveOrganizations() (r0 organizations.BitlyOrganizations, r1 error) {
+	return m.RetrieveOrganizationsContext(context.Background())
+}
+
+// RetrieveOrganizationsContext implements organizations.Service.
+func (m *Organizations) RetrieveOrganizationsContext(ctx context.Context) (r0 organizations.BitlyOrganizations, r1 error) {
+	m.Record("RetrieveOrganizations")
+	if m.RetrieveOrganizationsFunc != nil {
+		return m.RetrieveOrganizationsFunc(ctx)
+	}
+	return
+}
+
+// RetrieveOrganizationShortenCounts implements organizations.Service.
+func (m *Organizations) RetrieveOrganizationShortenCounts(organizationGUID string, input *organizations.MetricsRequest) (r0 organizations.Metrics, r1 error) {
+	return m.RetrieveOrganizationShortenCountsContext(context.Background(), organizationGUID, input)
+}
+
+// RetrieveOrganizationShortenCountsContext implements organizations.Service.
+func (m *Organizations) RetrieveOrganizationShortenCountsContext(ctx context.Context, organizationGUID string, input *organizations.MetricsRequest) (r0 organizations.Metrics, r1 error) {
+	m.Record("RetrieveOrganizationShortenCounts", organizationGUID, input)
+	if m.RetrieveOrganizationShortenCountsFunc != nil {
+		return m.RetrieveOrganizationShortenCountsFunc(ctx, organizationGUID, input)
+	}
+	return
+}
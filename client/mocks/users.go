@@ -0,0 +1,64 @@
+// Code generated by mockgen. DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+
+	"github.com/retgits/bitly/client/users"
+)
+
+// Users is a mock of users.Service. Set the Func fields to return canned responses; methods
+// without a Func return zero values. The methods without a context call their Context
+// variant with context.Background(), so only the Func of the Context variant needs to be set.
+type Users struct {
+	Recorder
+
+	UpdateUserFunc   func(context.Context, users.User) (users.User, error)
+	RetrieveUserFunc func(context.Context) (users.User, error)
+	PatchUserFunc    func(context.Context, *users.Patch) (users.User, error)
+}
+
+var _ users.Service = (*Users)(nil)
+
+// UpdateUser implements users.Service.
+func (m *Users) UpdateUser(user users.User) (r0 users.User, r1 error) {
+	return m.UpdateUserContext(context.Background(), user)
+}
+
+// UpdateUserContext implements users.Service.
+func (m *Users) UpdateUserContext(ctx context.Context, user users.User) (r0 users.User, r1 error) {
+	m.Record("UpdateUser", user)
+	if m.UpdateUserFunc != nil {
+		return m.UpdateUserFunc(ctx, user)
+	}
+	return
+}
+
+// RetrieveUser implements users.Service.
+func (m *Users) RetrieveUser() (r0 users.User, r1 error) {
+	return m.RetrieveUserContext(context.Background())
+}
+
+// RetrieveUserContext implements users.Service.
+func (m *Users) RetrieveUserContext(ctx context.Context) (r0 users.User, r1 error) {
+	m.Record("RetrieveUser")
+	if m.RetrieveUserFunc != nil {
+		return m.RetrieveUserFunc(ctx)
+	}
+	return
+}
+
+// PatchUser implements users.Service.
+func (m *Users) PatchUser(patch *users.Patch) (r0 users.User, r1 error) {
+	return m.PatchUserContext(context.Background(), patch)
+}
+
+// PatchUserContext implements users.Service.
+func (m *Users) PatchUserContext(ctx context.Context, patch *users.Patch) (r0 users.User, r1 error) {
+	m.Record("PatchUser", patch)
+	if m.PatchUserFunc != nil {
+		return m.PatchUserFunc(ctx, patch)
+	}
+	return
+}
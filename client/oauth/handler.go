@@ -0,0 +1,129 @@
+package oauth
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CallbackHandler returns the handler of the RedirectURL. It checks the state, which must not be empty,
+// exchanges the code for a token and calls done with the result. Done writes the response to the user, or the handler writes a
+// short page when done is nil.
+func (c *Config) CallbackHandler(state string, done func(w http.ResponseWriter, r *http.Request, token *Token, err error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, err := c.handleCallback(r, state)
+		if done != nil {
+			done(w, r, token, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "<p>Connecting your Bitly account failed: %s</p>", html.EscapeString(err.Error()))
+			return
+		}
+		fmt.Fprintf(w, "<p>Your Bitly account %s is connected. You can close this window.</p>", html.EscapeString(token.Login))
+	})
+}
+
+// handleCallback reads the code from the redirect and exchanges it for a token.
+func (c *Config) handleCallback(r *http.Request, state string) (*Token, error) {
+	q := r.URL.Query()
+
+	// An empty state would turn off the protection against cross-site request forgery
+	if len(state) == 0 {
+		return nil, fmt.Errorf("oauth: the expected state is empty")
+	}
+
+	received := q.Get("state")
+	if len(received) == 0 || subtle.ConstantTimeCompare([]byte(received), []byte(state)) != 1 {
+		return nil, fmt.Errorf("oauth: the state of the callback doesn't match")
+	}
+
+	if e := q.Get("error"); len(e) > 0 {
+		if d := q.Get("error_description"); len(d) > 0 {
+			e = fmt.Sprintf("%s: %s", e, d)
+		}
+		return nil, fmt.Errorf("oauth: access wasn't allowed: %s", e)
+	}
+
+	return c.Exchange(r.Context(), q.Get("code"))
+}
+
+// AuthorizeLocal runs the authorization code flow for command line tools. It listens on the host and port
+// of the RedirectURL, which should be a local address like http://127.0.0.1:8085/callback, calls open
+// with the URL the user has to visit, and waits until Bitly redirects back or the context is done.
+func (c *Config) AuthorizeLocal(ctx context.Context, open func(authURL string) error) (*Token, error) {
+	redirect, err := url.Parse(c.RedirectURL)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: invalid RedirectURL: %s", err.Error())
+	}
+
+	state, err := NewState()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	type result struct {
+		token *Token
+		err   error
+	}
+	results := make(chan result, 1)
+
+	path := redirect.Path
+	if len(path) == 0 {
+		path = "/"
+	}
+
+	callback := c.CallbackHandler(state, func(w http.ResponseWriter, r *http.Request, token *Token, err error) {
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "Connecting your Bitly account failed: %s\n", err.Error())
+		} else {
+			fmt.Fprintf(w, "Your Bitly account %s is connected. You can close this window.\n", token.Login)
+		}
+
+		select {
+		case results <- result{token: token, err: err}:
+		default:
+		}
+	})
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Other requests, like a browser asking for /favicon.ico, must not end the flow
+		if r.URL.Path != path {
+			http.NotFound(w, r)
+			return
+		}
+		callback.ServeHTTP(w, r)
+	})}
+	go srv.Serve(listener)
+	defer func() {
+		// Give the browser the time to receive the response of the callback
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	if err := open(c.AuthCodeURL(state)); err != nil {
+		return nil, err
+	}
+
+	select {
+	case res := <-results:
+		return res.token, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
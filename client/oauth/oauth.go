@@ -0,0 +1,297 @@
+// Package oauth implements Bitly's OAuth 2.0 authorization code flow, so users can connect their own Bitly
+// account to an application.
+//
+// The user is sent to the URL returned by AuthCodeURL. After the user allowed access, Bitly redirects to
+// the RedirectURL with a code, which Exchange trades for an access token:
+//
+//	conf := &oauth.Config{ClientID: "<id>", ClientSecret: "<secret>", RedirectURL: "https://example.org/callback"}
+//	http.Redirect(w, r, conf.AuthCodeURL(state), http.StatusFound)
+//
+//	// In the handler of the RedirectURL
+//	token, err := conf.Exchange(r.Context(), r.URL.Query().Get("code"))
+//	c := client.NewClient().WithTokenSource(token)
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/retgits/bitly/client"
+)
+
+const (
+	// DefaultAuthURL is the page where users allow an application to access their Bitly account
+	DefaultAuthURL = "https://bitly.com/oauth/authorize"
+	// DefaultTokenURL is the endpoint where codes are exchanged for access tokens
+	DefaultTokenURL = "https://api-ssl.bitly.com/oauth/access_token"
+)
+
+// Config contains the registration of an application with Bitly.
+type Config struct {
+	// The client ID of the application
+	ClientID string
+	// The client secret of the application
+	ClientSecret string
+	// The URL Bitly redirects to after the user allowed access, which must match the registration
+	RedirectURL string
+	// The authorization page, defaults to DefaultAuthURL
+	AuthURL string
+	// The token endpoint, defaults to DefaultTokenURL
+	TokenURL string
+	// The client used to call the token endpoint, defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// Token is the result of a successful exchange. Bitly's access tokens don't expire, so there is no
+// refresh token.
+type Token struct {
+	AccessToken string `json:"access_token"`
+	// The login of the user that allowed access
+	Login string `json:"login"`
+}
+
+var _ client.TokenSource = (*Token)(nil)
+
+// Token implements client.TokenSource, so a Token can be used as the TokenSource of a client.
+func (t *Token) Token(ctx context.Context) (string, error) {
+	if t == nil || len(t.AccessToken) == 0 {
+		return "", fmt.Errorf("oauth: the token is empty")
+	}
+	return t.AccessToken, nil
+}
+
+// Error is returned when the token endpoint rejects an exchange.
+type Error struct {
+	// The HTTP status code of the token endpoint
+	StatusCode int
+	// The reason, like INVALID_CLIENT_SECRET or INVALID_CODE, if Bitly sent one
+	Message string
+	// The raw response body
+	Body []byte
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if len(e.Message) > 0 {
+		return fmt.Sprintf("oauth: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("oauth: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+}
+
+// NewState returns a random value for the state parameter, which protects the callback against cross-site
+// request forgery.
+func NewState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// AuthCodeURL returns the URL of the page where the user allows the application to access their Bitly
+// account. The state is sent back to the RedirectURL unchanged.
+func (c *Config) AuthCodeURL(state string) string {
+	authURL := c.AuthURL
+	if len(authURL) == 0 {
+		authURL = DefaultAuthURL
+	}
+
+	v := url.Values{}
+	v.Set("client_id", c.ClientID)
+	v.Set("redirect_uri", c.RedirectURL)
+	if len(state) > 0 {
+		v.Set("state", state)
+	}
+
+	if strings.Contains(authURL, "?") {
+		return authURL + "&" + v.Encode()
+	}
+	return authURL + "?" + v.Encode()
+}
+
+// Exchange trades the code Bitly sent to the RedirectURL for an access token.
+func (c *Config) Exchange(ctx context.Context, code string) (*Token, error) {
+	if len(code) == 0 {
+		return nil, fmt.Errorf("oauth: the code is empty")
+	}
+
+	tokenURL := c.TokenURL
+	if len(tokenURL) == 0 {
+		tokenURL = DefaultTokenURL
+	}
+
+	form := url.Values{}
+	form.Set("client_id", c.ClientID)
+	form.Set("client_secret", c.ClientSecret)
+	form.Set("code", code)
+	form.Set("redirect_uri", c.RedirectURL)
+	form.Set("grant_type", "authorization_code")
+
+	req, err := http.NewRequest(http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, newError(res.StatusCode, body)
+	}
+
+	return parseToken(res.Header.Get("Content-Type"), res.StatusCode, body)
+}
+
+// parseToken reads the token from the response, which is JSON when Bitly honours the Accept header and
+// form encoded otherwise.
+func parseToken(contentType string, statusCode int, body []byte) (*Token, error) {
+	token := &Token{}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" || (len(body) > 0 && body[0] == '{') {
+		var res struct {
+			Token
+			StatusCode int    `json:"status_code"`
+			StatusTxt  string `json:"status_txt"`
+		}
+		if err := json.Unmarshal(body, &res); err != nil {
+			return nil, fmt.Errorf("oauth: reading the token: %s", err.Error())
+		}
+		if res.StatusCode != 0 && res.StatusCode != http.StatusOK {
+			// Bitly's older endpoints report errors in the body with a 200 status code
+			return nil, &Error{StatusCode: res.StatusCode, Message: res.StatusTxt, Body: body}
+		}
+		*token = res.Token
+	} else {
+		v, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, fmt.Errorf("oauth: reading the token: %s", err.Error())
+		}
+		token.AccessToken = v.Get("access_token")
+		token.Login = v.Get("login")
+	}
+
+	if len(token.AccessToken) == 0 {
+		return nil, newError(statusCode, body)
+	}
+	return token, nil
+}
+
+// newError creates the error for a response of the token endpoint.
+func newError(statusCode int, body []byte) *Error {
+	e := &Error{StatusCode: statusCode, Body: body}
+
+	var res struct {
+		StatusTxt string `json:"status_txt"`
+		Message   string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &res); err == nil {
+		e.Message = res.StatusTxt
+		if len(e.Message) == 0 {
+			e.Message = res.Message
+		}
+	} else if len(body) > 0 && len(body) < 200 {
+		e.Message = strings.TrimSpace(string(body))
+	}
+
+	return e
+}
+
+// Store keeps the tokens of the users that connected their Bitly account, by a key chosen by the
+// application, like a tenant or user ID. Implementations must be safe for concurrent use.
+type Store interface {
+	// Load returns the token of the key, and false if there is none
+	Load(ctx context.Context, key string) (*Token, bool, error)
+	// Save stores the token of the key
+	Save(ctx context.Context, key string, token *Token) error
+}
+
+// MemoryStore is a Store which keeps the tokens in memory.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	tokens map[string]*Token
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: make(map[string]*Token)}
+}
+
+// Load returns the token of the key, and false if there is none.
+func (s *MemoryStore) Load(ctx context.Context, key string) (*Token, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token, ok := s.tokens[key]
+	return token, ok, nil
+}
+
+// Save stores the token of the key.
+func (s *MemoryStore) Save(ctx context.Context, key string, token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[key] = token
+	return nil
+}
+
+// NotConnectedError is returned by the TokenSource of a key that has no token in the store.
+type NotConnectedError struct {
+	Key string
+}
+
+// Error implements the error interface.
+func (e *NotConnectedError) Error() string {
+	return fmt.Sprintf("oauth: %s hasn't connected a Bitly account", e.Key)
+}
+
+// storeTokenSource reads the token of a key from a Store.
+type storeTokenSource struct {
+	store Store
+	key   string
+}
+
+// NewTokenSource returns a client.TokenSource which reads the token of the key from the store for every
+// request, so a client keeps working when the user connects their account again.
+func NewTokenSource(store Store, key string) client.TokenSource {
+	return &storeTokenSource{store: store, key: key}
+}
+
+// Token returns the access token of the key.
+func (s *storeTokenSource) Token(ctx context.Context) (string, error) {
+	token, ok, err := s.store.Load(ctx, s.key)
+	if err != nil {
+		return "", err
+	}
+	if !ok || token == nil {
+		return "", &NotConnectedError{Key: s.key}
+	}
+	return token.Token(ctx)
+}
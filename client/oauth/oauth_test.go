@@ -0,0 +1,255 @@
+package oauth_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/oauth"
+	"github.com/retgits/bitly/client/users"
+)
+
+func TestAuthCodeURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		authURL string
+		state   string
+		want    string
+	}{
+		{
+			name:  "default",
+			state: "s1",
+			want:  "https://bitly.com/oauth/authorize?client_id=id&redirect_uri=https%3A%2F%2Fexample.org%2Fcallback&state=s1",
+		},
+		{
+			name: "without state",
+			want: "https://bitly.com/oauth/authorize?client_id=id&redirect_uri=https%3A%2F%2Fexample.org%2Fcallback",
+		},
+		{
+			name:    "auth URL with a query",
+			authURL: "https://example.org/authorize?a=b",
+			state:   "s1",
+			want:    "https://example.org/authorize?a=b&client_id=id&redirect_uri=https%3A%2F%2Fexample.org%2Fcallback&state=s1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &oauth.Config{ClientID: "id", RedirectURL: "https://example.org/callback", AuthURL: tt.authURL}
+			if got := conf.AuthCodeURL(tt.state); got != tt.want {
+				t.Errorf("AuthCodeURL() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExchange(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		wantErr string
+	}{
+		{name: "valid code", code: "code-1"},
+		{name: "unknown code", code: "code-2", wantErr: "oauth: 400 INVALID_CODE"},
+		{name: "empty code", wantErr: "oauth: the code is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			srv.AddAuthorizationCode("code-1", "user-token")
+
+			conf := &oauth.Config{ClientID: "id", ClientSecret: "secret", TokenURL: srv.TokenURL}
+			token, err := conf.Exchange(context.Background(), tt.code)
+
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("Exchange() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() returned %s", err.Error())
+			}
+			if token.AccessToken != "user-token" {
+				t.Errorf("AccessToken = %s, want user-token", token.AccessToken)
+			}
+
+			// The token can be used by a client, and a code can only be used once
+			c := client.NewClient().WithTokenSource(token).WithBaseURL(srv.URL)
+			if _, err := users.New(c).RetrieveUser(); err != nil {
+				t.Errorf("RetrieveUser() with the token returned %s", err.Error())
+			}
+			if _, err := conf.Exchange(context.Background(), tt.code); err == nil {
+				t.Errorf("Exchange() accepted a code twice")
+			}
+		})
+	}
+}
+
+func TestCallbackHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		query    url.Values
+		wantErr  string
+	}{
+		{
+			name:     "matching state",
+			expected: "s1",
+			query:    url.Values{"state": {"s1"}, "code": {"code-1"}},
+		},
+		{
+			name:    "empty expected state",
+			query:   url.Values{"code": {"code-1"}},
+			wantErr: "oauth: the expected state is empty",
+		},
+		{
+			name:     "missing state",
+			expected: "s1",
+			query:    url.Values{"code": {"code-1"}},
+			wantErr:  "oauth: the state of the callback doesn't match",
+		},
+		{
+			name:     "other state",
+			expected: "s1",
+			query:    url.Values{"state": {"s2"}, "code": {"code-1"}},
+			wantErr:  "oauth: the state of the callback doesn't match",
+		},
+		{
+			name:     "access denied",
+			expected: "s1",
+			query:    url.Values{"state": {"s1"}, "error": {"access_denied"}, "error_description": {"The user said no"}},
+			wantErr:  "oauth: access wasn't allowed: access_denied: The user said no",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			srv.AddAuthorizationCode("code-1", "user-token")
+
+			var token *oauth.Token
+			var err error
+			conf := &oauth.Config{ClientID: "id", ClientSecret: "secret", TokenURL: srv.TokenURL}
+			h := conf.CallbackHandler(tt.expected, func(w http.ResponseWriter, r *http.Request, tok *oauth.Token, e error) {
+				token, err = tok, e
+			})
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/callback?"+tt.query.Encode(), nil))
+
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("the handler returned %v, want %s", err, tt.wantErr)
+				}
+				if len(srv.Requests()) != 0 {
+					t.Errorf("the code was exchanged after a failed check")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("the handler returned %s", err.Error())
+			}
+			if token.AccessToken != "user-token" {
+				t.Errorf("AccessToken = %s, want user-token", token.AccessToken)
+			}
+		})
+	}
+}
+
+func TestCallbackHandlerDefaultPage(t *testing.T) {
+	conf := &oauth.Config{}
+	rec := httptest.NewRecorder()
+	conf.CallbackHandler("s1", nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/callback?state=s2", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("the handler wrote status %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestStoreTokenSource(t *testing.T) {
+	store := oauth.NewMemoryStore()
+	source := oauth.NewTokenSource(store, "tenant")
+
+	_, err := source.Token(context.Background())
+	if _, ok := err.(*oauth.NotConnectedError); !ok {
+		t.Errorf("Token() of a key without a token = %v, want a *NotConnectedError", err)
+	}
+
+	// The store is read for every request, so a new token is used right away
+	for _, want := range []string{"token-1", "token-2"} {
+		if err := store.Save(context.Background(), "tenant", &oauth.Token{AccessToken: want}); err != nil {
+			t.Fatal(err)
+		}
+		got, err := source.Token(context.Background())
+		if err != nil || got != want {
+			t.Errorf("Token() = %s, %v, want %s", got, err, want)
+		}
+	}
+}
+
+func TestAuthorizeLocal(t *testing.T) {
+	// Requests to other paths, like a browser asking for /favicon.ico, get a 404 and don't end the flow
+	for _, path := range []string{"/callback", "/"} {
+		t.Run(path, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			srv.AddAuthorizationCode("code-1", "user-token")
+
+			// Reserve a free port for the redirect
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			base := "http://" + listener.Addr().String()
+			listener.Close()
+
+			conf := &oauth.Config{ClientID: "id", RedirectURL: base + path, TokenURL: srv.TokenURL}
+			open := func(authURL string) error {
+				u, err := url.Parse(authURL)
+				if err != nil {
+					return err
+				}
+
+				go func() {
+					res, err := http.Get(base + "/favicon.ico")
+					if err != nil {
+						t.Errorf("requesting /favicon.ico returned %s", err.Error())
+						return
+					}
+					res.Body.Close()
+					if res.StatusCode != http.StatusNotFound {
+						t.Errorf("/favicon.ico returned %d, want 404", res.StatusCode)
+					}
+
+					q := url.Values{"state": {u.Query().Get("state")}, "code": {"code-1"}}
+					res, err = http.Get(base + path + "?" + q.Encode())
+					if err != nil {
+						t.Errorf("the callback returned %s", err.Error())
+						return
+					}
+					res.Body.Close()
+				}()
+				return nil
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			token, err := conf.AuthorizeLocal(ctx, open)
+			if err != nil {
+				t.Fatalf("AuthorizeLocal() returned %s", err.Error())
+			}
+			if token.AccessToken != "user-token" {
+				t.Errorf("AccessToken = %s, want user-token", token.AccessToken)
+			}
+		})
+	}
+}
@@ -1,7 +1,12 @@
 // Package organizations contains the methods to interact with the Organizations in Bitly
 package organizations
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/retgits/bitly"
+	"github.com/retgits/bitly/client/metrics"
+)
 
 // BitlyOrganizations is a toplevel struct containing all organizations
 type BitlyOrganizations struct {
@@ -10,13 +15,16 @@ type BitlyOrganizations struct {
 
 // Metrics contains the data for a metrics request
 type Metrics struct {
-	UnitReference string   `json:"unit_reference"`
-	Metrics       []Metric `json:"metrics"`
-	Units         int64    `json:"units"`
-	Unit          string   `json:"unit"`
-	Facet         string   `json:"facet"`
+	UnitReference bitly.Time `json:"unit_reference"`
+	Metrics       []Metric   `json:"metrics"`
+	Units         int64      `json:"units"`
+	Unit          string     `json:"unit"`
+	Facet         string     `json:"facet"`
 }
 
+// MetricsRequest is used to generate the metrics request to Bitly
+type MetricsRequest = metrics.Request
+
 // Metric contains data on the chosen metric
 type Metric struct {
 	Value int64  `json:"value"`
@@ -25,8 +33,8 @@ type Metric struct {
 
 // OrganizationDetails contains detailed information on the organization
 type OrganizationDetails struct {
-	Created         string        `json:"created"`
-	Modified        string        `json:"modified"`
+	Created         bitly.Time    `json:"created"`
+	Modified        bitly.Time    `json:"modified"`
 	Bsds            []interface{} `json:"bsds"`
 	GUID            string        `json:"guid"`
 	Name            string        `json:"name"`
@@ -0,0 +1,52 @@
+package organizations_test
+
+import (
+	"testing"
+
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/metrics"
+	"github.com/retgits/bitly/client/organizations"
+)
+
+func TestRetrieveOrganizationShortenCounts(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  *organizations.MetricsRequest
+		query    string
+		errField string
+	}{
+		{name: "no request", query: ""},
+		{name: "unit", request: &organizations.MetricsRequest{Unit: metrics.UnitDay, Units: 7}, query: "unit=day&units=7"},
+		{name: "invalid unit", request: &organizations.MetricsRequest{Unit: "year"}, errField: "unit"},
+		{name: "invalid units", request: &organizations.MetricsRequest{Units: -2}, errField: "units"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+
+			res, err := organizations.New(srv.Client()).RetrieveOrganizationShortenCounts(bitlytest.DefaultOrganizationGUID, tt.request)
+
+			if len(tt.errField) > 0 {
+				validationErr, ok := err.(*metrics.ValidationError)
+				if !ok || validationErr.Field != tt.errField {
+					t.Errorf("RetrieveOrganizationShortenCounts() = %v, want a *ValidationError of %s", err, tt.errField)
+				}
+				if got := len(srv.Requests()); got != 0 {
+					t.Errorf("sent %d requests for an invalid request", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RetrieveOrganizationShortenCounts() returned %s", err.Error())
+			}
+			if res.Facet != "shorten_counts" {
+				t.Errorf("Facet = %s, want shorten_counts", res.Facet)
+			}
+			if got := srv.Requests()[0].Query.Encode(); got != tt.query {
+				t.Errorf("sent the query %s, want %s", got, tt.query)
+			}
+		})
+	}
+}
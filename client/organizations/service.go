@@ -2,10 +2,12 @@
 package organizations
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 
 	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/metrics"
 )
 
 const (
@@ -14,11 +16,26 @@ const (
 	organizationShortenCountsEndpoint = "organizations/%s/shorten_counts"
 )
 
+// Service is the interface implemented by Organizations. Code that depends on Service, instead of *Organizations, can use a
+// mock in its unit tests.
+type Service interface {
+	RetrieveOrganizationDetails(organizationGUID string) (OrganizationDetails, error)
+	RetrieveOrganizationDetailsContext(ctx context.Context, organizationGUID string) (OrganizationDetails, error)
+
+	RetrieveOrganizations() (BitlyOrganizations, error)
+	RetrieveOrganizationsContext(ctx context.Context) (BitlyOrganizations, error)
+
+	RetrieveOrganizationShortenCounts(organizationGUID string, input *MetricsRequest) (Metrics, error)
+	RetrieveOrganizationShortenCountsContext(ctx context.Context, organizationGUID string, input *MetricsRequest) (Metrics, error)
+}
+
 // Organizations are part of our hierarchy. This is the top level where a group and user will belong.
 type Organizations struct {
 	*client.Client
 }
 
+var _ Service = (*Organizations)(nil)
+
 // New creates a new instance of the Organizations client.
 func New(c *client.Client) *Organizations {
 	return &Organizations{
@@ -28,7 +45,12 @@ func New(c *client.Client) *Organizations {
 
 // RetrieveOrganizationDetails is to retrieve details for an organization
 func (o *Organizations) RetrieveOrganizationDetails(organizationGUID string) (OrganizationDetails, error) {
-	data, err := o.Call(fmt.Sprintf(organizationDetailsEndpoint, organizationGUID), http.MethodGet, nil)
+	return o.RetrieveOrganizationDetailsContext(context.Background(), organizationGUID)
+}
+
+// RetrieveOrganizationDetailsContext is the same as RetrieveOrganizationDetails, but uses the context to control the lifetime of the request.
+func (o *Organizations) RetrieveOrganizationDetailsContext(ctx context.Context, organizationGUID string) (OrganizationDetails, error) {
+	data, err := o.CallContext(ctx, fmt.Sprintf(organizationDetailsEndpoint, organizationGUID), http.MethodGet, nil)
 	if err != nil {
 		return OrganizationDetails{}, err
 	}
@@ -38,7 +60,12 @@ func (o *Organizations) RetrieveOrganizationDetails(organizationGUID string) (Or
 
 // RetrieveOrganizations is to retrieve all organizations
 func (o *Organizations) RetrieveOrganizations() (BitlyOrganizations, error) {
-	data, err := o.Call(organizationsEndpoint, http.MethodGet, nil)
+	return o.RetrieveOrganizationsContext(context.Background())
+}
+
+// RetrieveOrganizationsContext is the same as RetrieveOrganizations, but uses the context to control the lifetime of the request.
+func (o *Organizations) RetrieveOrganizationsContext(ctx context.Context) (BitlyOrganizations, error) {
+	data, err := o.CallContext(ctx, organizationsEndpoint, http.MethodGet, nil)
 	if err != nil {
 		return BitlyOrganizations{}, err
 	}
@@ -47,8 +74,18 @@ func (o *Organizations) RetrieveOrganizations() (BitlyOrganizations, error) {
 }
 
 // RetrieveOrganizationShortenCounts is to retrieve all the shorten counts for a specific organization
-func (o *Organizations) RetrieveOrganizationShortenCounts(organizationGUID string) (Metrics, error) {
-	data, err := o.Call(fmt.Sprintf(organizationShortenCountsEndpoint, organizationGUID), http.MethodGet, nil)
+func (o *Organizations) RetrieveOrganizationShortenCounts(organizationGUID string, input *MetricsRequest) (Metrics, error) {
+	return o.RetrieveOrganizationShortenCountsContext(context.Background(), organizationGUID, input)
+}
+
+// RetrieveOrganizationShortenCountsContext is the same as RetrieveOrganizationShortenCounts, but uses the context to control the lifetime of the request.
+func (o *Organizations) RetrieveOrganizationShortenCountsContext(ctx context.Context, organizationGUID string, input *MetricsRequest) (Metrics, error) {
+	url, err := metrics.Encode(fmt.Sprintf(organizationShortenCountsEndpoint, organizationGUID), input)
+	if err != nil {
+		return Metrics{}, err
+	}
+
+	data, err := o.CallContext(ctx, url, http.MethodGet, nil)
 	if err != nil {
 		return Metrics{}, err
 	}
@@ -0,0 +1,113 @@
+package pool
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/retgits/bitly/client"
+)
+
+// ErrorBudget limits the number of failed requests of a tenant. When a tenant has more than MaxErrors failed
+// requests within the Window, all requests of the tenant fail immediately for the Cooldown, so a tenant with
+// a revoked token or a broken integration doesn't use the capacity of the others.
+type ErrorBudget struct {
+	// The number of failed requests allowed within the window
+	MaxErrors int
+	// The period over which failed requests are counted
+	Window time.Duration
+	// The time requests fail immediately after the budget is exceeded
+	Cooldown time.Duration
+}
+
+// BudgetExceededError is returned for the requests of a tenant that exceeded its ErrorBudget.
+type BudgetExceededError struct {
+	Tenant string
+	// The time at which requests are sent again
+	Until time.Time
+}
+
+// Error implements the error interface.
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("pool: tenant %s exceeded its error budget until %s", e.Tenant, e.Until.Format(time.RFC3339))
+}
+
+// Retryable returns false, so the client doesn't retry a request that was rejected during the cooldown.
+func (e *BudgetExceededError) Retryable() bool {
+	return false
+}
+
+// budget tracks the failed requests of a single tenant.
+type budget struct {
+	limit  ErrorBudget
+	tenant string
+	now    func() time.Time
+
+	mu     sync.Mutex
+	errors []time.Time
+	until  time.Time
+}
+
+func newBudget(tenant string, limit ErrorBudget) *budget {
+	return &budget{
+		limit:  limit,
+		tenant: tenant,
+		now:    time.Now,
+	}
+}
+
+// check returns a BudgetExceededError while the tenant is in its cooldown.
+func (b *budget) check() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.now().Before(b.until) {
+		return &BudgetExceededError{Tenant: b.tenant, Until: b.until}
+	}
+	return nil
+}
+
+// record counts a failed request, and starts the cooldown when there are too many.
+func (b *budget) record() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	start := now.Add(-b.limit.Window)
+
+	kept := b.errors[:0]
+	for _, t := range b.errors {
+		if t.After(start) {
+			kept = append(kept, t)
+		}
+	}
+	b.errors = append(kept, now)
+
+	if len(b.errors) > b.limit.MaxErrors {
+		b.until = now.Add(b.limit.Cooldown)
+		b.errors = b.errors[:0]
+	}
+}
+
+// middleware fails every attempt during the cooldown without sending it, and records every attempt that
+// failed because of a network error, a rejected token, a rate limit or a server error.
+// Other errors, like a Bitlink that doesn't exist, are caused by the request and don't count.
+func (b *budget) middleware(next client.Doer) client.Doer {
+	return client.DoerFunc(func(req *http.Request) (*http.Response, error) {
+		if err := b.check(); err != nil {
+			return nil, err
+		}
+
+		res, err := next.Do(req)
+
+		if err != nil && req.Context().Err() == nil {
+			b.record()
+		} else if res != nil && (res.StatusCode == http.StatusTooManyRequests || res.StatusCode == http.StatusUnauthorized ||
+			res.StatusCode == http.StatusForbidden || res.StatusCode >= 500) {
+			b.record()
+		}
+
+		return res, err
+	})
+}
@@ -0,0 +1,255 @@
+// Package pool manages a configured client per tenant, for applications that use the Bitly accounts of many
+// customers.
+//
+// All clients share a single transport, so connections to Bitly are reused, while every tenant has its own
+// token, rate limiter and error budget. The pool lives in its own package, because the services import the
+// client package.
+//
+//	p := pool.New(&pool.Options{
+//		Tokens:     func(tenant string) (client.TokenSource, error) { return oauth.NewTokenSource(store, tenant), nil },
+//		RateLimits: map[client.EndpointClass]client.RateLimit{client.EndpointClassShorten: {Requests: 100, Per: time.Minute}},
+//	})
+//	link, err := p.Bitlinks("customer-1").ShortenLink(&bitlinks.ShortenRequest{LongURL: "https://example.org"})
+package pool
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bsds"
+	"github.com/retgits/bitly/client/campaigns"
+	"github.com/retgits/bitly/client/custombitlinks"
+	"github.com/retgits/bitly/client/groups"
+	"github.com/retgits/bitly/client/organizations"
+	"github.com/retgits/bitly/client/users"
+)
+
+// TokenFunc returns the TokenSource of a tenant that wasn't registered.
+type TokenFunc func(tenant string) (client.TokenSource, error)
+
+// Options contains the configuration shared by the clients of all tenants.
+type Options struct {
+	// Tokens looks up the TokenSource of tenants that weren't registered. When nil, only registered tenants
+	// can be used.
+	Tokens TokenFunc
+	// Transport is shared by the clients of all tenants. When nil, a transport with sensible timeouts is used.
+	Transport http.RoundTripper
+	// Timeout is the timeout of every request. When zero, requests don't time out.
+	Timeout time.Duration
+	// RetryPolicy is the retry policy of every client. When nil, each request is attempted once.
+	RetryPolicy *client.RetryPolicy
+	// RateLimits are the limits of each tenant. Every tenant gets its own RateLimiter with these limits.
+	RateLimits map[client.EndpointClass]client.RateLimit
+	// ErrorBudget limits the failed requests of each tenant. When nil, failed requests aren't limited.
+	ErrorBudget *ErrorBudget
+	// Middlewares are added to every client, after the middleware of the error budget.
+	Middlewares []client.Middleware
+	// BaseURL is the base URL of every client. When empty, client.BitlyBaseURL is used.
+	BaseURL string
+	// UserAgent is the User-Agent of every client. When empty, client.DefaultUserAgent is used.
+	UserAgent string
+}
+
+// Pool holds a client per tenant. It is safe for concurrent use.
+type Pool struct {
+	options    Options
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	sources map[string]client.TokenSource
+	tenants map[string]*tenant
+}
+
+// tenant is the client of a tenant with its budget.
+type tenant struct {
+	client *client.Client
+	budget *budget
+}
+
+// New creates an empty Pool.
+func New(options *Options) *Pool {
+	p := &Pool{
+		sources: make(map[string]client.TokenSource),
+		tenants: make(map[string]*tenant),
+	}
+
+	if options != nil {
+		p.options = *options
+	}
+
+	transport := p.options.Transport
+	if transport == nil {
+		transport = newTransport()
+	}
+	p.httpClient = &http.Client{Transport: transport, Timeout: p.options.Timeout}
+
+	return p
+}
+
+// newTransport creates a transport which keeps enough idle connections to Bitly for many tenants.
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
+
+// Register sets the TokenSource of the tenant. A client that already exists for the tenant uses the new
+// TokenSource from its next request.
+func (p *Pool) Register(tenantID string, source client.TokenSource) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.sources[tenantID] = source
+}
+
+// RegisterToken sets a static access token for the tenant.
+func (p *Pool) RegisterToken(tenantID string, accessToken string) {
+	p.Register(tenantID, client.StaticTokenSource(accessToken))
+}
+
+// Remove removes the tenant and its client from the pool.
+func (p *Pool) Remove(tenantID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.sources, tenantID)
+	delete(p.tenants, tenantID)
+}
+
+// Tenants returns the IDs of the tenants that have a client, sorted.
+func (p *Pool) Tenants() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := make([]string, 0, len(p.tenants))
+	for id := range p.tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Client returns the client of the tenant, which is created on first use. The token of the tenant is looked
+// up for every request, so requests of an unknown tenant fail with an error instead of this method.
+func (p *Pool) Client(tenantID string) *client.Client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if t, ok := p.tenants[tenantID]; ok {
+		return t.client
+	}
+
+	t := &tenant{}
+	c := client.NewClient().
+		WithHTTPClient(p.httpClient).
+		WithRetryPolicy(p.options.RetryPolicy).
+		WithBaseURL(p.options.BaseURL).
+		WithUserAgent(p.options.UserAgent).
+		WithTokenSource(client.TokenSourceFunc(func(ctx context.Context) (string, error) {
+			return p.token(ctx, tenantID)
+		}))
+
+	if p.options.RateLimits != nil {
+		c = c.WithRateLimiter(client.NewRateLimiter(p.options.RateLimits))
+	}
+
+	if p.options.ErrorBudget != nil {
+		t.budget = newBudget(tenantID, *p.options.ErrorBudget)
+		c = c.Use(t.budget.middleware)
+	}
+	c = c.Use(p.options.Middlewares...)
+
+	t.client = c
+	p.tenants[tenantID] = t
+	return c
+}
+
+// token returns the access token of the tenant.
+func (p *Pool) token(ctx context.Context, tenantID string) (string, error) {
+	source, err := p.source(tenantID)
+	if err != nil {
+		return "", err
+	}
+	return source.Token(ctx)
+}
+
+// source returns the TokenSource of the tenant, and looks it up using the Tokens option when the tenant
+// wasn't registered.
+func (p *Pool) source(tenantID string) (client.TokenSource, error) {
+	p.mu.Lock()
+	source, ok := p.sources[tenantID]
+	p.mu.Unlock()
+
+	if ok {
+		return source, nil
+	}
+
+	if p.options.Tokens == nil {
+		return nil, fmt.Errorf("pool: unknown tenant %s", tenantID)
+	}
+
+	source, err := p.options.Tokens(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if existing, ok := p.sources[tenantID]; ok {
+		return existing, nil
+	}
+	p.sources[tenantID] = source
+	return source, nil
+}
+
+// Bitlinks returns the Bitlinks service of the tenant.
+func (p *Pool) Bitlinks(tenantID string) *bitlinks.Bitlinks {
+	return bitlinks.New(p.Client(tenantID))
+}
+
+// BSDs returns the BSDs service of the tenant.
+func (p *Pool) BSDs(tenantID string) *bsds.BSDs {
+	return bsds.New(p.Client(tenantID))
+}
+
+// Campaigns returns the Campaigns service of the tenant.
+func (p *Pool) Campaigns(tenantID string) *campaigns.Campaigns {
+	return campaigns.New(p.Client(tenantID))
+}
+
+// CustomBitlinks returns the CustomBitlinks service of the tenant.
+func (p *Pool) CustomBitlinks(tenantID string) *custombitlinks.CustomBitlinks {
+	return custombitlinks.New(p.Client(tenantID))
+}
+
+// Groups returns the Groups service of the tenant.
+func (p *Pool) Groups(tenantID string) *groups.Groups {
+	return groups.New(p.Client(tenantID))
+}
+
+// Organizations returns the Organizations service of the tenant.
+func (p *Pool) Organizations(tenantID string) *organizations.Organizations {
+	return organizations.New(p.Client(tenantID))
+}
+
+// Users returns the Users service of the tenant.
+func (p *Pool) Users(tenantID string) *users.Users {
+	return users.New(p.Client(tenantID))
+}
@@ -0,0 +1,213 @@
+package pool_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/bitlytest"
+	"github.com/retgits/bitly/client/pool"
+)
+
+func TestPoolTokens(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	// Accept every token, so the token of each tenant can be checked in the requests
+	srv.AccessToken = ""
+
+	var mu sync.Mutex
+	lookups := make(map[string]int)
+	p := pool.New(&pool.Options{
+		BaseURL: srv.URL,
+		Tokens: func(tenant string) (client.TokenSource, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			lookups[tenant]++
+			if strings.HasPrefix(tenant, "lazy") {
+				return client.StaticTokenSource("token-" + tenant), nil
+			}
+			return nil, fmt.Errorf("no token for %s", tenant)
+		},
+	})
+	p.RegisterToken("a", "token-a")
+	p.RegisterToken("b", "token-b")
+
+	tests := []struct {
+		tenant  string
+		token   string
+		wantErr string
+	}{
+		{tenant: "a", token: "token-a"},
+		{tenant: "b", token: "token-b"},
+		{tenant: "lazy1", token: "token-lazy1"},
+		{tenant: "lazy1", token: "token-lazy1"},
+		{tenant: "unknown", wantErr: "no token for unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tenant, func(t *testing.T) {
+			srv.ResetRequests()
+			_, err := p.Users(tt.tenant).RetrieveUser()
+
+			if len(tt.wantErr) > 0 {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("RetrieveUser() = %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RetrieveUser() returned %s", err.Error())
+			}
+
+			requests := srv.Requests()
+			if len(requests) != 1 {
+				t.Fatalf("sent %d requests, want 1", len(requests))
+			}
+			if got := requests[0].Header.Get("Authorization"); got != "Bearer "+tt.token {
+				t.Errorf("Authorization = %s, want Bearer %s", got, tt.token)
+			}
+		})
+	}
+
+	if lookups["lazy1"] != 1 {
+		t.Errorf("the token of lazy1 was looked up %d times, want 1", lookups["lazy1"])
+	}
+	if lookups["a"] != 0 {
+		t.Errorf("the token of a registered tenant was looked up")
+	}
+
+	want := "a,b,lazy1,unknown"
+	if got := strings.Join(p.Tenants(), ","); got != want {
+		t.Errorf("Tenants() = %s, want %s", got, want)
+	}
+}
+
+func TestPoolUnknownTenant(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+
+	p := pool.New(&pool.Options{BaseURL: srv.URL})
+	p.RegisterToken("a", bitlytest.DefaultAccessToken)
+
+	if _, err := p.Users("a").RetrieveUser(); err != nil {
+		t.Fatalf("RetrieveUser() returned %s", err.Error())
+	}
+
+	p.Remove("a")
+	_, err := p.Users("a").RetrieveUser()
+	if err == nil || err.Error() != "pool: unknown tenant a" {
+		t.Errorf("RetrieveUser() of a removed tenant = %v", err)
+	}
+}
+
+func TestPoolReusesClients(t *testing.T) {
+	p := pool.New(nil)
+	if p.Client("a") != p.Client("a") {
+		t.Errorf("Client() returned a new client for the same tenant")
+	}
+	if p.Client("a") == p.Client("b") {
+		t.Errorf("Client() returned the same client for two tenants")
+	}
+}
+
+func TestErrorBudget(t *testing.T) {
+	tests := []struct {
+		name     string
+		fault    bitlytest.Fault
+		calls    int
+		requests int
+		exceeded bool
+	}{
+		{
+			name:     "server errors",
+			fault:    bitlytest.Fault{StatusCode: http.StatusInternalServerError},
+			calls:    4,
+			requests: 3,
+			exceeded: true,
+		},
+		{
+			name:     "rejected token",
+			fault:    bitlytest.Fault{StatusCode: http.StatusForbidden},
+			calls:    4,
+			requests: 3,
+			exceeded: true,
+		},
+		{
+			name:     "errors caused by the request don't count",
+			fault:    bitlytest.Fault{StatusCode: http.StatusNotFound},
+			calls:    4,
+			requests: 4,
+		},
+		{
+			name:     "within the budget",
+			fault:    bitlytest.Fault{StatusCode: http.StatusInternalServerError, Times: 2},
+			calls:    4,
+			requests: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+			srv.InjectFault(tt.fault)
+
+			p := pool.New(&pool.Options{
+				BaseURL:     srv.URL,
+				ErrorBudget: &pool.ErrorBudget{MaxErrors: 2, Window: time.Minute, Cooldown: time.Minute},
+			})
+			p.RegisterToken("a", bitlytest.DefaultAccessToken)
+			p.RegisterToken("b", bitlytest.DefaultAccessToken)
+
+			var err error
+			for i := 0; i < tt.calls; i++ {
+				_, err = p.Users("a").RetrieveUser()
+			}
+
+			_, exceeded := err.(*pool.BudgetExceededError)
+			if exceeded != tt.exceeded {
+				t.Errorf("the last call returned %v, want a *BudgetExceededError: %t", err, tt.exceeded)
+			}
+			if got := len(srv.Requests()); got != tt.requests {
+				t.Errorf("sent %d requests, want %d", got, tt.requests)
+			}
+
+			// The budget of a tenant doesn't affect the others
+			srv.ClearFaults()
+			if _, err := p.Users("b").RetrieveUser(); err != nil {
+				t.Errorf("RetrieveUser() of another tenant returned %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestErrorBudgetIsNotRetried(t *testing.T) {
+	srv := bitlytest.NewServer()
+	defer srv.Close()
+	srv.InjectFault(bitlytest.Fault{StatusCode: http.StatusServiceUnavailable})
+
+	p := pool.New(&pool.Options{
+		BaseURL:     srv.URL,
+		RetryPolicy: &client.RetryPolicy{MaxAttempts: 10, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond},
+		ErrorBudget: &pool.ErrorBudget{MaxErrors: 1, Window: time.Minute, Cooldown: time.Minute},
+	})
+	p.RegisterToken("a", bitlytest.DefaultAccessToken)
+
+	// The budget is checked before every attempt, so the retries stop as soon as it is exceeded
+	_, err := p.Users("a").RetrieveUserContext(context.Background())
+	budgetErr, ok := err.(*pool.BudgetExceededError)
+	if !ok || budgetErr.Tenant != "a" {
+		t.Fatalf("RetrieveUser() = %v, want a *BudgetExceededError", err)
+	}
+	if got := len(srv.Requests()); got != 2 {
+		t.Errorf("sent %d requests, want 2", got)
+	}
+	if !strings.HasPrefix(err.Error(), "pool: tenant a exceeded its error budget until ") {
+		t.Errorf("Error() = %s", err.Error())
+	}
+}
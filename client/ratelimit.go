@@ -0,0 +1,202 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointClass groups the Bitly endpoints that share a rate limit.
+type EndpointClass string
+
+const (
+	// EndpointClassShorten are the requests that create new Bitlinks
+	EndpointClassShorten EndpointClass = "shorten"
+	// EndpointClassMetrics are the requests that retrieve click and shorten metrics
+	EndpointClassMetrics EndpointClass = "metrics"
+	// EndpointClassReads are all other requests that retrieve data
+	EndpointClassReads EndpointClass = "reads"
+	// EndpointClassWrites are all other requests that modify data
+	EndpointClassWrites EndpointClass = "writes"
+)
+
+// metricsSegments are the path segments that identify an endpoint as a metrics endpoint.
+var metricsSegments = []string{"clicks", "countries", "country", "referrers", "referrers_by_domains", "referring_domains", "referring_networks", "shorten_counts"}
+
+// RateLimit is the number of requests that can be sent to Bitly in a period of time.
+type RateLimit struct {
+	// The number of requests allowed per period
+	Requests int
+	// The length of the period
+	Per time.Duration
+	// The maximum number of requests that can be sent at once. Defaults to 1.
+	Burst int
+}
+
+// RateLimiter is a token bucket limiter which blocks requests to Bitly until the budget of their
+// EndpointClass allows them to be sent. The limiter adapts itself to the X-RateLimit-* headers in
+// the responses from Bitly.
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[EndpointClass]*bucket
+}
+
+type bucket struct {
+	// tokens per nanosecond
+	rate         float64
+	capacity     float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter. Requests for endpoint classes without a RateLimit are
+// only limited by the rate limit headers sent by Bitly.
+func NewRateLimiter(limits map[EndpointClass]RateLimit) *RateLimiter {
+	r := &RateLimiter{
+		buckets: make(map[EndpointClass]*bucket),
+	}
+
+	for class, limit := range limits {
+		if limit.Requests <= 0 || limit.Per <= 0 {
+			continue
+		}
+
+		capacity := float64(limit.Burst)
+		if capacity < 1 {
+			capacity = 1
+		}
+
+		r.buckets[class] = &bucket{
+			rate:     float64(limit.Requests) / float64(limit.Per),
+			capacity: capacity,
+			tokens:   capacity,
+			last:     time.Now(),
+		}
+	}
+
+	return r
+}
+
+// Wait blocks until a request of the endpoint class can be sent, or until the context is done.
+func (r *RateLimiter) Wait(ctx context.Context, class EndpointClass) error {
+	for {
+		d := r.reserve(class)
+		if d == 0 {
+			return nil
+		}
+
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// Update adjusts the budget of the endpoint class using the rate limit headers sent by Bitly.
+func (r *RateLimiter) Update(class EndpointClass, header http.Header) {
+	if header == nil {
+		return
+	}
+
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	hasRemaining := err == nil
+	if !hasRemaining && len(header.Get("Retry-After")) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := r.bucket(class)
+
+	if !hasRemaining || remaining <= 0 {
+		if d, ok := retryAfter(header); ok {
+			b.blockedUntil = time.Now().Add(d)
+		}
+		b.tokens = 0
+		return
+	}
+
+	if float64(remaining) < b.tokens {
+		b.tokens = float64(remaining)
+	}
+}
+
+// reserve takes a token from the bucket of the endpoint class, or returns how long to wait before
+// trying again.
+func (r *RateLimiter) reserve(class EndpointClass) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b := r.bucket(class)
+	now := time.Now()
+
+	if now.Before(b.blockedUntil) {
+		return b.blockedUntil.Sub(now)
+	}
+
+	if b.rate == 0 {
+		// Without a configured limit, the bucket only blocks while Bitly tells us to
+		return 0
+	}
+
+	b.tokens += float64(now.Sub(b.last)) * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+
+	return time.Duration((1-b.tokens)/b.rate) + 1
+}
+
+// bucket returns the bucket for the endpoint class, creating an unlimited one if it doesn't exist.
+// The caller must hold the lock.
+func (r *RateLimiter) bucket(class EndpointClass) *bucket {
+	b, ok := r.buckets[class]
+	if !ok {
+		b = &bucket{last: time.Now()}
+		r.buckets[class] = b
+	}
+	return b
+}
+
+// ClassifyEndpoint returns the EndpointClass of a request to Bitly.
+func ClassifyEndpoint(urlSuffix string, httpMethod string) EndpointClass {
+	path := urlSuffix
+	if idx := strings.Index(path, "?"); idx >= 0 {
+		path = path[:idx]
+	}
+
+	if path == "shorten" || (path == "bitlinks" && httpMethod == http.MethodPost) {
+		return EndpointClassShorten
+	}
+
+	if path == "expand" {
+		return EndpointClassReads
+	}
+
+	if httpMethod == http.MethodGet {
+		segments := strings.Split(path, "/")
+		if len(segments) > 1 && segments[len(segments)-2] == "clicks" {
+			return EndpointClassMetrics
+		}
+
+		last := segments[len(segments)-1]
+		for _, s := range metricsSegments {
+			if last == s {
+				return EndpointClassMetrics
+			}
+		}
+		return EndpointClassReads
+	}
+
+	return EndpointClassWrites
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClassifyEndpoint(t *testing.T) {
+	tests := []struct {
+		urlSuffix string
+		method    string
+		want      EndpointClass
+	}{
+		{urlSuffix: "shorten", method: http.MethodPost, want: EndpointClassShorten},
+		{urlSuffix: "bitlinks", method: http.MethodPost, want: EndpointClassShorten},
+		{urlSuffix: "expand", method: http.MethodPost, want: EndpointClassReads},
+		{urlSuffix: "bitlinks/bit.ly/2VNw1Ib", method: http.MethodGet, want: EndpointClassReads},
+		{urlSuffix: "bitlinks/bit.ly/2VNw1Ib", method: http.MethodPatch, want: EndpointClassWrites},
+		{urlSuffix: "bitlinks/bit.ly/2VNw1Ib/clicks", method: http.MethodGet, want: EndpointClassMetrics},
+		{urlSuffix: "bitlinks/bit.ly/2VNw1Ib/clicks/summary", method: http.MethodGet, want: EndpointClassMetrics},
+		{urlSuffix: "bitlinks/bit.ly/2VNw1Ib/countries?unit=day", method: http.MethodGet, want: EndpointClassMetrics},
+		{urlSuffix: "groups/Ba1bc23dE4F/shorten_counts", method: http.MethodGet, want: EndpointClassMetrics},
+		{urlSuffix: "groups/Ba1bc23dE4F/bitlinks?size=50", method: http.MethodGet, want: EndpointClassReads},
+		{urlSuffix: "groups/Ba1bc23dE4F", method: http.MethodDelete, want: EndpointClassWrites},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.urlSuffix, func(t *testing.T) {
+			if got := ClassifyEndpoint(tt.urlSuffix, tt.method); got != tt.want {
+				t.Errorf("ClassifyEndpoint() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter := NewRateLimiter(map[EndpointClass]RateLimit{
+		EndpointClassShorten: {Requests: 20, Per: time.Second, Burst: 2},
+	})
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiter.Wait(context.Background(), EndpointClassShorten); err != nil {
+				t.Errorf("Wait() returned %s", err.Error())
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Two requests fit in the burst, the other two wait 50ms each
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("four requests took %s, want at least 100ms", elapsed)
+	}
+
+	// Endpoint classes without a limit aren't blocked
+	start = time.Now()
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(context.Background(), EndpointClassReads); err != nil {
+			t.Fatalf("Wait() returned %s", err.Error())
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("requests without a limit took %s", elapsed)
+	}
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  http.Header
+		blocked bool
+	}{
+		{name: "no headers", header: http.Header{}, blocked: false},
+		{name: "remaining", header: http.Header{"X-Ratelimit-Remaining": []string{"10"}}, blocked: false},
+		{name: "exhausted", header: http.Header{"X-Ratelimit-Remaining": []string{"0"}, "Retry-After": []string{"60"}}, blocked: true},
+		{name: "retry-after only", header: http.Header{"Retry-After": []string{"60"}}, blocked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewRateLimiter(nil)
+			limiter.Update(EndpointClassWrites, tt.header)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			err := limiter.Wait(ctx, EndpointClassWrites)
+			if tt.blocked && err != context.DeadlineExceeded {
+				t.Errorf("Wait() = %v, want %v", err, context.DeadlineExceeded)
+			}
+			if !tt.blocked && err != nil {
+				t.Errorf("Wait() = %v, want no error", err)
+			}
+
+			// Other endpoint classes have their own budget
+			if err := limiter.Wait(context.Background(), EndpointClassReads); err != nil {
+				t.Errorf("Wait() of another class = %v, want no error", err)
+			}
+		})
+	}
+}
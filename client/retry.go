@@ -0,0 +1,198 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultMaxAttempts is the number of attempts made by the DefaultRetryPolicy
+	DefaultMaxAttempts = 3
+	// DefaultMinBackoff is the initial backoff of the DefaultRetryPolicy
+	DefaultMinBackoff = 500 * time.Millisecond
+	// DefaultMaxBackoff is the maximum backoff of the DefaultRetryPolicy
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy configures how the Client retries requests that failed because of transient errors, like
+// network failures, 5xx responses or exceeding the rate limit.
+type RetryPolicy struct {
+	// The total number of attempts made for a request, including the first one
+	MaxAttempts int
+	// The backoff before the first retry, which doubles for every subsequent retry
+	MinBackoff time.Duration
+	// The maximum backoff between two attempts
+	MaxBackoff time.Duration
+	// Whether or not to retry requests with a non-idempotent method (like POST requests to shorten a link).
+	// Retrying those might create duplicate resources in Bitly.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns a RetryPolicy which retries idempotent requests up to three times.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: DefaultMaxAttempts,
+		MinBackoff:  DefaultMinBackoff,
+		MaxBackoff:  DefaultMaxBackoff,
+	}
+}
+
+// attempts returns the number of attempts allowed for the HTTP method.
+func (p *RetryPolicy) attempts(httpMethod string) int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+
+	if !p.RetryNonIdempotent && !isIdempotent(httpMethod) {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+// backoff returns how long to wait before the next attempt. A Retry-After header, or the rate limit reset
+// header of a 429 response, takes precedence over the exponential backoff, so that Bitly can tell the client
+// when to come back. Waits from headers are capped at MaxBackoff as well.
+func (p *RetryPolicy) backoff(attempt int, err error, header http.Header) time.Duration {
+	d, ok := retryAfterHeader(header)
+	if !ok && isRateLimited(err) {
+		d, ok = rateLimitReset(header)
+	}
+	if ok {
+		if p.MaxBackoff > 0 && d > p.MaxBackoff {
+			d = p.MaxBackoff
+		}
+		return d
+	}
+
+	d = p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+
+	if d <= 0 {
+		return 0
+	}
+
+	// Use "equal jitter" so concurrent clients don't retry in lockstep
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryable is implemented by errors that know whether the request can be retried, like the errors of
+// middlewares that reject a request before it is sent. Such errors are returned by the Client unchanged.
+type retryable interface {
+	Retryable() bool
+}
+
+// isIdempotent returns true if the HTTP method can safely be sent more than once.
+func isIdempotent(httpMethod string) bool {
+	switch httpMethod {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// isRetryable returns true if the error is caused by a transient failure.
+func isRetryable(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	if r, ok := err.(retryable); ok {
+		return r.Retryable()
+	}
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		// Errors that aren't returned by Bitly are network errors
+		return true
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isRateLimited returns true if the error is a 429 response.
+func isRateLimited(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusTooManyRequests
+}
+
+// retryAfter reads the Retry-After header, or Bitly's rate limit reset header, to determine how long
+// to wait before sending the next request.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	if d, ok := retryAfterHeader(header); ok {
+		return d, true
+	}
+	return rateLimitReset(header)
+}
+
+// retryAfterHeader reads the Retry-After header, which is either a number of seconds or a date.
+func retryAfterHeader(header http.Header) (time.Duration, bool) {
+	if header == nil {
+		return 0, false
+	}
+
+	if v := header.Get("Retry-After"); len(v) > 0 {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(time.Until(t)), true
+		}
+	}
+
+	return 0, false
+}
+
+// rateLimitReset reads Bitly's X-RateLimit-Reset header, the unix time at which the rate limit resets.
+func rateLimitReset(header http.Header) (time.Duration, bool) {
+	if header == nil {
+		return 0, false
+	}
+
+	if v := header.Get("X-RateLimit-Reset"); len(v) > 0 {
+		if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return nonNegative(time.Until(time.Unix(epoch, 0))), true
+		}
+	}
+
+	return 0, false
+}
+
+// sleep waits for the duration to pass or for the context to be done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyAttempts(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *RetryPolicy
+		method string
+		want   int
+	}{
+		{name: "no policy", policy: nil, method: http.MethodGet, want: 1},
+		{name: "zero attempts", policy: &RetryPolicy{}, method: http.MethodGet, want: 1},
+		{name: "idempotent", policy: DefaultRetryPolicy(), method: http.MethodGet, want: DefaultMaxAttempts},
+		{name: "delete", policy: DefaultRetryPolicy(), method: http.MethodDelete, want: DefaultMaxAttempts},
+		{name: "non-idempotent", policy: DefaultRetryPolicy(), method: http.MethodPost, want: 1},
+		{name: "patch", policy: DefaultRetryPolicy(), method: http.MethodPatch, want: 1},
+		{name: "non-idempotent allowed", policy: &RetryPolicy{MaxAttempts: 5, RetryNonIdempotent: true}, method: http.MethodPost, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.attempts(tt.method); got != tt.want {
+				t.Errorf("attempts(%s) = %d, want %d", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3, MinBackoff: 100 * time.Millisecond, MaxBackoff: 10 * time.Second}
+	rateLimited := &APIError{StatusCode: http.StatusTooManyRequests}
+	unavailable := &APIError{StatusCode: http.StatusServiceUnavailable}
+	reset := strconv.FormatInt(time.Now().Add(5*time.Second).Unix(), 10)
+	farReset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+
+	tests := []struct {
+		name    string
+		attempt int
+		err     error
+		header  http.Header
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "first attempt", attempt: 1, err: unavailable, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "third attempt", attempt: 3, err: unavailable, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{name: "capped at max backoff", attempt: 20, err: unavailable, min: 5 * time.Second, max: 10 * time.Second},
+		{name: "retry-after seconds", attempt: 1, err: unavailable, header: http.Header{"Retry-After": []string{"2"}}, min: 2 * time.Second, max: 2 * time.Second},
+		{name: "retry-after zero", attempt: 3, err: rateLimited, header: http.Header{"Retry-After": []string{"0"}}, min: 0, max: 0},
+		{name: "retry-after date", attempt: 1, err: unavailable, header: http.Header{"Retry-After": []string{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat)}}, min: 0, max: 0},
+		{name: "retry-after clamped", attempt: 1, err: unavailable, header: http.Header{"Retry-After": []string{"3600"}}, min: 10 * time.Second, max: 10 * time.Second},
+		{name: "invalid retry-after", attempt: 1, err: unavailable, header: http.Header{"Retry-After": []string{"soon"}}, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "rate limit reset on 429", attempt: 1, err: rateLimited, header: http.Header{"X-Ratelimit-Reset": []string{reset}}, min: 3 * time.Second, max: 5 * time.Second},
+		{name: "rate limit reset clamped", attempt: 1, err: rateLimited, header: http.Header{"X-Ratelimit-Reset": []string{farReset}}, min: 10 * time.Second, max: 10 * time.Second},
+		{name: "rate limit reset ignored on 503", attempt: 1, err: unavailable, header: http.Header{"X-Ratelimit-Reset": []string{farReset}}, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "retry-after before rate limit reset", attempt: 1, err: rateLimited, header: http.Header{"Retry-After": []string{"1"}, "X-Ratelimit-Reset": []string{farReset}}, min: time.Second, max: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := policy.backoff(tt.attempt, tt.err, tt.header)
+			if got < tt.min || got > tt.max {
+				t.Errorf("backoff() = %s, want between %s and %s", got, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+type notRetryableError struct{}
+
+func (notRetryableError) Error() string   { return "not retryable" }
+func (notRetryableError) Retryable() bool { return false }
+
+func TestIsRetryable(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{name: "no error", ctx: context.Background(), err: nil, want: false},
+		{name: "network error", ctx: context.Background(), err: errors.New("connection reset"), want: true},
+		{name: "context done", ctx: cancelled, err: errors.New("connection reset"), want: false},
+		{name: "429", ctx: context.Background(), err: &APIError{StatusCode: http.StatusTooManyRequests}, want: true},
+		{name: "500", ctx: context.Background(), err: &APIError{StatusCode: http.StatusInternalServerError}, want: true},
+		{name: "502", ctx: context.Background(), err: &APIError{StatusCode: http.StatusBadGateway}, want: true},
+		{name: "503", ctx: context.Background(), err: &APIError{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "504", ctx: context.Background(), err: &APIError{StatusCode: http.StatusGatewayTimeout}, want: true},
+		{name: "400", ctx: context.Background(), err: &APIError{StatusCode: http.StatusBadRequest}, want: false},
+		{name: "404", ctx: context.Background(), err: &APIError{StatusCode: http.StatusNotFound}, want: false},
+		{name: "error that isn't retryable", ctx: context.Background(), err: notRetryableError{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.ctx, tt.err); got != tt.want {
+				t.Errorf("isRetryable() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenSource provides the access token for a request. Implementations must be safe for concurrent use.
+type TokenSource interface {
+	// Token returns the access token to send to Bitly
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc is an adapter to use a function as a TokenSource.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token calls f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticTokenSource is a TokenSource which always returns the same access token.
+type StaticTokenSource string
+
+// Token returns the access token.
+func (s StaticTokenSource) Token(ctx context.Context) (string, error) {
+	if len(s) == 0 {
+		return "", fmt.Errorf("bitly: the access token is empty")
+	}
+	return string(s), nil
+}
+
+// EnvTokenSource returns a TokenSource which reads the access token from the environment variable for
+// every request.
+func EnvTokenSource(name string) TokenSource {
+	return TokenSourceFunc(func(ctx context.Context) (string, error) {
+		token := strings.TrimSpace(os.Getenv(name))
+		if len(token) == 0 {
+			return "", fmt.Errorf("bitly: the environment variable %s is empty", name)
+		}
+		return token, nil
+	})
+}
+
+// FileTokenSource is a TokenSource which reads the access token from a file. The file is read again when
+// it has changed, so the token can be rotated without restarting.
+type FileTokenSource struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	token   string
+}
+
+// NewFileTokenSource creates a TokenSource which reads the access token from the file at path.
+func NewFileTokenSource(path string) *FileTokenSource {
+	return &FileTokenSource{path: path}
+}
+
+// Token returns the access token in the file, without surrounding whitespace.
+func (s *FileTokenSource) Token(ctx context.Context) (string, error) {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.token) > 0 && info.ModTime().Equal(s.modTime) {
+		return s.token, nil
+	}
+
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return "", err
+	}
+
+	token := strings.TrimSpace(string(data))
+	if len(token) == 0 {
+		return "", fmt.Errorf("bitly: the access token file %s is empty", s.path)
+	}
+
+	s.token = token
+	s.modTime = info.ModTime()
+	return token, nil
+}
@@ -0,0 +1,22 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AppendQuery appends the query parameters, if there are any, to the endpoint.
+func AppendQuery(endpoint string, v url.Values) string {
+	queryParams := v.Encode()
+	if len(queryParams) > 0 {
+		return fmt.Sprintf("%s?%s", endpoint, queryParams)
+	}
+	return endpoint
+}
+
+// TrimScheme removes the http:// or https:// scheme from a Bitlink, because Bitly identifies Bitlinks as
+// domain/hash, like bit.ly/2Bq3bLx.
+func TrimScheme(bitlink string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(bitlink, "https://"), "http://")
+}
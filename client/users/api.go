@@ -1,7 +1,11 @@
 // Package users contains the methods to interact with the Users in Bitly
 package users
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/retgits/bitly"
+)
 
 // Email contains email data from the logged in user
 type Email struct {
@@ -12,15 +16,49 @@ type Email struct {
 
 // User is the currently logged in user
 type User struct {
-	Created          string  `json:"created,omitempty"`
-	Modified         string  `json:"modified,omitempty"`
-	Login            string  `json:"login,omitempty"`
-	IsActive         bool    `json:"is_active,omitempty"`
-	Is2FaEnabled     bool    `json:"is_2fa_enabled,omitempty"`
-	Name             string  `json:"name"`
-	Emails           []Email `json:"emails,omitempty"`
-	IsSsoUser        bool    `json:"is_sso_user,omitempty"`
-	DefaultGroupGUID string  `json:"default_group_guid"`
+	Created          *bitly.Time `json:"created,omitempty"`
+	Modified         *bitly.Time `json:"modified,omitempty"`
+	Login            string      `json:"login,omitempty"`
+	IsActive         bool        `json:"is_active,omitempty"`
+	Is2FaEnabled     bool        `json:"is_2fa_enabled,omitempty"`
+	Name             string      `json:"name"`
+	Emails           []Email     `json:"emails,omitempty"`
+	IsSsoUser        bool        `json:"is_sso_user,omitempty"`
+	DefaultGroupGUID string      `json:"default_group_guid"`
+}
+
+// Patch contains the fields to update on the user. Only the fields that are explicitly set are sent to
+// Bitly, so all other fields are left untouched.
+type Patch struct {
+	name             *string
+	defaultGroupGUID *string
+}
+
+// NewPatch creates a new Patch that can be chained with builder methods to set the fields to update.
+func NewPatch() *Patch {
+	return &Patch{}
+}
+
+// Name sets the name of the user returning a Patch pointer for chaining.
+func (p *Patch) Name(name string) *Patch {
+	p.name = &name
+	return p
+}
+
+// DefaultGroupGUID sets the default group of the user returning a Patch pointer for chaining.
+func (p *Patch) DefaultGroupGUID(groupGUID string) *Patch {
+	p.defaultGroupGUID = &groupGUID
+	return p
+}
+
+// patchRequest is the payload sent to Bitly for a Patch
+type patchRequest struct {
+	Name             *string `json:"name,omitempty"`
+	DefaultGroupGUID *string `json:"default_group_guid,omitempty"`
+}
+
+func (r *patchRequest) marshal() ([]byte, error) {
+	return json.Marshal(r)
 }
 
 func (r *User) marshal() ([]byte, error) {
@@ -2,6 +2,7 @@
 package users
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/retgits/bitly/client"
@@ -11,12 +12,27 @@ const (
 	userEndpoint = "user"
 )
 
+// Service is the interface implemented by Users. Code that depends on Service, instead of *Users, can use a
+// mock in its unit tests.
+type Service interface {
+	UpdateUser(user User) (User, error)
+	UpdateUserContext(ctx context.Context, user User) (User, error)
+
+	RetrieveUser() (User, error)
+	RetrieveUserContext(ctx context.Context) (User, error)
+
+	PatchUser(patch *Patch) (User, error)
+	PatchUserContext(ctx context.Context, patch *Patch) (User, error)
+}
+
 // Users is the client object which allows you to perform operations such as changing your name
 // or fetching basic user information apply only to the authenticated user.
 type Users struct {
 	*client.Client
 }
 
+var _ Service = (*Users)(nil)
+
 // New creates a new instance of the Users client.
 func New(c *client.Client) *Users {
 	return &Users{
@@ -24,14 +40,20 @@ func New(c *client.Client) *Users {
 	}
 }
 
-// UpdateUser is to update fields in the user
+// UpdateUser is to update fields in the user. All fields of the User are sent to Bitly, use PatchUser
+// to only update specific fields.
 func (u *Users) UpdateUser(user User) (User, error) {
+	return u.UpdateUserContext(context.Background(), user)
+}
+
+// UpdateUserContext is the same as UpdateUser, but uses the context to control the lifetime of the request.
+func (u *Users) UpdateUserContext(ctx context.Context, user User) (User, error) {
 	payload, err := user.marshal()
 	if err != nil {
 		return User{}, err
 	}
 
-	data, err := u.Call(userEndpoint, http.MethodPatch, payload)
+	data, err := u.CallContext(ctx, userEndpoint, http.MethodPatch, payload)
 	if err != nil {
 		return User{}, err
 	}
@@ -41,7 +63,37 @@ func (u *Users) UpdateUser(user User) (User, error) {
 
 // RetrieveUser is to retrieve information for the current authenticated user
 func (u *Users) RetrieveUser() (User, error) {
-	data, err := u.Call(userEndpoint, http.MethodGet, nil)
+	return u.RetrieveUserContext(context.Background())
+}
+
+// RetrieveUserContext is the same as RetrieveUser, but uses the context to control the lifetime of the request.
+func (u *Users) RetrieveUserContext(ctx context.Context) (User, error) {
+	data, err := u.CallContext(ctx, userEndpoint, http.MethodGet, nil)
+	if err != nil {
+		return User{}, err
+	}
+
+	return unmarshalUser(data)
+}
+
+// PatchUser is to update only the fields of the user that are set in the Patch
+func (u *Users) PatchUser(patch *Patch) (User, error) {
+	return u.PatchUserContext(context.Background(), patch)
+}
+
+// PatchUserContext is the same as PatchUser, but uses the context to control the lifetime of the request.
+func (u *Users) PatchUserContext(ctx context.Context, patch *Patch) (User, error) {
+	req := patchRequest{
+		Name:             patch.name,
+		DefaultGroupGUID: patch.defaultGroupGUID,
+	}
+
+	payload, err := req.marshal()
+	if err != nil {
+		return User{}, err
+	}
+
+	data, err := u.CallContext(ctx, userEndpoint, http.MethodPatch, payload)
 	if err != nil {
 		return User{}, err
 	}
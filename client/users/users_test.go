@@ -0,0 +1,43 @@
+package users_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/users"
+)
+
+func TestPatchUser(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch *users.Patch
+		body  string
+	}{
+		{name: "name", patch: users.NewPatch().Name("Jane"), body: `{"name":"Jane"}`},
+		{name: "default group", patch: users.NewPatch().DefaultGroupGUID("Bg00000001"), body: `{"default_group_guid":"Bg00000001"}`},
+		{name: "empty name", patch: users.NewPatch().Name(""), body: `{"name":""}`},
+		{name: "nothing", patch: users.NewPatch(), body: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := ioutil.ReadAll(r.Body)
+				body = string(data)
+				w.Write([]byte(`{"login":"jane"}`))
+			}))
+			defer srv.Close()
+
+			if _, err := users.New(client.NewClient().WithBaseURL(srv.URL)).PatchUser(tt.patch); err != nil {
+				t.Fatalf("PatchUser() returned %s", err.Error())
+			}
+			if body != tt.body {
+				t.Errorf("PatchUser() sent %s, want %s", body, tt.body)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/retgits/bitly/client"
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/bsds"
+	"github.com/retgits/bitly/client/groups"
+	"github.com/retgits/bitly/client/metrics"
+	"github.com/retgits/bitly/client/organizations"
+	"github.com/retgits/bitly/client/users"
+)
+
+// stringsFlag is a flag that can be set multiple times.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// exactArgs returns an error when the number of positional arguments isn't n.
+func (env *environment) exactArgs(args []string, n int) error {
+	if len(args) != n {
+		return fmt.Errorf("expected %d argument(s), got %d; usage: bitly %s", n, len(args), env.usage)
+	}
+	return nil
+}
+
+func runShorten(env *environment, args []string) error {
+	fs := flag.NewFlagSet("shorten", flag.ContinueOnError)
+	domain := fs.String("domain", "", "the domain of the Bitlink, like bit.ly")
+	group := fs.String("group", "", "the GUID of the group, defaults to the default group of the user")
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := env.exactArgs(args, 1); err != nil {
+		return err
+	}
+
+	details, err := bitlinks.New(env.client).ShortenLink(&bitlinks.ShortenRequest{
+		LongURL:   args[0],
+		Domain:    *domain,
+		GroupGUID: *group,
+	})
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"link", "id", "long_url"}}
+	t.add(details.Link, details.ID, details.LongURL)
+	return env.print(details, t)
+}
+
+func runExpand(env *environment, args []string) error {
+	fs := flag.NewFlagSet("expand", flag.ContinueOnError)
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := env.exactArgs(args, 1); err != nil {
+		return err
+	}
+
+	info, err := bitlinks.New(env.client).ExpandBitlink(bitlinks.Link{BitlinkID: client.TrimScheme(args[0])})
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"id", "long_url", "created_at"}}
+	t.add(info.ID, info.LongURL, info.CreatedAt)
+	return env.print(info, t)
+}
+
+func runLinks(env *environment, args []string) error {
+	fs := flag.NewFlagSet("links", flag.ContinueOnError)
+	group := fs.String("group", "", "the GUID of the group, defaults to the default group of the user")
+	query := fs.String("query", "", "only list Bitlinks that match the query")
+	archived := fs.String("archived", "off", "whether to list archived Bitlinks: on, off or both")
+	size := fs.Int("size", 50, "the number of Bitlinks to request per page")
+	maxItems := fs.Int("max", 0, "the maximum number of Bitlinks to list, 0 lists all")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "only list Bitlinks with the tag, can be repeated")
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 || args[0] != "list" {
+		return fmt.Errorf("unknown subcommand; usage: bitly %s", env.usage)
+	}
+
+	groupGUID, err := env.groupGUID(*group)
+	if err != nil {
+		return err
+	}
+
+	it := groups.New(env.client).IterateBitlinksByGroup(groupGUID, &groups.BitlinksGroupRequest{
+		Size:     *size,
+		Query:    *query,
+		Archived: *archived,
+		Tags:     tags,
+	}, *maxItems)
+
+	links, err := it.All()
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"id", "long_url", "title", "tags", "created_at"}}
+	for _, link := range links {
+		t.add(link.ID, link.LongURL, link.Title, link.Tags, link.CreatedAt)
+	}
+	if links == nil {
+		links = []groups.Link{}
+	}
+	return env.print(links, t)
+}
+
+// groupGUID returns the group, or the default group of the user when the group is empty.
+func (env *environment) groupGUID(group string) (string, error) {
+	if len(group) > 0 {
+		return group, nil
+	}
+
+	user, err := users.New(env.client).RetrieveUser()
+	if err != nil {
+		return "", err
+	}
+	return user.DefaultGroupGUID, nil
+}
+
+func runClicks(env *environment, args []string) error {
+	fs := flag.NewFlagSet("clicks", flag.ContinueOnError)
+	unit := fs.String("unit", string(metrics.UnitDay), "the unit of time: minute, hour, day, week or month")
+	units := fs.Int("units", -1, "the number of units to query, -1 queries all")
+	summary := fs.Bool("summary", false, "only show the total number of clicks")
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := env.exactArgs(args, 1); err != nil {
+		return err
+	}
+
+	u, err := metrics.ParseUnit(*unit)
+	if err != nil {
+		return err
+	}
+
+	svc := bitlinks.New(env.client)
+	req := &bitlinks.MetricsRequest{Unit: u, Units: *units}
+	bitlink := client.TrimScheme(args[0])
+
+	if *summary {
+		m, err := svc.GetClicksSummary(bitlink, req)
+		if err != nil {
+			return err
+		}
+
+		t := &table{headers: []string{"bitlink", "unit", "units", "total_clicks"}}
+		t.add(bitlink, m.Unit, m.Units, m.TotalClicks)
+		return env.print(m, t)
+	}
+
+	m, err := svc.GetClicks(bitlink, req)
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"date", "clicks"}}
+	for _, c := range m.LinkClicks {
+		t.add(c.Date, c.Clicks)
+	}
+	return env.print(m, t)
+}
+
+func runGroups(env *environment, args []string) error {
+	fs := flag.NewFlagSet("groups", flag.ContinueOnError)
+	organization := fs.String("organization", "", "only list the groups of the organization")
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := env.exactArgs(args, 0); err != nil {
+		return err
+	}
+
+	g, err := groups.New(env.client).RetrieveGroups(*organization)
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"guid", "name", "organization_guid", "role", "active"}}
+	for _, group := range g.Groups {
+		t.add(group.GUID, group.Name, group.OrganizationGUID, group.Role, group.IsActive)
+	}
+	if g.Groups == nil {
+		g.Groups = []groups.Group{}
+	}
+	return env.print(g.Groups, t)
+}
+
+func runOrgs(env *environment, args []string) error {
+	fs := flag.NewFlagSet("orgs", flag.ContinueOnError)
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := env.exactArgs(args, 0); err != nil {
+		return err
+	}
+
+	o, err := organizations.New(env.client).RetrieveOrganizations()
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"guid", "name", "tier", "role", "active"}}
+	for _, org := range o.Organizations {
+		t.add(org.GUID, org.Name, org.Tier, org.Role, org.IsActive)
+	}
+	if o.Organizations == nil {
+		o.Organizations = []organizations.OrganizationDetails{}
+	}
+	return env.print(o.Organizations, t)
+}
+
+func runUser(env *environment, args []string) error {
+	fs := flag.NewFlagSet("user", flag.ContinueOnError)
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := env.exactArgs(args, 0); err != nil {
+		return err
+	}
+
+	user, err := users.New(env.client).RetrieveUser()
+	if err != nil {
+		return err
+	}
+
+	var email string
+	for _, e := range user.Emails {
+		if e.IsPrimary {
+			email = e.Email
+		}
+	}
+
+	created := ""
+	if user.Created != nil {
+		created = user.Created.String()
+	}
+
+	t := &table{headers: []string{"login", "name", "email", "default_group_guid", "created"}}
+	t.add(user.Login, user.Name, email, user.DefaultGroupGUID, created)
+	return env.print(user, t)
+}
+
+func runBSDs(env *environment, args []string) error {
+	fs := flag.NewFlagSet("bsds", flag.ContinueOnError)
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := env.exactArgs(args, 0); err != nil {
+		return err
+	}
+
+	b, err := bsds.New(env.client).GetBSDs()
+	if err != nil {
+		return err
+	}
+
+	t := &table{headers: []string{"bsd"}}
+	for _, bsd := range b.AllBSDs {
+		t.add(bsd)
+	}
+	if b.AllBSDs == nil {
+		b.AllBSDs = []string{}
+	}
+	return env.print(b.AllBSDs, t)
+}
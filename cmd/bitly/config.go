@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// tokenEnvVar is the environment variable that contains the access token
+	tokenEnvVar = "BITLY_ACCESS_TOKEN"
+)
+
+// config is the content of the config file.
+type config struct {
+	AccessToken string `json:"access_token"`
+}
+
+// defaultConfigFile returns the location of the config file in the home directory of the user.
+func defaultConfigFile() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); len(dir) > 0 {
+		return filepath.Join(dir, "bitly", "config.json")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "bitly", "config.json")
+}
+
+// accessToken returns the token from the flag, the environment or the config file, in that order.
+func accessToken(flagValue string, configFile string) (string, error) {
+	if len(flagValue) > 0 {
+		return flagValue, nil
+	}
+
+	if token := os.Getenv(tokenEnvVar); len(token) > 0 {
+		return token, nil
+	}
+
+	if len(configFile) > 0 {
+		data, err := ioutil.ReadFile(configFile)
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+
+		if err == nil {
+			var c config
+			if err := json.Unmarshal(data, &c); err != nil {
+				return "", fmt.Errorf("reading %s: %s", configFile, err.Error())
+			}
+			if token := strings.TrimSpace(c.AccessToken); len(token) > 0 {
+				return token, nil
+			}
+		}
+	}
+
+	return "", errors.New("no access token found, set --token, " + tokenEnvVar + " or the access_token in the config file")
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/export"
+	"github.com/retgits/bitly/client/groups"
+	"github.com/retgits/bitly/client/metrics"
+)
+
+func runExport(env *environment, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	group := fs.String("group", "", "the GUID of the group, defaults to the default group of the user")
+	archived := fs.String("archived", "off", "whether to export archived Bitlinks: on, off or both")
+	columns := fs.String("columns", strings.Join(export.DefaultColumns, ","), "the columns to export")
+	format := fs.String("format", string(export.FormatCSV), "the format of the export: csv, tsv or jsonl")
+	unit := fs.String("unit", string(metrics.UnitDay), "the unit of time of the click totals")
+	units := fs.Int("units", -1, "the number of units of the click totals, -1 counts all clicks")
+	workers := fs.Int("workers", export.DefaultWorkers, "the number of concurrent click requests")
+	file := fs.String("file", "", "the file to write the export to, defaults to stdout")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "only export Bitlinks with the tag, can be repeated")
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := env.exactArgs(args, 0); err != nil {
+		return err
+	}
+
+	u, err := metrics.ParseUnit(*unit)
+	if err != nil {
+		return err
+	}
+
+	groupGUID, err := env.groupGUID(*group)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = env.stdout
+	if len(*file) > 0 {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	count, err := export.Export(groups.New(env.client), bitlinks.New(env.client), groupGUID, w, &export.Options{
+		Format:   export.Format(strings.ToLower(*format)),
+		Columns:  strings.Split(*columns, ","),
+		Tags:     tags,
+		Archived: *archived,
+		Clicks:   &bitlinks.MetricsRequest{Unit: u, Units: *units},
+		Workers:  *workers,
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(*file) > 0 {
+		fmt.Fprintf(env.stderr, "exported %d Bitlinks to %s\n", count, *file)
+	}
+	return nil
+}
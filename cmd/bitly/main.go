@@ -0,0 +1,199 @@
+// Command bitly is a command-line interface to Bitly, built on the services of this module.
+//
+// Usage:
+//
+//	bitly [command] [flags] [arguments]
+//
+// The access token is read from the --token flag, the BITLY_ACCESS_TOKEN environment variable or the
+// access_token field of the config file ($HOME/.config/bitly/config.json by default), in that order.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/retgits/bitly/client"
+)
+
+// command is a single subcommand of the CLI.
+type command struct {
+	usage       string
+	description string
+	run         func(env *environment, args []string) error
+}
+
+// environment contains the global configuration of a single invocation.
+type environment struct {
+	stdout io.Writer
+	stderr io.Writer
+	usage  string
+	output string
+	client *client.Client
+}
+
+// globalFlags are the flags that are accepted by every command.
+type globalFlags struct {
+	output     string
+	token      string
+	configFile string
+	baseURL    string
+}
+
+var commands = map[string]command{
+	"shorten": {
+		usage:       "shorten [--domain domain] [--group guid] <long url>",
+		description: "Convert a long url to a Bitlink",
+		run:         runShorten,
+	},
+	"expand": {
+		usage:       "expand <bitlink>",
+		description: "Return the long url of a Bitlink",
+		run:         runExpand,
+	},
+	"links": {
+		usage:       "links list [--group guid] [--tag tag]... [--query query] [--archived on|off|both] [--max n]",
+		description: "List the Bitlinks of a group",
+		run:         runLinks,
+	},
+	"clicks": {
+		usage:       "clicks [--unit minute|hour|day|week|month] [--units n] [--summary] <bitlink>",
+		description: "Return the click counts of a Bitlink",
+		run:         runClicks,
+	},
+	"export": {
+		usage:       "export [--group guid] [--tag tag]... [--columns id,long_url,...] [--format csv|tsv|jsonl] [--unit unit] [--units n] [--workers n] [--file path]",
+		description: "Export the Bitlinks of a group with their click totals",
+		run:         runExport,
+	},
+	"groups": {
+		usage:       "groups [--organization guid]",
+		description: "List the groups",
+		run:         runGroups,
+	},
+	"plan": {
+		usage:       "plan <manifest.json|manifest.yaml>",
+		description: "Show the changes needed to make the Bitlinks match a manifest",
+		run:         runPlan,
+	},
+	"apply": {
+		usage:       "apply [--dry-run] [--continue-on-error] <manifest.json|manifest.yaml>",
+		description: "Make the Bitlinks match a manifest",
+		run:         runApply,
+	},
+	"orgs": {
+		usage:       "orgs",
+		description: "List the organizations",
+		run:         runOrgs,
+	},
+	"user": {
+		usage:       "user",
+		description: "Show the authenticated user",
+		run:         runUser,
+	},
+	"bsds": {
+		usage:       "bsds",
+		description: "List the branded short domains",
+		run:         runBSDs,
+	},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the command and returns the exit code.
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "bitly: unknown command %q\n\n", args[0])
+		usage(stderr)
+		return 2
+	}
+
+	env := &environment{stdout: stdout, stderr: stderr, usage: cmd.usage}
+	if err := cmd.run(env, args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			return 0
+		}
+		fmt.Fprintf(stderr, "bitly %s: %s\n", args[0], err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: bitly <command> [flags] [arguments]\n\nCommands:\n")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].description)
+	}
+
+	fmt.Fprintf(w, "\nGlobal flags:\n")
+	fs := flag.NewFlagSet("bitly", flag.ContinueOnError)
+	registerGlobalFlags(fs, &globalFlags{})
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+// registerGlobalFlags adds the flags accepted by every command to the flag set.
+func registerGlobalFlags(fs *flag.FlagSet, g *globalFlags) {
+	fs.StringVar(&g.output, "output", "table", "the output format: table, json or csv")
+	fs.StringVar(&g.token, "token", "", "the Bitly access token")
+	fs.StringVar(&g.configFile, "config", defaultConfigFile(), "the config file")
+	fs.StringVar(&g.baseURL, "base-url", "", "the base URL of the Bitly API")
+}
+
+// parse parses the flags of the command, which may be mixed with the positional arguments, and configures
+// the client. It returns the positional arguments.
+func (env *environment) parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	g := &globalFlags{}
+	registerGlobalFlags(fs, g)
+	fs.SetOutput(env.stderr)
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+
+	switch strings.ToLower(g.output) {
+	case outputTable, outputJSON, outputCSV:
+		env.output = strings.ToLower(g.output)
+	default:
+		return nil, fmt.Errorf("unknown output format %q, use table, json or csv", g.output)
+	}
+
+	token, err := accessToken(g.token, g.configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	env.client = client.NewClient().WithAccessToken(token).WithRetryPolicy(client.DefaultRetryPolicy())
+	if len(g.baseURL) > 0 {
+		env.client = env.client.WithBaseURL(g.baseURL)
+	}
+
+	return positional, nil
+}
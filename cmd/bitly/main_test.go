@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/retgits/bitly/client/bitlytest"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "help",
+			args:   []string{"help"},
+			stdout: "Usage: bitly <command> [flags] [arguments]",
+		},
+		{
+			name:   "unknown command",
+			args:   []string{"shrink"},
+			code:   2,
+			stderr: `bitly: unknown command "shrink"`,
+		},
+		{
+			name:   "empty list as json",
+			args:   []string{"links", "list", "--output", "json"},
+			stdout: "[]\n",
+		},
+		{
+			name:   "empty list as csv",
+			args:   []string{"bsds", "--output", "csv"},
+			stdout: "bsd\n",
+		},
+		{
+			name:   "flags after the arguments",
+			args:   []string{"shorten", "https://example.org", "--output", "csv"},
+			stdout: "link,id,long_url\nhttps://bit.ly/",
+		},
+		{
+			name:   "user",
+			args:   []string{"user", "--output", "csv"},
+			stdout: "login,name,email,default_group_guid,created\nbitlytest,Bitly Test,",
+		},
+		{
+			name:   "unknown output format",
+			args:   []string{"user", "--output", "xml"},
+			code:   1,
+			stderr: `bitly user: unknown output format "xml", use table, json or csv`,
+		},
+		{
+			name:   "unknown subcommand",
+			args:   []string{"links", "show"},
+			code:   1,
+			stderr: "bitly links: unknown subcommand; usage: bitly links list",
+		},
+		{
+			name:   "rejected token",
+			args:   []string{"user", "--token", "other"},
+			code:   1,
+			stderr: "bitly user: bitly: 403 FORBIDDEN",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := bitlytest.NewServer()
+			defer srv.Close()
+
+			// The token flag comes first, so a token in the arguments of the test overrides it
+			args := append([]string{}, tt.args...)
+			if len(args) > 0 && args[0] != "help" && args[0] != "shrink" {
+				args = append([]string{args[0], "--token", bitlytest.DefaultAccessToken, "--base-url", srv.URL}, args[1:]...)
+			}
+
+			var stdout, stderr bytes.Buffer
+			code := run(args, &stdout, &stderr)
+
+			if code != tt.code {
+				t.Errorf("run() = %d, want %d; stderr: %s", code, tt.code, stderr.String())
+			}
+			if !strings.HasPrefix(stdout.String(), tt.stdout) {
+				t.Errorf("run() wrote\n%s\nwant it to start with\n%s", stdout.String(), tt.stdout)
+			}
+			if !strings.HasPrefix(stderr.String(), tt.stderr) {
+				t.Errorf("run() wrote to stderr\n%s\nwant it to start with\n%s", stderr.String(), tt.stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/retgits/bitly/client/bitlinks"
+	"github.com/retgits/bitly/client/custombitlinks"
+	"github.com/retgits/bitly/client/groups"
+	"github.com/retgits/bitly/client/manifest"
+)
+
+// loadPlan reads the manifest and compares it with the Bitlinks in Bitly.
+func (env *environment) loadPlan(path string) (*manifest.Plan, error) {
+	m, err := manifest.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest.NewPlan(groups.New(env.client), m)
+}
+
+// printPlan writes the plan as text for the table output, and as a row per change for the other formats.
+func (env *environment) printPlan(plan *manifest.Plan) error {
+	if env.output == outputTable {
+		return plan.Print(env.stdout)
+	}
+
+	t := &table{headers: []string{"action", "group_guid", "bitlink", "changes"}}
+	for _, c := range plan.Changes {
+		t.add(c.Action, c.GroupGUID, c.Bitlink, formatDiffs(c.Diffs))
+	}
+	return env.print(plan, t)
+}
+
+func formatDiffs(diffs []manifest.Diff) string {
+	s := make([]string, len(diffs))
+	for idx, d := range diffs {
+		s[idx] = d.Field + "=" + d.New
+	}
+	return strings.Join(s, " ")
+}
+
+func runPlan(env *environment, args []string) error {
+	fs := flag.NewFlagSet("plan", flag.ContinueOnError)
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := env.exactArgs(args, 1); err != nil {
+		return err
+	}
+
+	plan, err := env.loadPlan(args[0])
+	if err != nil {
+		return err
+	}
+
+	return env.printPlan(plan)
+}
+
+// applyResult is the result of a change as printed by apply.
+type applyResult struct {
+	Action  manifest.Action `json:"action"`
+	Bitlink string          `json:"bitlink"`
+	Status  string          `json:"status"`
+	Error   string          `json:"error,omitempty"`
+}
+
+func runApply(env *environment, args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "show the plan without changing any Bitlink")
+	continueOnError := fs.Bool("continue-on-error", false, "continue with the next change when a change fails")
+
+	args, err := env.parse(fs, args)
+	if err != nil {
+		return err
+	}
+	if err := env.exactArgs(args, 1); err != nil {
+		return err
+	}
+
+	plan, err := env.loadPlan(args[0])
+	if err != nil {
+		return err
+	}
+
+	results := manifest.Apply(bitlinks.New(env.client), plan, &manifest.ApplyOptions{
+		DryRun:          *dryRun,
+		ContinueOnError: *continueOnError,
+		CustomBitlinks:  custombitlinks.New(env.client),
+	})
+
+	if *dryRun || env.output == outputTable {
+		if err := env.printPlan(plan); err != nil {
+			return err
+		}
+	}
+	if *dryRun {
+		return nil
+	}
+
+	t := &table{headers: []string{"action", "bitlink", "status", "error"}}
+	out := make([]applyResult, 0, len(results))
+	var failed int
+	for _, res := range results {
+		bitlink := res.Change.Bitlink
+		if len(res.Details.ID) > 0 {
+			bitlink = res.Details.ID
+		}
+
+		status, msg := "done", ""
+		switch {
+		case res.Err != nil:
+			status, msg = "failed", res.Err.Error()
+			failed++
+		case res.Skipped:
+			status = "skipped"
+		}
+		t.add(res.Change.Action, bitlink, status, msg)
+		out = append(out, applyResult{Action: res.Change.Action, Bitlink: bitlink, Status: status, Error: msg})
+	}
+
+	if env.output == outputTable {
+		fmt.Fprintln(env.stdout)
+	}
+	if err := env.print(out, t); err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d changes failed", failed, len(results))
+	}
+	return nil
+}
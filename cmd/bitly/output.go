@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+)
+
+// table is the tabular representation of the result of a command.
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func (t *table) add(values ...interface{}) {
+	row := make([]string, len(values))
+	for idx, v := range values {
+		switch val := v.(type) {
+		case []string:
+			row[idx] = strings.Join(val, ",")
+		default:
+			row[idx] = fmt.Sprintf("%v", val)
+		}
+	}
+	t.rows = append(t.rows, row)
+}
+
+// print writes the result in the output format. The raw value is used for JSON, so all fields are
+// available, and the table for the table and CSV formats.
+func (env *environment) print(raw interface{}, t *table) error {
+	switch env.output {
+	case outputJSON:
+		enc := json.NewEncoder(env.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(raw)
+	case outputCSV:
+		w := csv.NewWriter(env.stdout)
+		if err := w.Write(t.headers); err != nil {
+			return err
+		}
+		if err := w.WriteAll(t.rows); err != nil {
+			return err
+		}
+		return w.Error()
+	}
+
+	w := tabwriter.NewWriter(env.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(t.headers, "\t")))
+	for _, row := range t.rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
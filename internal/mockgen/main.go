@@ -0,0 +1,357 @@
+// Command mockgen generates the mocks in the client/mocks package from the Service interfaces of the
+// services. It is run through go generate:
+//
+//	go generate ./client/mocks
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const contextSuffix = "Context"
+
+// param is a single parameter or result of a method.
+type param struct {
+	name     string
+	typ      string
+	variadic bool
+}
+
+// method is a single method of the interface.
+type method struct {
+	name    string
+	params  []param
+	results []param
+}
+
+func main() {
+	src := flag.String("src", "", "the directory of the package that contains the interface")
+	iface := flag.String("iface", "Service", "the name of the interface to mock")
+	importPath := flag.String("import", "", "the import path of the package that contains the interface")
+	mock := flag.String("type", "", "the name of the generated mock")
+	out := flag.String("out", "", "the file to write the mock to")
+	flag.Parse()
+
+	if len(*src) == 0 || len(*importPath) == 0 || len(*mock) == 0 || len(*out) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	code, err := generate(*src, *iface, *importPath, *mock)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(*out, code, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// generate parses the package and renders the mock for the interface.
+func generate(src string, iface string, importPath string, mock string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, src, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	pkgName := path.Base(importPath)
+	pkg, ok := pkgs[pkgName]
+	if !ok {
+		return nil, fmt.Errorf("package %s not found in %s", pkgName, src)
+	}
+
+	var spec *ast.InterfaceType
+	var file *ast.File
+	for _, f := range pkg.Files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			ts, ok := n.(*ast.TypeSpec)
+			if !ok || ts.Name.Name != iface {
+				return true
+			}
+			if it, ok := ts.Type.(*ast.InterfaceType); ok {
+				spec = it
+				file = f
+			}
+			return false
+		})
+	}
+
+	if spec == nil {
+		return nil, fmt.Errorf("interface %s not found in %s", iface, src)
+	}
+
+	r := &renderer{pkgName: pkgName, imports: map[string]string{pkgName: importPath}, fileImports: fileImports(file)}
+
+	var methods []method
+	for _, field := range spec.Methods.List {
+		ft, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("interface %s embeds other interfaces, which isn't supported", iface)
+		}
+		methods = append(methods, method{
+			name:    field.Names[0].Name,
+			params:  r.fields(ft.Params, "p"),
+			results: r.fields(ft.Results, "r"),
+		})
+	}
+
+	return r.render(mock, iface, methods)
+}
+
+// renderer renders the types of the interface, qualified with the name of their package.
+type renderer struct {
+	pkgName     string
+	imports     map[string]string
+	fileImports map[string]string
+}
+
+func (r *renderer) fields(list *ast.FieldList, prefix string) []param {
+	if list == nil {
+		return nil
+	}
+
+	var params []param
+	for _, f := range list.List {
+		typ := f.Type
+		variadic := false
+		if e, ok := typ.(*ast.Ellipsis); ok {
+			typ = e.Elt
+			variadic = true
+		}
+
+		names := f.Names
+		if len(names) == 0 {
+			names = []*ast.Ident{nil}
+		}
+
+		for _, n := range names {
+			p := param{typ: r.expr(typ), variadic: variadic}
+			// Parameters that shadow a package name are renamed, to keep the generated code readable
+			if n != nil && n.Name != "_" && n.Name != r.pkgName && n.Name != "context" {
+				p.name = n.Name
+			} else {
+				p.name = fmt.Sprintf("%s%d", prefix, len(params))
+			}
+			params = append(params, p)
+		}
+	}
+
+	// Results are always named, so the zero values can be returned with a bare return
+	if prefix == "r" {
+		for idx := range params {
+			params[idx].name = fmt.Sprintf("r%d", idx)
+		}
+	}
+
+	return params
+}
+
+func (r *renderer) expr(e ast.Expr) string {
+	switch t := e.(type) {
+	case *ast.Ident:
+		if ast.IsExported(t.Name) {
+			return fmt.Sprintf("%s.%s", r.pkgName, t.Name)
+		}
+		return t.Name
+	case *ast.StarExpr:
+		return fmt.Sprintf("*%s", r.expr(t.X))
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return fmt.Sprintf("[]%s", r.expr(t.Elt))
+		}
+		return fmt.Sprintf("[%s]%s", r.expr(t.Len), r.expr(t.Elt))
+	case *ast.MapType:
+		return fmt.Sprintf("map[%s]%s", r.expr(t.Key), r.expr(t.Value))
+	case *ast.SelectorExpr:
+		x := t.X.(*ast.Ident).Name
+		if p, ok := r.fileImports[x]; ok {
+			r.imports[x] = p
+		}
+		return fmt.Sprintf("%s.%s", x, t.Sel.Name)
+	case *ast.InterfaceType:
+		return "interface{}"
+	case *ast.ChanType:
+		switch t.Dir {
+		case ast.SEND:
+			return fmt.Sprintf("chan<- %s", r.expr(t.Value))
+		case ast.RECV:
+			return fmt.Sprintf("<-chan %s", r.expr(t.Value))
+		}
+		return fmt.Sprintf("chan %s", r.expr(t.Value))
+	case *ast.FuncType:
+		params := r.fields(t.Params, "p")
+		results := r.fields(t.Results, "x")
+		return fmt.Sprintf("func(%s) %s", joinTypes(params), resultTypes(results))
+	case *ast.BasicLit:
+		return t.Value
+	}
+	panic(fmt.Sprintf("unsupported type %T", e))
+}
+
+func (r *renderer) render(mock string, iface string, methods []method) ([]byte, error) {
+	byName := make(map[string]bool, len(methods))
+	for _, m := range methods {
+		byName[m.name] = true
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by mockgen. DO NOT EDIT.\n\npackage mocks\n\n")
+
+	r.imports["context"] = "context"
+	var std, other []string
+	for name, p := range r.imports {
+		if strings.Contains(strings.SplitN(p, "/", 2)[0], ".") {
+			other = append(other, name)
+		} else {
+			std = append(std, name)
+		}
+	}
+
+	buf.WriteString("import (\n")
+	for idx, group := range [][]string{std, other} {
+		if idx > 0 && len(group) > 0 {
+			buf.WriteString("\n")
+		}
+		sort.Slice(group, func(i, j int) bool { return r.imports[group[i]] < r.imports[group[j]] })
+		for _, name := range group {
+			if path.Base(r.imports[name]) == name {
+				fmt.Fprintf(&buf, "\t%s\n", strconv.Quote(r.imports[name]))
+			} else {
+				fmt.Fprintf(&buf, "\t%s %s\n", name, strconv.Quote(r.imports[name]))
+			}
+		}
+	}
+	buf.WriteString(")\n\n")
+
+	fmt.Fprintf(&buf, "// %s is a mock of %s.%s. Set the Func fields to return canned responses; methods\n", mock, r.pkgName, iface)
+	fmt.Fprintf(&buf, "// without a Func return zero values. The methods without a context call their Context\n")
+	fmt.Fprintf(&buf, "// variant with context.Background(), so only the Func of the Context variant needs to be set.\n")
+	fmt.Fprintf(&buf, "type %s struct {\n\tRecorder\n\n", mock)
+	for _, m := range methods {
+		if r.delegates(m, byName) {
+			continue
+		}
+		fmt.Fprintf(&buf, "\t%sFunc func(%s) %s\n", baseName(m.name), joinTypes(m.params), resultTypes(m.results))
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(&buf, "var _ %s.%s = (*%s)(nil)\n", r.pkgName, iface, mock)
+
+	for _, m := range methods {
+		fmt.Fprintf(&buf, "\n// %s implements %s.%s.\n", m.name, r.pkgName, iface)
+		fmt.Fprintf(&buf, "func (m *%s) %s(%s) %s {\n", mock, m.name, joinParams(m.params), namedResults(m.results))
+
+		if r.delegates(m, byName) {
+			args := append([]string{"context.Background()"}, callArgs(m.params)...)
+			fmt.Fprintf(&buf, "\treturn m.%s%s(%s)\n}\n", m.name, contextSuffix, strings.Join(args, ", "))
+			continue
+		}
+
+		base := baseName(m.name)
+		recorded := []string{fmt.Sprintf("%q", base)}
+		for _, p := range m.params {
+			if p.typ != "context.Context" {
+				recorded = append(recorded, p.name)
+			}
+		}
+		fmt.Fprintf(&buf, "\tm.Record(%s)\n", strings.Join(recorded, ", "))
+		fmt.Fprintf(&buf, "\tif m.%sFunc != nil {\n", base)
+		if len(m.results) > 0 {
+			fmt.Fprintf(&buf, "\t\treturn m.%sFunc(%s)\n\t}\n\treturn\n}\n", base, strings.Join(callArgs(m.params), ", "))
+		} else {
+			fmt.Fprintf(&buf, "\t\tm.%sFunc(%s)\n\t}\n}\n", base, strings.Join(callArgs(m.params), ", "))
+		}
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+// delegates returns true if the method has a Context variant it can call.
+func (r *renderer) delegates(m method, byName map[string]bool) bool {
+	return !strings.HasSuffix(m.name, contextSuffix) && byName[m.name+contextSuffix]
+}
+
+func fileImports(f *ast.File) map[string]string {
+	imports := make(map[string]string)
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		name := path.Base(p)
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imports[name] = p
+	}
+	return imports
+}
+
+func baseName(name string) string {
+	return strings.TrimSuffix(name, contextSuffix)
+}
+
+func joinParams(params []param) string {
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		parts = append(parts, fmt.Sprintf("%s %s", p.name, typeOf(p)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func joinTypes(params []param) string {
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		parts = append(parts, typeOf(p))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func namedResults(results []param) string {
+	if len(results) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("(%s)", joinParams(results))
+}
+
+func resultTypes(results []param) string {
+	switch len(results) {
+	case 0:
+		return ""
+	case 1:
+		return results[0].typ
+	}
+	return fmt.Sprintf("(%s)", joinTypes(results))
+}
+
+func callArgs(params []param) []string {
+	args := make([]string, 0, len(params))
+	for _, p := range params {
+		if p.variadic {
+			args = append(args, fmt.Sprintf("%s...", p.name))
+		} else {
+			args = append(args, p.name)
+		}
+	}
+	return args
+}
+
+func typeOf(p param) string {
+	if p.variadic {
+		return fmt.Sprintf("...%s", p.typ)
+	}
+	return p.typ
+}
@@ -0,0 +1,120 @@
+// Package bitly contains the types shared by the services of the Bitly client
+package bitly
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// TimeFormat is the format in which Bitly sends timestamps, like 2018-08-05T22:08:53+0000
+	TimeFormat = "2006-01-02T15:04:05-0700"
+)
+
+// timeFormats are all formats Bitly has been seen to use for timestamps, in order of preference.
+var timeFormats = []string{
+	TimeFormat,
+	"2006-01-02T15:04:05.999999999-0700",
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// Time is a timestamp from Bitly. It wraps time.Time, so it can be sorted, filtered and compared, and reads
+// the formats Bitly uses which time.RFC3339 can't parse. The zero Time is marshalled as null, because
+// omitempty has no effect on structs, so fields that are sent to Bitly and may be empty use a *Time.
+type Time struct {
+	time.Time
+}
+
+// NewTime wraps t in a Time.
+func NewTime(t time.Time) Time {
+	return Time{Time: t}
+}
+
+// ParseTime parses a timestamp in any of the formats used by Bitly, or a unix epoch in seconds.
+func ParseTime(s string) (Time, error) {
+	if len(s) == 0 {
+		return Time{}, nil
+	}
+
+	for _, f := range timeFormats {
+		if t, err := time.Parse(f, s); err == nil {
+			return Time{Time: t}, nil
+		}
+	}
+
+	if epoch, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return Time{Time: time.Unix(epoch, 0).UTC()}, nil
+	}
+
+	return Time{}, fmt.Errorf("bitly: cannot parse %q as a timestamp", s)
+}
+
+// String returns the time in Bitly's format, or an empty string for the zero Time.
+func (t Time) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(TimeFormat)
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Format(TimeFormat))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface. It accepts strings in any of the formats used by
+// Bitly, unix epochs as numbers, and null.
+func (t *Time) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+
+	if bytes.Equal(data, []byte("null")) {
+		*t = Time{}
+		return nil
+	}
+
+	if len(data) > 0 && data[0] != '"' {
+		epoch, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return fmt.Errorf("bitly: cannot parse %s as a timestamp", string(data))
+		}
+		*t = Time{Time: time.Unix(epoch, 0).UTC()}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := ParseTime(s)
+	if err != nil {
+		return err
+	}
+
+	*t = parsed
+	return nil
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (t Time) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (t *Time) UnmarshalText(data []byte) error {
+	parsed, err := ParseTime(string(data))
+	if err != nil {
+		return err
+	}
+
+	*t = parsed
+	return nil
+}
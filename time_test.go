@@ -0,0 +1,93 @@
+package bitly
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimeUnmarshalJSON(t *testing.T) {
+	want := time.Date(2018, 8, 5, 22, 8, 53, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		json    string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "bitly format", json: `"2018-08-05T22:08:53+0000"`, want: want},
+		{name: "other offset", json: `"2018-08-06T00:08:53+0200"`, want: want},
+		{name: "fractional seconds", json: `"2018-08-05T22:08:53.000000+0000"`, want: want},
+		{name: "rfc3339", json: `"2018-08-05T22:08:53Z"`, want: want},
+		{name: "without zone", json: `"2018-08-05T22:08:53"`, want: want},
+		{name: "date", json: `"2018-08-05"`, want: time.Date(2018, 8, 5, 0, 0, 0, 0, time.UTC)},
+		{name: "epoch number", json: `1533506933`, want: want},
+		{name: "epoch string", json: `"1533506933"`, want: want},
+		{name: "null", json: `null`},
+		{name: "empty string", json: `""`},
+		{name: "invalid string", json: `"yesterday"`, wantErr: true},
+		{name: "invalid number", json: `1.5`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Time
+			err := json.Unmarshal([]byte(tt.json), &got)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Unmarshal() = %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal() returned %s", err.Error())
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("Unmarshal() = %s, want %s", got.Time, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeMarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{name: "time", v: NewTime(time.Date(2018, 8, 5, 22, 8, 53, 0, time.UTC)), want: `"2018-08-05T22:08:53+0000"`},
+		{name: "zero", v: Time{}, want: `null`},
+		{name: "nil pointer", v: struct {
+			Modified *Time `json:"modified,omitempty"`
+		}{}, want: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatalf("Marshal() returned %s", err.Error())
+			}
+			if string(got) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeRoundTrip(t *testing.T) {
+	in := NewTime(time.Date(2019, 6, 1, 12, 30, 0, 0, time.FixedZone("", 2*60*60)))
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out Time
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !out.Equal(in.Time) {
+		t.Errorf("the round trip returned %s, want %s", out, in)
+	}
+}